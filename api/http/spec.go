@@ -0,0 +1,9 @@
+// Package httpapi embeds the OpenAPI description of the REST API.
+package httpapi
+
+import _ "embed"
+
+// Spec is the OpenAPI document the REST server validates requests against.
+//
+//go:embed openapi.yml
+var Spec []byte
@@ -0,0 +1,10 @@
+// Package kafkaapi embeds the registered schemas of the Kafka messages.
+package kafkaapi
+
+import "embed"
+
+// Schemas holds a directory per registry subject with a file per registered version, written by
+// cmd/schema-registry.
+//
+//go:embed schemas
+var Schemas embed.FS
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/romanpitatelev/wallets-service/internal/broker"
+	"github.com/romanpitatelev/wallets-service/internal/configs"
+	"github.com/rs/zerolog/log"
+)
+
+// dlq-replay sends the messages dead-lettered by the wallets service back to their topics, to be
+// run once the cause of their failure is fixed.
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := configs.New()
+
+	replayed, err := broker.ReplayDLQ(ctx, broker.ReplayConfig{
+		Addr:    cfg.GetKafkaAddress(),
+		GroupID: cfg.GetKafkaConsumerGroup() + "-dlq-replay",
+	})
+	if err != nil {
+		log.Panic().Err(err).Int("replayed", replayed).Msg("failed to replay dead letter queue")
+	}
+
+	log.Info().Int("replayed", replayed).Msg("dead letter queue replayed")
+}
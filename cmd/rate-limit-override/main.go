@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os/signal"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/configs"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// rate-limit-override sets the requests per period a user is allowed in a rate limit budget, or
+// with -delete removes the override so the configured limit applies again. Replicas pick the
+// change up within RATE_LIMIT_REFRESH_PERIOD.
+func main() {
+	user := flag.String("user", "", "user ID")
+	budget := flag.String("budget", models.RateLimitDefault, "rate limit budget: "+models.RateLimitDefault+" or "+models.RateLimitMoney)
+	requests := flag.Int("requests", 0, "requests per RATE_LIMIT_PERIOD, at least one")
+	remove := flag.Bool("delete", false, "remove the override of the user for the budget")
+	flag.Parse()
+
+	userID, err := uuid.Parse(*user)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid user ID")
+	}
+
+	if *budget != models.RateLimitDefault && *budget != models.RateLimitMoney {
+		log.Fatal().Str("budget", *budget).Msg("unknown rate limit budget")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := configs.New()
+
+	if err := cfg.ValidatePIIEncryptionKey(); err != nil {
+		log.Panic().Err(err).Msg("invalid configuration")
+	}
+
+	pgStore, err := store.New(ctx, store.Config{
+		Dsn:           cfg.GetPostgresDSN(),
+		EncryptionKey: cfg.GetPIIEncryptionKey(),
+	})
+	if err != nil {
+		log.Panic().Err(err).Msg("failed to create store")
+	}
+
+	if *remove {
+		if err := pgStore.DeleteRateLimitOverride(ctx, models.UserID(userID), *budget); err != nil {
+			log.Panic().Err(err).Msg("failed to delete rate limit override")
+		}
+
+		log.Info().Str("user", userID.String()).Str("budget", *budget).Msg("rate limit override deleted")
+
+		return
+	}
+
+	override := models.RateLimitOverride{UserID: models.UserID(userID), Budget: *budget, Requests: *requests}
+
+	if err := pgStore.UpsertRateLimitOverride(ctx, override); err != nil {
+		log.Panic().Err(err).Msg("failed to set rate limit override")
+	}
+
+	log.Info().Str("user", userID.String()).Str("budget", *budget).Int("requests", *requests).Msg("rate limit override set")
+}
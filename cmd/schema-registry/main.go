@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"slices"
+
+	"github.com/romanpitatelev/wallets-service/internal/broker"
+	"github.com/romanpitatelev/wallets-service/internal/schemaregistry"
+	"github.com/rs/zerolog/log"
+)
+
+// schema-registry registers the current schemas of the Kafka messages as new versions in the
+// registry directory, to be run after changing a .proto file under api/kafka. It fails when a
+// schema is incompatible with a registered version.
+func main() {
+	dir := flag.String("dir", "api/kafka/schemas", "registry directory")
+	flag.Parse()
+
+	registry := schemaregistry.Open(*dir)
+	schemas := broker.Schemas()
+
+	subjects := make([]string, 0, len(schemas))
+	for subject := range schemas {
+		subjects = append(subjects, subject)
+	}
+
+	slices.Sort(subjects)
+
+	for _, subject := range subjects {
+		version, err := registry.Register(subject, schemas[subject])
+		if err != nil {
+			log.Fatal().Err(err).Str("subject", subject).Msg("failed to register schema")
+		}
+
+		log.Info().Str("subject", subject).Int("version", version).Msg("schema registered")
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"crypto/rand"
-	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -11,6 +10,7 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/google/uuid"
 	"github.com/goombaio/namegenerator"
+	"github.com/romanpitatelev/wallets-service/internal/broker"
 	"github.com/romanpitatelev/wallets-service/internal/models"
 	"github.com/rs/zerolog/log"
 )
@@ -18,7 +18,6 @@ import (
 const (
 	timeInterval           = time.Second
 	numberOfDifferentUsers = 1000
-	topic                  = "users"
 	ageMin                 = 15
 	ageMax                 = 100
 	defaultAge             = 45
@@ -30,12 +29,12 @@ const (
 )
 
 type User struct {
-	UserID    models.UserID `json:"userid"`
-	FirstName string        `json:"firstName"`
-	LastName  string        `json:"lastName"`
-	Gender    string        `json:"gender"`
-	Age       int           `json:"age"`
-	Deleted   bool          `json:"deleted"`
+	UserID    models.UserID
+	FirstName string
+	LastName  string
+	Gender    string
+	Age       int
+	Deleted   bool
 }
 
 func main() {
@@ -55,8 +54,10 @@ func main() {
 	users := generateUsers()
 
 	for user := range users {
-		if err := sendUserToKafka(producer, topic, user); err != nil {
-			log.Panic().Err(err).Msg("failed to send user to kafka")
+		for _, event := range userEvents(user) {
+			if err := sendUserToKafka(producer, event); err != nil {
+				log.Panic().Err(err).Msg("failed to send user to kafka")
+			}
 		}
 
 		log.Info().Msg("message sent")
@@ -157,15 +158,40 @@ func newKafkaProducer(brokers []string) (sarama.SyncProducer, error) {
 	return producer, nil
 }
 
-func sendUserToKafka(producer sarama.SyncProducer, topic string, user User) error {
-	bytes, err := json.Marshal(user)
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+// userEvents returns the lifecycle events of a generated user: its creation and, for a user
+// generated as deleted, its deletion right after.
+func userEvents(user User) []models.UserEvent {
+	now := time.Now().UTC()
+
+	events := []models.UserEvent{{
+		Version:    models.UserEventVersion,
+		Type:       models.UserEventCreated,
+		UserID:     user.UserID,
+		OccurredAt: now,
+		Profile: &models.UserProfile{
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Gender:    user.Gender,
+			Age:       user.Age,
+		},
+	}}
+
+	if user.Deleted {
+		events = append(events, models.UserEvent{
+			Version:    models.UserEventVersion,
+			Type:       models.UserEventDeleted,
+			UserID:     user.UserID,
+			OccurredAt: now,
+		})
 	}
 
-	message := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.StringEncoder(bytes),
+	return events
+}
+
+func sendUserToKafka(producer sarama.SyncProducer, event models.UserEvent) error {
+	message, err := broker.NewUserEventMessage(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode user event: %w", err)
 	}
 
 	_, _, err = producer.SendMessage(message)
@@ -6,11 +6,15 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/romanpitatelev/wallets-service/internal/auth"
 	"github.com/romanpitatelev/wallets-service/internal/broker"
 	"github.com/romanpitatelev/wallets-service/internal/configs"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/ratelimit"
 	"github.com/romanpitatelev/wallets-service/internal/rest"
 	"github.com/romanpitatelev/wallets-service/internal/service"
 	"github.com/romanpitatelev/wallets-service/internal/store"
+	walletsgrpcserver "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/wallets-server"
 	xrgrpcclient "github.com/romanpitatelev/wallets-service/internal/xr/xr-grpc/xr-client"
 	"github.com/rs/zerolog/log"
 	migrate "github.com/rubenv/sql-migrate"
@@ -24,9 +28,16 @@ func main() {
 
 	cfg := configs.New()
 
-	pgStore, err := store.New(ctx, store.Config{Dsn: cfg.GetPostgresDSN()})
+	if err := cfg.ValidatePIIEncryptionKey(); err != nil {
+		log.Panic().Err(err).Msg("invalid configuration")
+	}
+
+	pgStore, err := store.New(ctx, store.Config{
+		Dsn:           cfg.GetPostgresDSN(),
+		EncryptionKey: cfg.GetPIIEncryptionKey(),
+	})
 	if err != nil {
-		log.Panic().Err(err).Msg("failed to connect to database")
+		log.Panic().Err(err).Msg("failed to create store")
 	}
 
 	if err := pgStore.Migrate(migrate.Up); err != nil {
@@ -35,7 +46,12 @@ func main() {
 
 	log.Info().Msg("successful migration")
 
-	kafkaConsumer, err := broker.NewConsumer(pgStore, broker.ConsumerConfig{Addr: cfg.GetKafkaAddress()})
+	kafkaConsumer, err := broker.NewConsumer(pgStore, broker.ConsumerConfig{
+		Addr:         cfg.GetKafkaAddress(),
+		GroupID:      cfg.GetKafkaConsumerGroup(),
+		MaxRetries:   cfg.GetKafkaMaxRetries(),
+		RetryBackoff: cfg.GetKafkaRetryBackoff(),
+	})
 	if err != nil {
 		log.Panic().Err(err).Msg("failed to create kafka consumer")
 	}
@@ -48,7 +64,10 @@ func main() {
 
 	log.Info().Msg("kafka consumer created")
 
-	kafkaTxProducer, err := broker.NewProducer(broker.ProducerConfig{Addr: cfg.GetKafkaAddress()})
+	kafkaTxProducer, err := broker.NewProducer(broker.ProducerConfig{
+		Addr:   cfg.GetKafkaAddress(),
+		Format: cfg.GetKafkaMessageFormat(),
+	})
 	if err != nil {
 		log.Panic().Err(err).Msg("failed to create kafka transactions producer")
 	}
@@ -68,15 +87,71 @@ func main() {
 
 	svc := service.New(
 		service.Config{
-			StaleWalletDuration: cfg.GetStaleWalletDuration(),
-			PerformCheckPeriod:  cfg.GetPerformCheckPeriod(),
+			StaleWalletDuration:  cfg.GetStaleWalletDuration(),
+			PerformCheckPeriod:   cfg.GetPerformCheckPeriod(),
+			AuditRetention:       cfg.GetAuditRetention(),
+			RollupPeriod:         cfg.GetRollupPeriod(),
+			RateCacheTTL:         cfg.GetRateCacheTTL(),
+			SignatureMaxSkew:     cfg.GetSignatureMaxSkew(),
+			WebhookPollPeriod:    cfg.GetWebhookPollPeriod(),
+			WebhookMaxAttempts:   cfg.GetWebhookMaxAttempts(),
+			WebhookBackoff:       cfg.GetWebhookBackoff(),
+			WebhookTimeout:       cfg.GetWebhookTimeout(),
+			WebhookAllowPrivate:  cfg.GetWebhookAllowPrivate(),
+			EventRetention:       cfg.GetEventRetention(),
+			ComplianceScanPeriod: cfg.GetComplianceScanPeriod(),
+			ComplianceRules: models.ComplianceRules{
+				Thresholds:        cfg.GetComplianceThresholds(),
+				StructuringWindow: cfg.GetStructuringWindow(),
+				StructuringCount:  cfg.GetStructuringCount(),
+				StructuringMargin: cfg.GetStructuringMargin(),
+			},
 		},
 		pgStore,
 		xrClient,
 		kafkaTxProducer,
 	)
 
-	server := rest.New(rest.Config{Port: cfg.GetAppPort()}, svc, rest.GetPublicKey())
+	verifier, err := auth.New(ctx, auth.Config{
+		KeyFiles:      cfg.GetJWTPublicKeyFiles(),
+		JWKSURL:       cfg.GetJWKSURL(),
+		RefreshPeriod: cfg.GetJWTKeysRefresh(),
+		Issuer:        cfg.GetJWTIssuer(),
+		Audience:      cfg.GetJWTAudience(),
+	})
+	if err != nil {
+		log.Panic().Err(err).Msg("failed to create token verifier")
+	}
+
+	var rateLimitBackend ratelimit.Backend
+
+	switch cfg.GetRateLimitBackend() {
+	case "memory":
+		rateLimitBackend = ratelimit.NewMemoryBackend()
+	case "postgres":
+		rateLimitBackend = pgStore
+	default:
+		log.Panic().Str("backend", cfg.GetRateLimitBackend()).Msg("unknown rate limit backend")
+	}
+
+	limiter := ratelimit.New(ctx, ratelimit.Config{
+		Limits: map[string]int{
+			models.RateLimitDefault: cfg.GetRateLimitDefault(),
+			models.RateLimitMoney:   cfg.GetRateLimitMoney(),
+		},
+		Period:        cfg.GetRateLimitPeriod(),
+		RefreshPeriod: cfg.GetRateLimitRefresh(),
+	}, rateLimitBackend, pgStore)
+
+	server, err := rest.New(rest.Config{
+		Port:              cfg.GetAppPort(),
+		ValidateResponses: cfg.GetValidateResponses(),
+	}, svc, verifier, limiter)
+	if err != nil {
+		log.Panic().Err(err).Msg("failed to create http server")
+	}
+
+	grpcServer := walletsgrpcserver.New(walletsgrpcserver.Config{Port: cfg.GetGRPCPort()}, svc, verifier, limiter)
 
 	errGr, ctx := errgroup.WithContext(ctx)
 
@@ -96,6 +171,54 @@ func main() {
 		return nil
 	})
 
+	errGr.Go(func() error {
+		if err := svc.RunRollups(ctx); err != nil {
+			return fmt.Errorf("failed to run analytics rollups: %w", err)
+		}
+
+		return nil
+	})
+
+	errGr.Go(func() error {
+		if err := svc.RunWebhooks(ctx); err != nil {
+			return fmt.Errorf("failed to run webhook deliveries: %w", err)
+		}
+
+		return nil
+	})
+
+	errGr.Go(func() error {
+		if err := svc.RunCompliance(ctx); err != nil {
+			return fmt.Errorf("failed to run compliance case detection: %w", err)
+		}
+
+		return nil
+	})
+
+	errGr.Go(func() error {
+		if err := svc.RunEventStream(ctx); err != nil {
+			return fmt.Errorf("failed to run wallet event stream: %w", err)
+		}
+
+		return nil
+	})
+
+	errGr.Go(func() error {
+		if err := verifier.Run(ctx); err != nil {
+			return fmt.Errorf("failed to refresh token keys: %w", err)
+		}
+
+		return nil
+	})
+
+	errGr.Go(func() error {
+		if err := limiter.Run(ctx); err != nil {
+			return fmt.Errorf("failed to refresh rate limits: %w", err)
+		}
+
+		return nil
+	})
+
 	errGr.Go(func() error {
 		if err := server.Run(ctx); err != nil {
 			return fmt.Errorf("failed to run the server: %w", err)
@@ -104,6 +227,14 @@ func main() {
 		return nil
 	})
 
+	errGr.Go(func() error {
+		if err := grpcServer.Run(ctx); err != nil {
+			return fmt.Errorf("failed to run the grpc server: %w", err)
+		}
+
+		return nil
+	})
+
 	if err = errGr.Wait(); err != nil {
 		log.Panic().Err(err).Msg("failed to wait blocks")
 	}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+var errInvalidJWK = errors.New("invalid jwk")
+
+// reloadFiles reads every configured key file. The keys are only replaced when all files load,
+// so a half-written file during a rotation does not drop the keys in use.
+func (v *Verifier) reloadFiles() error {
+	keys := make(map[string]*rsa.PublicKey)
+
+	for _, path := range v.cfg.KeyFiles {
+		files, err := keyFiles(path)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read public key %s: %w", file, err)
+			}
+
+			key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return fmt.Errorf("error parsing public key %s: %w", file, err)
+			}
+
+			keys[strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))] = key
+		}
+	}
+
+	if len(keys) == 0 {
+		return errNoKeys
+	}
+
+	v.mu.Lock()
+	v.fileKeys = keys
+	v.mu.Unlock()
+
+	return nil
+}
+
+func keyFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat public key %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list public keys in %s: %w", path, err)
+	}
+
+	return files, nil
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// fetchJWKS replaces the JWKS keys with the RSA signing keys currently published at the URL.
+func (v *Verifier) fetchJWKS(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create jwks request: %w", err)
+	}
+
+	response, err := v.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close response body")
+		}
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch jwks: status %d", response.StatusCode) //nolint:err113
+	}
+
+	var set jwks
+
+	if err = json.NewDecoder(response.Body).Decode(&set); err != nil {
+		return fmt.Errorf("error decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		publicKey, err := key.rsaPublicKey()
+		if err != nil {
+			log.Warn().Err(err).Str("kid", key.Kid).Msg("skipping jwks key")
+
+			continue
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	v.mu.Lock()
+	v.jwksKeys = keys
+	v.lastFetched = time.Now()
+	v.mu.Unlock()
+
+	return nil
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("%w: modulus", errInvalidJWK)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 {
+		return nil, fmt.Errorf("%w: exponent", errInvalidJWK)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("%w: exponent", errInvalidJWK)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
@@ -0,0 +1,239 @@
+// Package auth verifies the JWTs clients authenticate with against rotating signing keys.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultRefreshPeriod = 5 * time.Minute
+	// minRefreshInterval throttles the JWKS refetches triggered by tokens with an unknown kid.
+	minRefreshInterval = 30 * time.Second
+	fetchTimeout       = 10 * time.Second
+)
+
+//go:embed keys/public_key.pem
+var defaultKeyData []byte
+
+var (
+	errUnknownKey = errors.New("unknown signing key")
+	errNoKeys     = errors.New("no signing keys")
+)
+
+type Config struct {
+	// KeyFiles lists PEM public keys or directories of *.pem files. A key's kid is its file name
+	// without the extension. The files are reloaded every RefreshPeriod.
+	KeyFiles []string
+	// JWKSURL is fetched every RefreshPeriod and whenever a token names an unknown kid.
+	JWKSURL       string
+	RefreshPeriod time.Duration
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+}
+
+// Verifier checks token signatures against the configured keys. Without key files or a JWKS URL
+// it falls back to the embedded development key.
+type Verifier struct {
+	cfg    Config
+	client *http.Client
+	parser *jwt.Parser
+
+	mu          sync.RWMutex
+	fileKeys    map[string]*rsa.PublicKey
+	jwksKeys    map[string]*rsa.PublicKey
+	lastFetched time.Time
+}
+
+func New(ctx context.Context, cfg Config) (*Verifier, error) {
+	if cfg.RefreshPeriod <= 0 {
+		cfg.RefreshPeriod = defaultRefreshPeriod
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodRS256.Alg(), jwt.SigningMethodRS384.Alg(), jwt.SigningMethodRS512.Alg(),
+		}),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	v := &Verifier{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: fetchTimeout},
+		parser:   jwt.NewParser(options...),
+		fileKeys: make(map[string]*rsa.PublicKey),
+		jwksKeys: make(map[string]*rsa.PublicKey),
+	}
+
+	if len(cfg.KeyFiles) == 0 && cfg.JWKSURL == "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM(defaultKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key: %w", err)
+		}
+
+		v.fileKeys[""] = key
+
+		return v, nil
+	}
+
+	if len(cfg.KeyFiles) > 0 {
+		if err := v.reloadFiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.JWKSURL != "" {
+		// The identity provider may come up after us, so a failed first fetch is retried later.
+		if err := v.fetchJWKS(ctx); err != nil {
+			log.Warn().Err(err).Str("url", cfg.JWKSURL).Msg("failed to fetch jwks")
+		}
+	}
+
+	return v, nil
+}
+
+// Run reloads the key files and refetches the JWKS every refresh period. Failed refreshes keep
+// the previous keys.
+func (v *Verifier) Run(ctx context.Context) error {
+	if len(v.cfg.KeyFiles) == 0 && v.cfg.JWKSURL == "" {
+		return nil
+	}
+
+	ticker := time.NewTicker(v.cfg.RefreshPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			v.refresh(ctx)
+		}
+	}
+}
+
+func (v *Verifier) refresh(ctx context.Context) {
+	if len(v.cfg.KeyFiles) > 0 {
+		if err := v.reloadFiles(); err != nil {
+			log.Warn().Err(err).Msg("failed to reload public keys")
+		}
+	}
+
+	if v.cfg.JWKSURL != "" {
+		if err := v.fetchJWKS(ctx); err != nil {
+			log.Warn().Err(err).Str("url", v.cfg.JWKSURL).Msg("failed to fetch jwks")
+		}
+	}
+}
+
+// Verify parses the token, checks its signature, expiry, issuer and audience and returns its
+// claims. All failures are reported as models.ErrInvalidToken.
+func (v *Verifier) Verify(ctx context.Context, token string) (*models.Claims, error) {
+	claims := &models.Claims{}
+
+	parsed, err := v.parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.lookup(ctx, token)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidToken, err)
+	}
+
+	if !parsed.Valid {
+		return nil, models.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// lookup selects the verification key by the kid header. Tokens without a kid are checked
+// against every known key.
+func (v *Verifier) lookup(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" {
+		keys := v.allKeys()
+		if len(keys) == 0 {
+			return nil, errNoKeys
+		}
+
+		return jwt.VerificationKeySet{Keys: keys}, nil
+	}
+
+	if key := v.key(kid); key != nil {
+		return key, nil
+	}
+
+	if v.cfg.JWKSURL != "" && v.claimFetch() {
+		if err := v.fetchJWKS(ctx); err != nil {
+			log.Warn().Err(err).Str("url", v.cfg.JWKSURL).Msg("failed to fetch jwks")
+		}
+
+		if key := v.key(kid); key != nil {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", errUnknownKey, kid)
+}
+
+func (v *Verifier) key(kid string) *rsa.PublicKey {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if key, ok := v.jwksKeys[kid]; ok {
+		return key
+	}
+
+	return v.fileKeys[kid]
+}
+
+func (v *Verifier) allKeys() []jwt.VerificationKey {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	keys := make([]jwt.VerificationKey, 0, len(v.fileKeys)+len(v.jwksKeys))
+
+	for _, key := range v.fileKeys {
+		keys = append(keys, key)
+	}
+
+	for _, key := range v.jwksKeys {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// claimFetch reports whether an on-demand JWKS fetch may run now and, if so, records it, so
+// a burst of tokens with an unknown kid triggers a single fetch.
+func (v *Verifier) claimFetch() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if time.Since(v.lastFetched) < minRefreshInterval {
+		return false
+	}
+
+	v.lastFetched = time.Now()
+
+	return true
+}
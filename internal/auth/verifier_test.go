@@ -0,0 +1,284 @@
+//nolint:testpackage
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return key
+}
+
+func writePublicKey(t *testing.T, path string, key *rsa.PrivateKey) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, mutate func(*models.Claims)) string {
+	t.Helper()
+
+	claims := models.Claims{
+		UserID: models.UserID(uuid.New()),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	if mutate != nil {
+		mutate(&claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func TestVerifyKeyFiles(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	oldKey, newKey := generateKey(t), generateKey(t)
+
+	writePublicKey(t, filepath.Join(dir, "old.pem"), oldKey)
+
+	verifier, err := New(ctx, Config{KeyFiles: []string{dir}})
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, signToken(t, oldKey, "old", nil))
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, signToken(t, oldKey, "", nil))
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, signToken(t, newKey, "new", nil))
+	require.ErrorIs(t, err, models.ErrInvalidToken)
+
+	writePublicKey(t, filepath.Join(dir, "new.pem"), newKey)
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.pem")))
+
+	verifier.refresh(ctx)
+
+	_, err = verifier.Verify(ctx, signToken(t, newKey, "new", nil))
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, signToken(t, oldKey, "old", nil))
+	require.ErrorIs(t, err, models.ErrInvalidToken)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+
+	verifier.refresh(ctx)
+
+	_, err = verifier.Verify(ctx, signToken(t, newKey, "new", nil))
+	require.NoError(t, err, "a broken file must not drop the loaded keys")
+}
+
+type jwksServer struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fetches++
+
+	set := jwks{}
+
+	for kid, key := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+func (s *jwksServer) add(kid string, key *rsa.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[kid] = key
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fetches
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	firstKey, secondKey := generateKey(t), generateKey(t)
+
+	provider := &jwksServer{keys: map[string]*rsa.PrivateKey{"first": firstKey}}
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+
+	verifier, err := New(ctx, Config{JWKSURL: server.URL})
+	require.NoError(t, err)
+	require.Equal(t, 1, provider.fetchCount())
+
+	_, err = verifier.Verify(ctx, signToken(t, firstKey, "first", nil))
+	require.NoError(t, err)
+	require.Equal(t, 1, provider.fetchCount(), "known keys are served from the cache")
+
+	provider.add("second", secondKey)
+
+	_, err = verifier.Verify(ctx, signToken(t, secondKey, "second", nil))
+	require.ErrorIs(t, err, models.ErrInvalidToken, "refetches are throttled")
+	require.Equal(t, 1, provider.fetchCount())
+
+	verifier.mu.Lock()
+	verifier.lastFetched = time.Now().Add(-minRefreshInterval)
+	verifier.mu.Unlock()
+
+	_, err = verifier.Verify(ctx, signToken(t, secondKey, "second", nil))
+	require.NoError(t, err, "an unknown kid triggers a refetch")
+	require.Equal(t, 2, provider.fetchCount())
+
+	_, err = verifier.Verify(ctx, signToken(t, firstKey, "second", nil))
+	require.ErrorIs(t, err, models.ErrInvalidToken, "the kid selects the key")
+}
+
+func TestVerifyUnavailableJWKS(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := New(ctx, Config{JWKSURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, signToken(t, generateKey(t), "", nil))
+	require.ErrorIs(t, err, models.ErrInvalidToken)
+
+	_, err = verifier.Verify(ctx, signToken(t, generateKey(t), "unknown", nil))
+	require.ErrorIs(t, err, models.ErrInvalidToken)
+}
+
+func TestVerifyClaims(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	key := generateKey(t)
+
+	writePublicKey(t, filepath.Join(dir, "key.pem"), key)
+
+	verifier, err := New(ctx, Config{
+		KeyFiles: []string{filepath.Join(dir, "key.pem")},
+		Issuer:   "https://id.example.com",
+		Audience: "wallets-service",
+	})
+	require.NoError(t, err)
+
+	valid := func(claims *models.Claims) {
+		claims.Issuer = "https://id.example.com"
+		claims.Audience = jwt.ClaimStrings{"wallets-service"}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*models.Claims)
+		valid  bool
+	}{
+		{
+			name:   "valid",
+			mutate: valid,
+			valid:  true,
+		},
+		{
+			name: "wrong issuer",
+			mutate: func(claims *models.Claims) {
+				valid(claims)
+				claims.Issuer = "https://evil.example.com"
+			},
+		},
+		{
+			name: "wrong audience",
+			mutate: func(claims *models.Claims) {
+				valid(claims)
+				claims.Audience = jwt.ClaimStrings{"another-service"}
+			},
+		},
+		{
+			name: "expired",
+			mutate: func(claims *models.Claims) {
+				valid(claims)
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			},
+		},
+		{
+			name: "without expiry",
+			mutate: func(claims *models.Claims) {
+				valid(claims)
+				claims.ExpiresAt = nil
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			claims, err := verifier.Verify(ctx, signToken(t, key, "", tc.mutate))
+			if tc.valid {
+				require.NoError(t, err)
+				require.NotNil(t, claims)
+
+				return
+			}
+
+			require.ErrorIs(t, err, models.ErrInvalidToken)
+		})
+	}
+}
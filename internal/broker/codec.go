@@ -0,0 +1,253 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	kafkaapi "github.com/romanpitatelev/wallets-service/api/kafka"
+	transactionsv1 "github.com/romanpitatelev/wallets-service/internal/broker/gen/go/transactions"
+	usersv1 "github.com/romanpitatelev/wallets-service/internal/broker/gen/go/users"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/schemaregistry"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Messages carry their encoding in the content-type header and the version of their contract in
+// the schema-version header: for protobuf the registered version of the schema they were encoded
+// with, for JSON the version of its field layout. Messages without the headers are the JSON
+// published before the schemas.
+const (
+	headerContentType   = "content-type"
+	headerSchemaVersion = "schema-version"
+
+	// transactionJSONVersion is the version of the JSON transactions produced while consumers
+	// migrate to protobuf. It changes whenever a field of the JSON is changed or removed.
+	transactionJSONVersion = 1
+
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/x-protobuf"
+
+	usersSubject        = topic + "-value"
+	transactionsSubject = transactiontTopic + "-value"
+)
+
+var errUnknownSchemaVersion = errors.New("unknown schema version")
+
+//nolint:gochecknoglobals
+var (
+	registry = sync.OnceValue(func() *schemaregistry.Registry {
+		schemas, err := fs.Sub(kafkaapi.Schemas, "schemas")
+		if err != nil {
+			panic(err)
+		}
+
+		return schemaregistry.New(schemas)
+	})
+
+	userSchemaVersion = sync.OnceValues(func() (int, error) {
+		return registry().Version(usersSubject, (&usersv1.UserEvent{}).ProtoReflect().Descriptor())
+	})
+
+	transactionSchemaVersion = sync.OnceValues(func() (int, error) {
+		return registry().Version(transactionsSubject, (&transactionsv1.TransactionEvent{}).ProtoReflect().Descriptor())
+	})
+
+	userEventTypes = map[string]usersv1.UserEvent_Type{
+		models.UserEventCreated:  usersv1.UserEvent_TYPE_CREATED,
+		models.UserEventDeleted:  usersv1.UserEvent_TYPE_DELETED,
+		models.UserEventRestored: usersv1.UserEvent_TYPE_RESTORED,
+	}
+)
+
+// Schemas returns the schemas of the messages the service produces and consumes, by subject.
+func Schemas() map[string]protoreflect.MessageDescriptor {
+	return map[string]protoreflect.MessageDescriptor{
+		usersSubject:        (&usersv1.UserEvent{}).ProtoReflect().Descriptor(),
+		transactionsSubject: (&transactionsv1.TransactionEvent{}).ProtoReflect().Descriptor(),
+	}
+}
+
+// NewUserEventMessage encodes the event for the users topic, keyed by the user ID.
+func NewUserEventMessage(event models.UserEvent) (*sarama.ProducerMessage, error) {
+	version, err := userSchemaVersion()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	value, err := proto.Marshal(userEventToProto(event))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode user event: %w", err)
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.StringEncoder(uuid.UUID(event.UserID).String()),
+		Value:   sarama.ByteEncoder(value),
+		Headers: protobufHeaders(version),
+	}, nil
+}
+
+func protobufHeaders(version int) []sarama.RecordHeader {
+	return []sarama.RecordHeader{
+		{Key: []byte(headerContentType), Value: []byte(contentTypeProtobuf)},
+		{Key: []byte(headerSchemaVersion), Value: []byte(strconv.Itoa(version))},
+	}
+}
+
+func jsonHeaders(version int) []sarama.RecordHeader {
+	return []sarama.RecordHeader{
+		{Key: []byte(headerContentType), Value: []byte(contentTypeJSON)},
+		{Key: []byte(headerSchemaVersion), Value: []byte(strconv.Itoa(version))},
+	}
+}
+
+func header(message *sarama.ConsumerMessage, key string) string {
+	for _, h := range message.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+// decodeUserEvent decodes a message of the users topic in either encoding. A protobuf message
+// must name a registered schema version; as registered versions are compatible with each other,
+// it is then read with the current schema.
+func decodeUserEvent(message *sarama.ConsumerMessage, receivedAt time.Time) (models.UserEvent, error) {
+	if header(message, headerContentType) != contentTypeProtobuf {
+		return models.ParseUserEvent(message.Value, receivedAt) //nolint:wrapcheck
+	}
+
+	if err := checkSchemaVersion(usersSubject, header(message, headerSchemaVersion)); err != nil {
+		return models.UserEvent{}, err
+	}
+
+	var event usersv1.UserEvent
+
+	if err := proto.Unmarshal(message.Value, &event); err != nil {
+		return models.UserEvent{}, fmt.Errorf("%w: %w", models.ErrInvalidUserEvent, err)
+	}
+
+	return userEventFromProto(&event, receivedAt)
+}
+
+func checkSchemaVersion(subject, value string) error {
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%w: %q", errUnknownSchemaVersion, value)
+	}
+
+	if _, err = registry().Schema(subject, version); err != nil {
+		if errors.Is(err, schemaregistry.ErrVersionNotFound) {
+			return fmt.Errorf("%w: %s version %d", errUnknownSchemaVersion, subject, version)
+		}
+
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func userEventToProto(event models.UserEvent) *usersv1.UserEvent {
+	message := &usersv1.UserEvent{
+		Type:   userEventTypes[event.Type],
+		UserId: uuid.UUID(event.UserID).String(),
+	}
+
+	if !event.OccurredAt.IsZero() {
+		message.OccurredAt = timestamppb.New(event.OccurredAt)
+	}
+
+	if event.Profile != nil {
+		message.Profile = &usersv1.UserProfile{
+			FirstName: event.Profile.FirstName,
+			LastName:  event.Profile.LastName,
+			Gender:    event.Profile.Gender,
+			Age:       int32(event.Profile.Age), //nolint:gosec
+		}
+	}
+
+	return message
+}
+
+func userEventFromProto(message *usersv1.UserEvent, receivedAt time.Time) (models.UserEvent, error) {
+	event := models.UserEvent{
+		Version:    models.UserEventVersion,
+		OccurredAt: receivedAt,
+	}
+
+	for eventType, protoType := range userEventTypes {
+		if message.GetType() == protoType {
+			event.Type = eventType
+		}
+	}
+
+	if event.Type == "" {
+		return models.UserEvent{}, fmt.Errorf("%w: unknown type %s", models.ErrInvalidUserEvent, message.GetType())
+	}
+
+	userID, err := uuid.Parse(message.GetUserId())
+	if err != nil || userID == uuid.Nil {
+		return models.UserEvent{}, fmt.Errorf("%w: invalid user ID %q", models.ErrInvalidUserEvent, message.GetUserId())
+	}
+
+	event.UserID = models.UserID(userID)
+
+	if message.GetOccurredAt() != nil {
+		event.OccurredAt = message.GetOccurredAt().AsTime()
+	}
+
+	if profile := message.GetProfile(); profile != nil {
+		event.Profile = &models.UserProfile{
+			FirstName: profile.GetFirstName(),
+			LastName:  profile.GetLastName(),
+			Gender:    profile.GetGender(),
+			Age:       int(profile.GetAge()),
+		}
+	}
+
+	return event, nil
+}
+
+func transactionToProto(transaction models.Transaction) *transactionsv1.TransactionEvent {
+	message := &transactionsv1.TransactionEvent{
+		TransactionId: uuidString((*uuid.UUID)(&transaction.ID)),
+		Type:          transaction.Type,
+		ToWalletId:    uuidString((*uuid.UUID)(transaction.ToWalletID)),
+		FromWalletId:  uuidString((*uuid.UUID)(transaction.FromWalletID)),
+		Amount:        transaction.Amount,
+		Currency:      transaction.Currency,
+		Description:   transaction.Description,
+		CategoryId:    uuidString((*uuid.UUID)(transaction.CategoryID)),
+		Metadata:      transaction.Metadata,
+		PerformedBy:   uuidString((*uuid.UUID)(transaction.PerformedBy)),
+	}
+
+	if !transaction.CommittedAt.IsZero() {
+		message.CommittedAt = timestamppb.New(transaction.CommittedAt)
+	}
+
+	if transaction.ExternalReference != nil {
+		message.ExternalReference = *transaction.ExternalReference
+	}
+
+	return message
+}
+
+// uuidString returns the ID as a string, or an empty string for a missing or nil ID.
+func uuidString(id *uuid.UUID) string {
+	if id == nil || *id == uuid.Nil {
+		return ""
+	}
+
+	return id.String()
+}
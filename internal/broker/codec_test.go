@@ -0,0 +1,222 @@
+//nolint:testpackage
+package broker
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	transactionsv1 "github.com/romanpitatelev/wallets-service/internal/broker/gen/go/transactions"
+	usersv1 "github.com/romanpitatelev/wallets-service/internal/broker/gen/go/users"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/schemaregistry"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestSchemasRegistered fails when a .proto file changed without its schema being registered
+// with cmd/schema-registry, or when registered versions were edited into incompatible ones.
+func TestSchemasRegistered(t *testing.T) {
+	t.Parallel()
+
+	for subject, message := range Schemas() {
+		_, err := registry().Version(subject, message)
+		require.NoError(t, err, subject)
+
+		versions, err := registry().Versions(subject)
+		require.NoError(t, err)
+
+		for i, version := range versions {
+			next, err := registry().Schema(subject, version)
+			require.NoError(t, err)
+
+			for _, previousVersion := range versions[:i] {
+				previous, err := registry().Schema(subject, previousVersion)
+				require.NoError(t, err)
+				require.NoError(t, schemaregistry.CheckCompatibility(previous, next), "%s %d to %d", subject, previousVersion, version)
+			}
+		}
+	}
+}
+
+//nolint:funlen
+func TestDecodeUserEvent(t *testing.T) {
+	t.Parallel()
+
+	userID := models.UserID(uuid.New())
+	receivedAt := time.Now().UTC()
+
+	event := models.UserEvent{
+		Version:    models.UserEventVersion,
+		Type:       models.UserEventCreated,
+		UserID:     userID,
+		OccurredAt: receivedAt.Add(-time.Hour),
+		Profile:    &models.UserProfile{FirstName: "Ann", LastName: "Smith", Gender: "female", Age: 34},
+	}
+
+	message, err := NewUserEventMessage(event)
+	require.NoError(t, err)
+	require.Equal(t, topic, message.Topic)
+
+	withHeaders := func(value []byte, headers ...sarama.RecordHeader) *sarama.ConsumerMessage {
+		result := &sarama.ConsumerMessage{Value: value}
+
+		for i := range headers {
+			result.Headers = append(result.Headers, &headers[i])
+		}
+
+		return result
+	}
+
+	encode := func(message *usersv1.UserEvent) []byte {
+		value, err := proto.Marshal(message)
+		require.NoError(t, err)
+
+		return value
+	}
+
+	restored, err := json.Marshal(models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventRestored, UserID: userID})
+	require.NoError(t, err)
+
+	version, err := userSchemaVersion()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		message     *sarama.ConsumerMessage
+		expected    models.UserEvent
+		expectedErr error
+	}{
+		{
+			name:     "protobuf",
+			message:  consumed(message),
+			expected: event,
+		},
+		{
+			name: "protobuf without a time is ordered by when it was received",
+			message: withHeaders(encode(&usersv1.UserEvent{Type: usersv1.UserEvent_TYPE_DELETED, UserId: uuid.UUID(userID).String()}),
+				protobufHeaders(version)...),
+			expected: models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventDeleted, UserID: userID, OccurredAt: receivedAt},
+		},
+		{
+			name:     "json",
+			message:  withHeaders(restored, jsonHeaders(models.UserEventVersion)...),
+			expected: models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventRestored, UserID: userID, OccurredAt: receivedAt},
+		},
+		{
+			name:        "unregistered schema version",
+			message:     withHeaders(consumed(message).Value, protobufHeaders(version+100)...),
+			expectedErr: errUnknownSchemaVersion,
+		},
+		{
+			name: "missing schema version",
+			message: withHeaders(consumed(message).Value,
+				sarama.RecordHeader{Key: []byte(headerContentType), Value: []byte(contentTypeProtobuf)}),
+			expectedErr: errUnknownSchemaVersion,
+		},
+		{
+			name:        "unspecified type",
+			message:     withHeaders(encode(&usersv1.UserEvent{UserId: uuid.UUID(userID).String()}), protobufHeaders(version)...),
+			expectedErr: models.ErrInvalidUserEvent,
+		},
+		{
+			name:        "invalid user ID",
+			message:     withHeaders(encode(&usersv1.UserEvent{Type: usersv1.UserEvent_TYPE_CREATED, UserId: "42"}), protobufHeaders(version)...),
+			expectedErr: models.ErrInvalidUserEvent,
+		},
+		{
+			name:        "not protobuf",
+			message:     withHeaders([]byte("{}"), protobufHeaders(version)...),
+			expectedErr: models.ErrInvalidUserEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			decoded, err := decodeUserEvent(tt.message, receivedAt)
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, tt.expected.OccurredAt.Equal(decoded.OccurredAt))
+
+			decoded.OccurredAt = tt.expected.OccurredAt
+			require.Equal(t, tt.expected, decoded)
+		})
+	}
+}
+
+func TestTransactionMessage(t *testing.T) {
+	t.Parallel()
+
+	walletID := models.WalletID(uuid.New())
+	categoryID := models.CategoryID(uuid.New())
+	userID := models.UserID(uuid.New())
+	reference := "order-1"
+
+	transaction := models.Transaction{
+		ID:                models.TxID(uuid.New()),
+		Type:              "deposit",
+		ToWalletID:        &walletID,
+		Amount:            10.5,
+		Currency:          "RUB",
+		CommittedAt:       time.Now().UTC(),
+		CategoryID:        &categoryID,
+		ExternalReference: &reference,
+		Metadata:          map[string]string{"channel": "app"},
+		PerformedBy:       &userID,
+	}
+
+	t.Run("protobuf", func(t *testing.T) {
+		t.Parallel()
+
+		message, err := (&Producer{format: FormatProtobuf}).transactionMessage(transaction)
+		require.NoError(t, err)
+
+		result := consumed(message)
+		require.Equal(t, contentTypeProtobuf, header(result, headerContentType))
+		require.NoError(t, checkSchemaVersion(transactionsSubject, header(result, headerSchemaVersion)))
+
+		var event transactionsv1.TransactionEvent
+
+		require.NoError(t, proto.Unmarshal(result.Value, &event))
+		require.Equal(t, uuid.UUID(transaction.ID).String(), event.GetTransactionId())
+		require.Equal(t, uuid.UUID(walletID).String(), event.GetToWalletId())
+		require.Empty(t, event.GetFromWalletId())
+		require.InDelta(t, 10.5, event.GetAmount(), 0.001)
+		require.Equal(t, reference, event.GetExternalReference())
+		require.Equal(t, transaction.Metadata, event.GetMetadata())
+		require.True(t, transaction.CommittedAt.Equal(event.GetCommittedAt().AsTime()))
+		require.Equal(t, uuid.UUID(categoryID).String(), event.GetCategoryId())
+		require.Equal(t, uuid.UUID(userID).String(), event.GetPerformedBy())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		message, err := (&Producer{format: FormatJSON}).transactionMessage(transaction)
+		require.NoError(t, err)
+
+		result := consumed(message)
+		require.Equal(t, contentTypeJSON, header(result, headerContentType))
+		require.Equal(t, strconv.Itoa(transactionJSONVersion), header(result, headerSchemaVersion))
+		require.Contains(t, string(result.Value), `"externalReference":"order-1"`)
+
+		var decoded models.Transaction
+
+		require.NoError(t, json.Unmarshal(result.Value, &decoded))
+		require.Equal(t, transaction.ID, decoded.ID)
+		require.True(t, transaction.CommittedAt.Equal(decoded.CommittedAt))
+		require.Equal(t, categoryID, *decoded.CategoryID)
+		require.Equal(t, userID, *decoded.PerformedBy)
+	})
+}
@@ -2,11 +2,14 @@ package broker
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/romanpitatelev/wallets-service/internal/models"
 	"github.com/rs/zerolog/log"
 )
@@ -15,21 +18,37 @@ const (
 	topic = "users"
 )
 
+// Consumer reads the users topic as a member of a consumer group, so that partitions are spread
+// across the replicas and every replica resumes from the offsets committed by the group.
 type Consumer struct {
-	consumer sarama.Consumer
+	cfg      ConsumerConfig
+	group    sarama.ConsumerGroup
 	store    userStore
+	producer sarama.SyncProducer
+	metrics  *metrics
 }
 
+// ConsumerConfig configures the consumer. A message whose event fails to be applied with a
+// transient error is retried up to MaxRetries times, waiting RetryBackoff before the first retry
+// and twice as long before each further one, and then dead-lettered.
 type ConsumerConfig struct {
-	Addr string
+	Addr         string
+	GroupID      string
+	MaxRetries   int
+	RetryBackoff time.Duration
 }
 
 type userStore interface {
-	UpsertUser(ctx context.Context, users models.User) error
+	ApplyUserEvent(ctx context.Context, event models.UserEvent) error
 }
 
 func NewConsumer(store userStore, cfg ConsumerConfig) (*Consumer, error) {
-	var consumer sarama.Consumer
+	config := sarama.NewConfig()
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+
+	var group sarama.ConsumerGroup
 
 	var err error
 
@@ -37,65 +56,231 @@ func NewConsumer(store userStore, cfg ConsumerConfig) (*Consumer, error) {
 	delay := time.Second
 
 	for i := range maxRetries {
-		consumer, err = sarama.NewConsumer([]string{cfg.Addr}, nil)
+		group, err = sarama.NewConsumerGroup([]string{cfg.Addr}, cfg.GroupID, config)
 		if err == nil {
 			break
 		}
 
-		log.Warn().Err(err).Msgf("failed to create Kafka consumer (attempt%d/%d), retrying ...", i+1, maxRetries)
+		log.Warn().Err(err).Msgf("failed to create Kafka consumer group (attempt%d/%d), retrying ...", i+1, maxRetries)
 		time.Sleep(delay * 1)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kafka consumer in sarama.NewConsumer(): %w", err)
+		return nil, fmt.Errorf("failed to create Kafka consumer group in sarama.NewConsumerGroup(): %w", err)
+	}
+
+	producerConfig := sarama.NewConfig()
+	producerConfig.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer([]string{cfg.Addr}, producerConfig)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to create dead letter producer: %w", err), group.Close())
 	}
 
 	return &Consumer{
-		consumer: consumer,
+		cfg:      cfg,
+		group:    group,
 		store:    store,
+		producer: producer,
+		metrics:  sharedMetrics(),
 	}, nil
 }
 
+// Run consumes until the context is cancelled. Consume returns on every rebalance, after which
+// the consumer joins the group again and gets its new share of the partitions. Unprocessable
+// messages are dead-lettered, so Run only fails when that is impossible, as it stops the
+// partition and its messages would be lost otherwise.
 func (c *Consumer) Run(ctx context.Context) error {
-	partConsumer, err := c.consumer.ConsumePartition(topic, 0, sarama.OffsetNewest)
-	if err != nil {
-		return fmt.Errorf("failed to initiate consumer in Run(): %w", err)
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	consumeErr := make(chan error, 1)
+
+	go func() {
+		for {
+			if err := c.group.Consume(ctx, []string{topic}, c); err != nil {
+				if !errors.Is(err, sarama.ErrClosedConsumerGroup) {
+					consumeErr <- fmt.Errorf("failed to consume in Run(): %w", err)
+				}
+
+				return
+			}
 
-	defer func() {
-		if err := partConsumer.Close(); err != nil {
-			log.Warn().Err(err).Msg("failed to close partConsumer")
+			if ctx.Err() != nil {
+				return
+			}
 		}
 	}()
 
 	for {
 		select {
-		case message := <-partConsumer.Messages():
-			log.Trace().Msg("message received")
+		case err := <-consumeErr:
+			return err
+		case err := <-c.group.Errors():
+			if errors.Is(err, errDeadLetterFailed) {
+				return fmt.Errorf("error from consumer group: %w", err)
+			}
+
+			log.Warn().Err(err).Msg("error from consumer group")
+		case <-ctx.Done():
+			log.Info().Msg("shutting down from ctx.Done()")
+
+			return nil
+		}
+	}
+}
+
+func (c *Consumer) Setup(session sarama.ConsumerGroupSession) error {
+	log.Info().Interface("claims", session.Claims()).Msg("kafka consumer group partitions assigned")
+
+	return nil
+}
+
+// Cleanup drops the lag of the released partitions, which are reported by their new owner.
+func (c *Consumer) Cleanup(session sarama.ConsumerGroupSession) error {
+	for claimedTopic, partitions := range session.Claims() {
+		for _, partition := range partitions {
+			c.metrics.consumerLag.DeleteLabelValues(claimedTopic, strconv.Itoa(int(partition)))
+		}
+	}
+
+	return nil
+}
 
-			var user models.User
+// ConsumeClaim applies the user events of a partition in order. A message is marked only once its
+// event is applied or the message is dead-lettered, so after a rebalance it is delivered again.
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	partition := strconv.Itoa(int(claim.Partition()))
 
-			if err := json.Unmarshal(message.Value, &user); err != nil {
-				return fmt.Errorf("failed to unmarshal message in the for loop: %w", err)
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
 			}
 
-			if err := c.store.UpsertUser(ctx, user); err != nil {
-				return fmt.Errorf("failed to upsert user in for loop: %w", err)
+			log.Trace().Msg("message received")
+
+			done, err := c.handle(session.Context(), message)
+			if err != nil {
+				return err
+			}
+
+			if !done {
+				return nil
 			}
-		case err = <-partConsumer.Errors():
-			return fmt.Errorf("error from consumer in for loop: %w", err)
-		case <-ctx.Done():
-			log.Info().Msg("shutting down from ctx.Done()")
 
+			session.MarkMessage(message, "")
+
+			c.metrics.consumerLag.WithLabelValues(claim.Topic(), partition).
+				Set(float64(claim.HighWaterMarkOffset() - message.Offset - 1))
+		case <-session.Context().Done():
 			return nil
 		}
 	}
 }
 
+// handle processes a message and reports whether it is done with, either applied or dead-lettered.
+// It is not done when the session ends during a retry. Only a failure to dead-letter the message
+// is returned.
+func (c *Consumer) handle(ctx context.Context, message *sarama.ConsumerMessage) (bool, error) {
+	err := c.process(ctx, message)
+
+	switch {
+	case err == nil:
+		return true, nil
+	case ctx.Err() != nil:
+		return false, nil
+	}
+
+	reason := dlqReasonStore
+	if errors.Is(err, errMalformedMessage) {
+		reason = dlqReasonMalformed
+	}
+
+	log.Error().Err(err).
+		Str("topic", message.Topic).
+		Int32("partition", message.Partition).
+		Int64("offset", message.Offset).
+		Msg("unprocessable message, sending it to the dead letter queue")
+
+	if err = c.deadLetter(message, err); err != nil {
+		return false, err
+	}
+
+	c.metrics.deadLettered.WithLabelValues(message.Topic, reason).Inc()
+
+	return true, nil
+}
+
+func (c *Consumer) process(ctx context.Context, message *sarama.ConsumerMessage) error {
+	// Events without a time of their own are ordered by when they were produced.
+	producedAt := message.Timestamp
+	if producedAt.IsZero() {
+		producedAt = time.Now()
+	}
+
+	event, err := decodeUserEvent(message, producedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errMalformedMessage, err)
+	}
+
+	backoff := c.cfg.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := c.store.ApplyUserEvent(ctx, event)
+		if err == nil {
+			return nil
+		}
+
+		if !transient(err) || attempt >= c.cfg.MaxRetries {
+			return fmt.Errorf("failed to apply user event after %d attempts: %w", attempt+1, err)
+		}
+
+		log.Warn().Err(err).Int64("offset", message.Offset).Msgf("failed to apply user event, retrying in %s", backoff)
+		c.metrics.consumerRetries.WithLabelValues(message.Topic).Inc()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// transient reports whether applying an event may succeed when tried again: connection failures,
+// serialization failures and deadlocks, and an overloaded or restarting database. Constraint
+// violations and invalid data fail the same way every time.
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) {
+		return true
+	}
+
+	return pgerrcode.IsConnectionException(pgErr.Code) ||
+		pgerrcode.IsTransactionRollback(pgErr.Code) ||
+		pgerrcode.IsInsufficientResources(pgErr.Code) ||
+		pgerrcode.IsOperatorIntervention(pgErr.Code) ||
+		pgerrcode.IsSystemError(pgErr.Code)
+}
+
 func (c *Consumer) Close() error {
-	if err := c.consumer.Close(); err != nil {
-		return fmt.Errorf("failed to close Kafka consumer: %w", err)
+	var errs []error
+
+	if err := c.group.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close Kafka consumer group: %w", err))
 	}
 
-	return nil
+	if err := c.producer.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close dead letter producer: %w", err))
+	}
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,320 @@
+//nolint:testpackage
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUserStore struct {
+	errs   []error
+	calls  int
+	events []models.UserEvent
+}
+
+func (f *fakeUserStore) ApplyUserEvent(_ context.Context, event models.UserEvent) error {
+	f.calls++
+	f.events = append(f.events, event)
+
+	if len(f.errs) == 0 {
+		return nil
+	}
+
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+
+	return err
+}
+
+//nolint:funlen
+func TestHandle(t *testing.T) {
+	t.Parallel()
+
+	var (
+		connectionErr = &pgconn.PgError{Code: pgerrcode.ConnectionFailure}
+		constraintErr = &pgconn.PgError{Code: pgerrcode.CheckViolation}
+	)
+
+	validUser, err := json.Marshal(models.User{UserID: models.UserID(uuid.New())})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		value         []byte
+		storeErrs     []error
+		dlqErr        error
+		expectedCalls int
+		deadLettered  bool
+		expectedErr   bool
+	}{
+		{name: "stored", value: validUser, expectedCalls: 1},
+		{name: "malformed", value: []byte(`{"userId":`), deadLettered: true},
+		{name: "transient error is retried", value: validUser, storeErrs: []error{connectionErr, connectionErr}, expectedCalls: 3},
+		{name: "retries are bounded", value: validUser, storeErrs: []error{connectionErr, connectionErr, connectionErr, connectionErr}, expectedCalls: 3, deadLettered: true},
+		{name: "permanent error is not retried", value: validUser, storeErrs: []error{constraintErr}, expectedCalls: 1, deadLettered: true},
+		{name: "dead letter queue unavailable", value: []byte(`[]`), dlqErr: sarama.ErrOutOfBrokers, deadLettered: true, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeUserStore{errs: tt.storeErrs}
+			producer := mocks.NewSyncProducer(t, nil)
+
+			message := &sarama.ConsumerMessage{
+				Topic:     topic,
+				Partition: 2,
+				Offset:    42,
+				Key:       []byte("key"),
+				Value:     tt.value,
+				Headers:   []*sarama.RecordHeader{{Key: []byte("trace-id"), Value: []byte("abc")}},
+			}
+
+			if tt.deadLettered {
+				producer.ExpectSendMessageWithMessageCheckerFunctionAndFail(func(m *sarama.ProducerMessage) error {
+					if m.Topic != dlqTopic {
+						return errors.New("unexpected topic " + m.Topic)
+					}
+
+					replay := replayMessage(consumed(m))
+					if replay.Topic != topic || len(replay.Headers) != 1 {
+						return errors.New("original topic or headers are lost")
+					}
+
+					return nil
+				}, tt.dlqErr)
+			}
+
+			consumer := &Consumer{
+				cfg:      ConsumerConfig{MaxRetries: 2, RetryBackoff: time.Millisecond},
+				store:    store,
+				producer: producer,
+				metrics:  sharedMetrics(),
+			}
+
+			done, err := consumer.handle(context.Background(), message)
+
+			require.Equal(t, tt.expectedCalls, store.calls)
+			require.NoError(t, producer.Close())
+
+			if tt.expectedErr {
+				require.ErrorIs(t, err, errDeadLetterFailed)
+				require.False(t, done)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, done)
+		})
+	}
+}
+
+//nolint:funlen
+func TestUserEvents(t *testing.T) {
+	t.Parallel()
+
+	userID := models.UserID(uuid.New())
+	producedAt := time.Now().Add(-time.Minute).UTC()
+	deletedAt := producedAt.Add(-time.Hour)
+
+	encode := func(v any) []byte {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	type generatorUser struct {
+		UserID    models.UserID `json:"userid"`
+		FirstName string        `json:"firstName"`
+		Deleted   bool          `json:"deleted"`
+	}
+
+	tests := []struct {
+		name        string
+		value       []byte
+		expected    models.UserEvent
+		expectedErr error
+	}{
+		{
+			name:     "event",
+			value:    encode(models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventDeleted, UserID: userID, OccurredAt: deletedAt}),
+			expected: models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventDeleted, UserID: userID, OccurredAt: deletedAt},
+		},
+		{
+			name:     "event without a time is ordered by when it was produced",
+			value:    encode(models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventRestored, UserID: userID}),
+			expected: models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventRestored, UserID: userID, OccurredAt: producedAt},
+		},
+		{
+			name:     "legacy user",
+			value:    encode(models.User{UserID: userID}),
+			expected: models.UserEvent{Type: models.UserEventRestored, UserID: userID, OccurredAt: producedAt},
+		},
+		{
+			name:     "legacy deleted user",
+			value:    encode(models.User{UserID: userID, DeletedAt: &deletedAt}),
+			expected: models.UserEvent{Type: models.UserEventDeleted, UserID: userID, OccurredAt: deletedAt},
+		},
+		{
+			name:  "legacy generator user",
+			value: encode(generatorUser{UserID: userID, FirstName: "Ann", Deleted: true}),
+			expected: models.UserEvent{
+				Type:       models.UserEventDeleted,
+				UserID:     userID,
+				OccurredAt: producedAt,
+				Profile:    &models.UserProfile{FirstName: "Ann"},
+			},
+		},
+		{
+			name:        "unsupported version",
+			value:       encode(models.UserEvent{Version: 99, Type: models.UserEventCreated, UserID: userID}),
+			expectedErr: models.ErrUnsupportedEventVersion,
+		},
+		{
+			name:        "unknown type",
+			value:       encode(models.UserEvent{Version: models.UserEventVersion, Type: "user.renamed", UserID: userID}),
+			expectedErr: models.ErrInvalidUserEvent,
+		},
+		{
+			name:        "missing user",
+			value:       []byte(`{"version":1,"type":"user.created"}`),
+			expectedErr: models.ErrInvalidUserEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeUserStore{}
+			consumer := &Consumer{store: store, metrics: sharedMetrics()}
+
+			err := consumer.process(context.Background(), &sarama.ConsumerMessage{Value: tt.value, Timestamp: producedAt})
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, errMalformedMessage)
+				require.ErrorIs(t, err, tt.expectedErr)
+				require.Empty(t, store.events)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, store.events, 1)
+			require.True(t, tt.expected.OccurredAt.Equal(store.events[0].OccurredAt))
+
+			store.events[0].OccurredAt = tt.expected.OccurredAt
+			require.Equal(t, tt.expected, store.events[0])
+		})
+	}
+}
+
+// TestReplayKeepsProducedAt replays a dead-lettered event without a time of its own after the
+// user was restored: the event keeps the time it was produced at and is ordered before the restore.
+func TestReplayKeepsProducedAt(t *testing.T) {
+	t.Parallel()
+
+	userID := models.UserID(uuid.New())
+	producedAt := time.Now().Add(-time.Hour).UTC()
+	restoredAt := producedAt.Add(time.Minute)
+
+	deleted, err := json.Marshal(models.UserEvent{Version: models.UserEventVersion, Type: models.UserEventDeleted, UserID: userID})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		dlqCopy  func(m *sarama.ProducerMessage) *sarama.ConsumerMessage
+		replayed func(m *sarama.ProducerMessage) *sarama.ConsumerMessage
+	}{
+		{
+			name:     "timestamps kept by the broker",
+			dlqCopy:  consumed,
+			replayed: consumed,
+		},
+		{
+			name: "timestamps set by the broker",
+			dlqCopy: func(m *sarama.ProducerMessage) *sarama.ConsumerMessage {
+				message := consumed(m)
+				message.Timestamp = time.Now()
+
+				return message
+			},
+			replayed: consumed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var deadLettered *sarama.ProducerMessage
+
+			producer := mocks.NewSyncProducer(t, nil)
+			producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(m *sarama.ProducerMessage) error {
+				deadLettered = m
+
+				return nil
+			})
+
+			consumer := &Consumer{producer: producer, metrics: sharedMetrics()}
+
+			message := &sarama.ConsumerMessage{Topic: topic, Value: deleted, Timestamp: producedAt}
+			require.NoError(t, consumer.deadLetter(message, errors.New("database unavailable")))
+			require.NoError(t, producer.Close())
+
+			replay := replayMessage(tt.dlqCopy(deadLettered))
+
+			store := &fakeUserStore{}
+			consumer = &Consumer{store: store, metrics: sharedMetrics()}
+
+			require.NoError(t, consumer.process(context.Background(), tt.replayed(replay)))
+			require.Len(t, store.events, 1)
+			require.True(t, producedAt.Equal(store.events[0].OccurredAt))
+			require.True(t, store.events[0].OccurredAt.Before(restoredAt))
+		})
+	}
+}
+
+func TestHandleCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	consumer := &Consumer{
+		cfg:     ConsumerConfig{MaxRetries: 2, RetryBackoff: time.Hour},
+		store:   &fakeUserStore{errs: []error{errors.New("connection refused")}},
+		metrics: sharedMetrics(),
+	}
+
+	done, err := consumer.handle(ctx, &sarama.ConsumerMessage{Value: []byte(`{}`)})
+	require.NoError(t, err)
+	require.False(t, done, "a message is not done with when the session ends during a retry")
+}
+
+// consumed turns a produced message into the message a consumer reads.
+func consumed(message *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	value, _ := message.Value.Encode()
+
+	result := &sarama.ConsumerMessage{Topic: message.Topic, Value: value, Timestamp: message.Timestamp}
+
+	for i := range message.Headers {
+		result.Headers = append(result.Headers, &message.Headers[i])
+	}
+
+	return result
+}
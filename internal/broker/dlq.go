@@ -0,0 +1,252 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dlqTopic = topic + ".dlq"
+
+	dlqHeaderPrefix    = "dlq-"
+	dlqHeaderError     = dlqHeaderPrefix + "error"
+	dlqHeaderTopic     = dlqHeaderPrefix + "original-topic"
+	dlqHeaderPartition = dlqHeaderPrefix + "original-partition"
+	dlqHeaderOffset    = dlqHeaderPrefix + "original-offset"
+	dlqHeaderTimestamp = dlqHeaderPrefix + "original-timestamp"
+	dlqHeaderFailedAt  = dlqHeaderPrefix + "failed-at"
+
+	dlqReasonMalformed = "malformed"
+	dlqReasonStore     = "store"
+)
+
+var (
+	errMalformedMessage = errors.New("malformed message")
+	errDeadLetterFailed = errors.New("failed to send message to the dead letter queue")
+)
+
+// deadLetter publishes the message to the dead letter queue with its key, value and headers
+// unchanged, adding headers with the error, where the message was read from and when it was
+// produced, which orders the events without a time of their own once the message is replayed.
+func (c *Consumer) deadLetter(message *sarama.ConsumerMessage, cause error) error {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+6) //nolint:mnd
+
+	for _, header := range message.Headers {
+		headers = append(headers, *header)
+	}
+
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(dlqHeaderError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(dlqHeaderTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(dlqHeaderPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(dlqHeaderOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(dlqHeaderTimestamp), Value: []byte(message.Timestamp.UTC().Format(time.RFC3339Nano))},
+		sarama.RecordHeader{Key: []byte(dlqHeaderFailedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	deadLettered := &sarama.ProducerMessage{
+		Topic:     dlqTopic,
+		Value:     sarama.ByteEncoder(message.Value),
+		Headers:   headers,
+		Timestamp: message.Timestamp,
+	}
+
+	if message.Key != nil {
+		deadLettered.Key = sarama.ByteEncoder(message.Key)
+	}
+
+	if _, _, err := c.producer.SendMessage(deadLettered); err != nil {
+		return fmt.Errorf("%w at offset %d: %w", errDeadLetterFailed, message.Offset, err)
+	}
+
+	return nil
+}
+
+type ReplayConfig struct {
+	Addr    string
+	GroupID string
+}
+
+// ReplayDLQ sends the dead-lettered messages back to the topics they were read from, once the
+// cause of their failure is fixed, and returns how many it sent. It stops at the end of the dead
+// letter queue as of the start of the replay. Replayed offsets are committed for the group, so
+// every message is replayed once; messages that fail again are dead-lettered again.
+func ReplayDLQ(ctx context.Context, cfg ReplayConfig) (int, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	client, err := sarama.NewClient([]string{cfg.Addr}, config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close Kafka client")
+		}
+	}()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create replay producer: %w", err)
+	}
+
+	defer func() {
+		if err := producer.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close replay producer")
+		}
+	}()
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create replay consumer: %w", err)
+	}
+
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close replay consumer")
+		}
+	}()
+
+	offsets, err := sarama.NewOffsetManagerFromClient(cfg.GroupID, client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create offset manager: %w", err)
+	}
+
+	defer func() {
+		if err := offsets.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close offset manager")
+		}
+	}()
+
+	partitions, err := client.Partitions(dlqTopic)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get dead letter queue partitions: %w", err)
+	}
+
+	var replayed int
+
+	for _, partition := range partitions {
+		count, err := replayPartition(ctx, client, consumer, producer, offsets, partition)
+		replayed += count
+
+		offsets.Commit()
+
+		if err != nil {
+			return replayed, err
+		}
+	}
+
+	return replayed, nil
+}
+
+//nolint:cyclop
+func replayPartition(
+	ctx context.Context,
+	client sarama.Client,
+	consumer sarama.Consumer,
+	producer sarama.SyncProducer,
+	offsets sarama.OffsetManager,
+	partition int32,
+) (int, error) {
+	end, err := client.GetOffset(dlqTopic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the end of partition %d: %w", partition, err)
+	}
+
+	partitionOffsets, err := offsets.ManagePartition(dlqTopic, partition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get replayed offset of partition %d: %w", partition, err)
+	}
+
+	defer func() {
+		if err := partitionOffsets.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close partition offset manager")
+		}
+	}()
+
+	next, _ := partitionOffsets.NextOffset()
+	if next == sarama.OffsetOldest {
+		if next, err = client.GetOffset(dlqTopic, partition, sarama.OffsetOldest); err != nil {
+			return 0, fmt.Errorf("failed to get the start of partition %d: %w", partition, err)
+		}
+	}
+
+	if next >= end {
+		return 0, nil
+	}
+
+	partitionConsumer, err := consumer.ConsumePartition(dlqTopic, partition, next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume partition %d: %w", partition, err)
+	}
+
+	defer func() {
+		if err := partitionConsumer.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close partition consumer")
+		}
+	}()
+
+	var replayed int
+
+	for {
+		select {
+		case message := <-partitionConsumer.Messages():
+			if _, _, err = producer.SendMessage(replayMessage(message)); err != nil {
+				return replayed, fmt.Errorf("failed to replay message at offset %d: %w", message.Offset, err)
+			}
+
+			partitionOffsets.MarkOffset(message.Offset+1, "")
+			replayed++
+
+			if message.Offset+1 >= end {
+				return replayed, nil
+			}
+		case err := <-partitionConsumer.Errors():
+			return replayed, fmt.Errorf("error from replay consumer: %w", err)
+		case <-ctx.Done():
+			return replayed, ctx.Err() //nolint:wrapcheck
+		}
+	}
+}
+
+// replayMessage restores a dead-lettered message as it was read from its original topic,
+// including when it was produced: the consumer orders events without a time of their own by
+// it, so a replayed event must not overwrite the changes made after it while it was dead-lettered.
+func replayMessage(message *sarama.ConsumerMessage) *sarama.ProducerMessage {
+	replay := &sarama.ProducerMessage{
+		Topic:     topic,
+		Value:     sarama.ByteEncoder(message.Value),
+		Timestamp: message.Timestamp,
+	}
+
+	if message.Key != nil {
+		replay.Key = sarama.ByteEncoder(message.Key)
+	}
+
+	for _, header := range message.Headers {
+		key := string(header.Key)
+
+		switch {
+		case key == dlqHeaderTopic:
+			replay.Topic = string(header.Value)
+		case key == dlqHeaderTimestamp:
+			if producedAt, err := time.Parse(time.RFC3339Nano, string(header.Value)); err == nil && !producedAt.IsZero() {
+				replay.Timestamp = producedAt
+			}
+		case !strings.HasPrefix(key, dlqHeaderPrefix):
+			replay.Headers = append(replay.Headers, *header)
+		}
+	}
+
+	return replay
+}
@@ -0,0 +1,64 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "wallets_service"
+	subsystem = "broker"
+)
+
+type metrics struct {
+	consumerLag     *prometheus.GaugeVec
+	consumerRetries *prometheus.CounterVec
+	deadLettered    *prometheus.CounterVec
+}
+
+// The consumers of a process share their metrics, as a metric can only be registered once.
+//
+//nolint:gochecknoglobals
+var (
+	registerMetrics sync.Once
+	brokerMetrics   *metrics
+)
+
+func sharedMetrics() *metrics {
+	registerMetrics.Do(func() {
+		brokerMetrics = newMetrics()
+	})
+
+	return brokerMetrics
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		consumerLag: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "consumer_lag",
+				Help:      "Messages in a partition not yet consumed by this replica.",
+			},
+			[]string{"topic", "partition"}),
+		consumerRetries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "consumer_retries_total",
+				Help:      "Retries of messages that failed to be processed with a transient error.",
+			},
+			[]string{"topic"}),
+		deadLettered: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "dead_lettered_total",
+				Help:      "Messages sent to the dead letter queue.",
+			},
+			[]string{"topic", "reason"}),
+	}
+}
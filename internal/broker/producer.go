@@ -2,23 +2,52 @@ package broker
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/IBM/sarama"
+	"github.com/google/uuid"
 	"github.com/romanpitatelev/wallets-service/internal/models"
+	"google.golang.org/protobuf/proto"
 )
 
-const transactiontTopic = "transaction_update"
+const (
+	transactiontTopic = "transaction_update"
+	budgetAlertTopic  = "budget_alerts"
+)
+
+const (
+	FormatProtobuf = "protobuf"
+	FormatJSON     = "json"
+)
 
+// ProducerConfig configures the producer. Transactions are encoded in Format, json unless
+// protobuf is set once every consumer reads it. Either encoding is versioned in the
+// schema-version header.
 type ProducerConfig struct {
-	Addr string
+	Addr   string
+	Format string
 }
 
 type Producer struct {
 	producer sarama.SyncProducer
+	format   string
 }
 
+var errUnknownFormat = errors.New("unknown message format")
+
 func NewProducer(cfg ProducerConfig) (*Producer, error) {
+	switch cfg.Format {
+	case "", FormatJSON:
+		cfg.Format = FormatJSON
+	case FormatProtobuf:
+		if _, err := transactionSchemaVersion(); err != nil {
+			return nil, fmt.Errorf("failed to find transaction schema: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, cfg.Format)
+	}
+
 	config := sarama.NewConfig()
 	config.Producer.Return.Successes = true
 
@@ -27,7 +56,7 @@ func NewProducer(cfg ProducerConfig) (*Producer, error) {
 		return nil, fmt.Errorf("error creating producer: %w", err)
 	}
 
-	return &Producer{producer: producer}, nil
+	return &Producer{producer: producer, format: cfg.Format}, nil
 }
 
 func (p *Producer) Close() error {
@@ -39,13 +68,58 @@ func (p *Producer) Close() error {
 }
 
 func (p *Producer) ProduceTxToKafka(transaction models.Transaction) error {
-	bytes, err := json.Marshal(transaction)
+	message, err := p.transactionMessage(transaction)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := p.producer.SendMessage(message); err != nil {
+		return fmt.Errorf("error sending message to Kafka: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Producer) transactionMessage(transaction models.Transaction) (*sarama.ProducerMessage, error) {
+	if p.format == FormatJSON {
+		bytes, err := json.Marshal(transaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON when sending tx to kafka: %w", err)
+		}
+
+		return &sarama.ProducerMessage{
+			Topic:   transactiontTopic,
+			Value:   sarama.ByteEncoder(bytes),
+			Headers: jsonHeaders(transactionJSONVersion),
+		}, nil
+	}
+
+	version, err := transactionSchemaVersion()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	bytes, err := proto.Marshal(transactionToProto(transaction))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction when sending tx to kafka: %w", err)
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   transactiontTopic,
+		Value:   sarama.ByteEncoder(bytes),
+		Headers: protobufHeaders(version),
+	}, nil
+}
+
+func (p *Producer) ProduceBudgetAlert(alert models.BudgetAlert) error {
+	bytes, err := json.Marshal(alert)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON when sending tx to kafka: %w", err)
+		return fmt.Errorf("failed to marshal JSON when sending budget alert to kafka: %w", err)
 	}
 
 	message := &sarama.ProducerMessage{
-		Topic: transactiontTopic,
+		Topic: budgetAlertTopic,
+		Key:   sarama.StringEncoder(uuid.UUID(alert.UserID).String()),
 		Value: sarama.StringEncoder(bytes),
 	}
 
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+var ErrInvalidGranularity = errors.New("invalid analytics granularity")
+
+// AnalyticsRequest selects whole periods of the granularity in UTC. Validate widens From and To
+// to period boundaries, so every period overlapping [From, To) is reported in full; To stays
+// exclusive when it already falls on a boundary.
+type AnalyticsRequest struct {
+	Granularity       string     `json:"granularity"`
+	From              *time.Time `json:"from,omitempty"`
+	To                *time.Time `json:"to,omitempty"`
+	WalletID          *WalletID  `json:"walletId,omitempty"`
+	ReportingCurrency string     `json:"reportingCurrency,omitempty"`
+}
+
+type AnalyticsBucket struct {
+	PeriodStart time.Time `json:"periodStart"`
+	Type        string    `json:"type"`
+	Currency    string    `json:"currency"`
+	Inflow      float64   `json:"inflow"`
+	Outflow     float64   `json:"outflow"`
+	Net         float64   `json:"net"`
+	Count       int64     `json:"count"`
+	Converted   *Amounts  `json:"converted,omitempty"`
+}
+
+type Amounts struct {
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Net     float64 `json:"net"`
+}
+
+type AnalyticsReport struct {
+	Granularity       string            `json:"granularity"`
+	WalletID          *WalletID         `json:"walletId,omitempty"`
+	ReportingCurrency string            `json:"reportingCurrency,omitempty"`
+	Buckets           []AnalyticsBucket `json:"buckets"`
+	Total             *Amounts          `json:"total,omitempty"`
+}
+
+func (r *AnalyticsRequest) Validate() error {
+	switch r.Granularity {
+	case "":
+		r.Granularity = GranularityDay
+	case GranularityDay, GranularityWeek, GranularityMonth:
+	default:
+		return ErrInvalidGranularity
+	}
+
+	if r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return ErrInvalidFilter
+	}
+
+	if r.From != nil {
+		from := periodStart(*r.From, r.Granularity)
+		r.From = &from
+	}
+
+	if r.To != nil {
+		to := periodStart(*r.To, r.Granularity)
+		if to.Before(*r.To) {
+			to = nextPeriod(to, r.Granularity)
+		}
+
+		r.To = &to
+	}
+
+	return nil
+}
+
+// periodStart returns the start of the period containing t: the UTC day, the ISO week starting
+// on Monday, or the month, as date_trunc buckets the rollups.
+func periodStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch granularity {
+	case GranularityWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7) //nolint:mnd
+	case GranularityMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+func nextPeriod(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7) //nolint:mnd
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleAuditor = "auditor"
+)
+
+var ErrForbidden = errors.New("access forbidden")
+
+type AuditRecord struct {
+	ID          int64           `json:"id"`
+	RequestID   string          `json:"requestId"`
+	UserID      UserID          `json:"userId"`
+	Role        string          `json:"role"`
+	Method      string          `json:"method"`
+	Route       string          `json:"route"`
+	WalletIDs   []WalletID      `json:"walletIds"`
+	Status      int             `json:"status"`
+	LatencyMs   int64           `json:"latencyMs"`
+	RequestBody json.RawMessage `json:"requestBody,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type AuditQuery struct {
+	UserID   *UserID    `json:"userId,omitempty"`
+	WalletID *WalletID  `json:"walletId,omitempty"`
+	Route    string     `json:"route,omitempty"`
+	Method   string     `json:"method,omitempty"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Limit    int        `json:"limit,omitempty"`
+	Offset   int        `json:"offset,omitempty"`
+}
+
+func (u *UserInfo) CanAudit() bool {
+	return u.Role == RoleAuditor || u.Role == RoleAdmin
+}
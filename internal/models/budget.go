@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetID uuid.UUID
+
+const (
+	BudgetPeriodWeekly  = "weekly"
+	BudgetPeriodMonthly = "monthly"
+
+	maxBudgetThreshold = 1000
+)
+
+var (
+	ErrBudgetNotFound = errors.New("budget not found")
+	ErrInvalidBudget  = errors.New("invalid budget")
+)
+
+//nolint:gochecknoglobals
+var DefaultBudgetThresholds = []int{80, 100}
+
+// Budget limits the spending from a wallet, of a category or of a category within a wallet
+// over a calendar period. Thresholds are percentages of the amount that raise an alert once per period.
+type Budget struct {
+	BudgetID   BudgetID    `json:"budgetId"`
+	UserID     UserID      `json:"userId"`
+	Name       string      `json:"name"`
+	WalletID   *WalletID   `json:"walletId,omitempty"`
+	CategoryID *CategoryID `json:"categoryId,omitempty"`
+	Period     string      `json:"period"`
+	Amount     float64     `json:"amount"`
+	Currency   string      `json:"currency"`
+	Thresholds []int       `json:"thresholds"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+type BudgetProgress struct {
+	Budget      Budget    `json:"budget"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Spent       float64   `json:"spent"`
+	Remaining   float64   `json:"remaining"`
+	Percent     float64   `json:"percent"`
+	Crossed     []int     `json:"crossed"`
+}
+
+type BudgetAlert struct {
+	BudgetID    BudgetID  `json:"budgetId"`
+	UserID      UserID    `json:"userId"`
+	Name        string    `json:"name"`
+	Threshold   int       `json:"threshold"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Spent       float64   `json:"spent"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type CurrencyAmount struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+func (b *Budget) Validate() error {
+	b.Name = strings.TrimSpace(b.Name)
+	b.Currency = strings.ToUpper(b.Currency)
+
+	if len(b.Thresholds) == 0 {
+		b.Thresholds = slices.Clone(DefaultBudgetThresholds)
+	}
+
+	slices.Sort(b.Thresholds)
+	b.Thresholds = slices.Compact(b.Thresholds)
+
+	switch {
+	case b.Name == "":
+		return ErrInvalidBudget
+	case b.WalletID == nil && b.CategoryID == nil:
+		return ErrInvalidBudget
+	case b.Period != BudgetPeriodWeekly && b.Period != BudgetPeriodMonthly:
+		return ErrInvalidBudget
+	case b.Amount <= 0:
+		return ErrInvalidBudget
+	case b.Currency == "":
+		return ErrInvalidBudget
+	case b.Thresholds[0] <= 0 || b.Thresholds[len(b.Thresholds)-1] > maxBudgetThreshold:
+		return ErrInvalidBudget
+	}
+
+	return nil
+}
+
+// PeriodBounds returns the calendar period of the budget containing t, in UTC.
+// Weeks start on Monday.
+func (b *Budget) PeriodBounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	if b.Period == BudgetPeriodWeekly {
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)) //nolint:mnd
+
+		return start, start.AddDate(0, 0, 7) //nolint:mnd
+	}
+
+	start := day.AddDate(0, 0, 1-day.Day())
+
+	return start, start.AddDate(0, 1, 0)
+}
+
+func (b *BudgetID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(b), data)
+}
+
+//nolint:wrapcheck
+func (b BudgetID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(b).String())
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	CategoryID uuid.UUID
+	RuleID     uuid.UUID
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryExists      = errors.New("category already exists")
+	ErrCategoryEmptyName   = errors.New("category name cannot be empty")
+	ErrRuleNotFound        = errors.New("category rule not found")
+	ErrInvalidRule         = errors.New("invalid category rule")
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+type Category struct {
+	CategoryID CategoryID `json:"categoryId"`
+	UserID     UserID     `json:"userId"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CategoryRule assigns its category to new transactions matching every condition that is set.
+// Rules are evaluated by ascending priority and the first match wins.
+type CategoryRule struct {
+	RuleID               RuleID     `json:"ruleId"`
+	CategoryID           CategoryID `json:"categoryId"`
+	Priority             int        `json:"priority"`
+	Type                 *string    `json:"type,omitempty"`
+	MinAmount            *float64   `json:"minAmount,omitempty"`
+	MaxAmount            *float64   `json:"maxAmount,omitempty"`
+	CounterpartyWalletID *WalletID  `json:"counterpartyWalletId,omitempty"`
+	DescriptionContains  *string    `json:"descriptionContains,omitempty"`
+	CreatedAt            time.Time  `json:"createdAt"`
+}
+
+type CategoryAssignment struct {
+	CategoryID *CategoryID `json:"categoryId"`
+}
+
+type CategoryTotal struct {
+	CategoryID   *CategoryID `json:"categoryId"`
+	CategoryName string      `json:"categoryName"`
+	Currency     string      `json:"currency"`
+	Count        int64       `json:"count"`
+	Amount       float64     `json:"amount"`
+}
+
+type ApplyRulesResult struct {
+	Updated int64 `json:"updated"`
+}
+
+func (c *Category) Validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return ErrCategoryEmptyName
+	}
+
+	return nil
+}
+
+func (r *CategoryRule) Validate() error {
+	switch {
+	case r.CategoryID == CategoryID(uuid.Nil):
+		return ErrInvalidRule
+	case r.Type != nil && *r.Type != "deposit" && *r.Type != "withdraw" && *r.Type != "transfer":
+		return ErrInvalidRule
+	case r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount:
+		return ErrInvalidRule
+	case r.DescriptionContains != nil && strings.TrimSpace(*r.DescriptionContains) == "":
+		return ErrInvalidRule
+	}
+
+	return nil
+}
+
+func (c *CategoryID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(c), data)
+}
+
+func (r *RuleID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(r), data)
+}
+
+//nolint:wrapcheck
+func (c CategoryID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(c).String())
+}
+
+//nolint:wrapcheck
+func (r RuleID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(r).String())
+}
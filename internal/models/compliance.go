@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CaseID uuid.UUID
+
+const (
+	RoleComplianceOfficer = "compliance_officer"
+
+	CaseKindLargeTransaction = "large_transaction"
+	CaseKindStructuring      = "structuring"
+
+	CaseStatusOpen          = "open"
+	CaseStatusInvestigating = "investigating"
+	CaseStatusReported      = "reported"
+	CaseStatusClosed        = "closed"
+
+	maxCaseNoteLength = 4096
+)
+
+var (
+	ErrCaseNotFound          = errors.New("compliance case not found")
+	ErrInvalidCaseStatus     = errors.New("invalid compliance case status")
+	ErrInvalidCaseTransition = errors.New("compliance case status cannot change this way")
+	ErrInvalidCaseNote       = errors.New("invalid compliance case note")
+)
+
+// caseTransitions lists the statuses a case can move to from each status. Closed cases are final.
+//
+//nolint:gochecknoglobals
+var caseTransitions = map[string][]string{
+	CaseStatusOpen:          {CaseStatusInvestigating, CaseStatusClosed},
+	CaseStatusInvestigating: {CaseStatusReported, CaseStatusClosed},
+	CaseStatusReported:      {CaseStatusClosed},
+	CaseStatusClosed:        {},
+}
+
+// ComplianceCase is opened automatically for a transaction above the reporting threshold of its
+// currency or for a series of deposits just below it, with the transactions attached.
+type ComplianceCase struct {
+	CaseID       CaseID        `json:"caseId"`
+	UserID       UserID        `json:"userId"`
+	Kind         string        `json:"kind"`
+	Status       string        `json:"status"`
+	Currency     string        `json:"currency"`
+	Amount       float64       `json:"amount"`
+	Transactions []Transaction `json:"transactions"`
+	Notes        []CaseNote    `json:"notes"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+}
+
+type CaseNote struct {
+	CaseID    CaseID    `json:"caseId"`
+	AuthorID  UserID    `json:"authorId"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CaseUpdate struct {
+	Status string `json:"status"`
+}
+
+// ComplianceRules configures case detection. Thresholds are keyed by upper case currency codes
+// and transactions in currencies without a threshold are not monitored. Structuring is
+// StructuringCount or more deposits within StructuringWindow, each below the threshold by at
+// most StructuringMargin of it.
+type ComplianceRules struct {
+	Thresholds        map[string]float64
+	StructuringWindow time.Duration
+	StructuringCount  int
+	StructuringMargin float64
+}
+
+func (r ComplianceRules) Threshold(currency string) (float64, bool) {
+	threshold, ok := r.Thresholds[strings.ToUpper(currency)]
+
+	return threshold, ok && threshold > 0
+}
+
+func ValidCaseStatus(status string) bool {
+	_, ok := caseTransitions[status]
+
+	return ok
+}
+
+// CanMoveTo reports whether the case may move from its status to the status.
+func (c *ComplianceCase) CanMoveTo(status string) bool {
+	return slices.Contains(caseTransitions[c.Status], status)
+}
+
+func (n *CaseNote) Validate() error {
+	n.Text = strings.TrimSpace(n.Text)
+
+	if n.Text == "" || len(n.Text) > maxCaseNoteLength {
+		return fieldError("text", ErrInvalidCaseNote)
+	}
+
+	return nil
+}
+
+func (u *UserInfo) CanInvestigate() bool {
+	return u.Role == RoleComplianceOfficer || u.Role == RoleAdmin
+}
+
+func (c *CaseID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(c), data)
+}
+
+//nolint:wrapcheck
+func (c CaseID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(c).String())
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MemberRoleOwner   = "owner"
+	MemberRoleSpender = "spender"
+	MemberRoleViewer  = "viewer"
+)
+
+var (
+	ErrMemberNotFound     = errors.New("wallet member not found")
+	ErrMemberExists       = errors.New("user is already a wallet member")
+	ErrInvalidMember      = errors.New("invalid wallet member")
+	ErrLastOwner          = errors.New("wallet must keep at least one owner")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrSpendLimitExceeded = errors.New("member spend limit exceeded")
+	ErrInsufficientRights = errors.New("insufficient wallet role")
+)
+
+// WalletMember grants a user access to a wallet. Owners have full control, spenders may move
+// funds up to their spend limit per transaction (in the wallet currency), viewers may only read.
+type WalletMember struct {
+	WalletID   WalletID  `json:"walletId"`
+	UserID     UserID    `json:"userId"`
+	Role       string    `json:"role"`
+	SpendLimit *float64  `json:"spendLimit,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func (m *WalletMember) Validate() error {
+	switch m.Role {
+	case MemberRoleOwner, MemberRoleSpender, MemberRoleViewer:
+	default:
+		return ErrInvalidMember
+	}
+
+	if m.SpendLimit != nil && (*m.SpendLimit < 0 || m.Role != MemberRoleSpender) {
+		return ErrInvalidMember
+	}
+
+	return nil
+}
+
+// CanSpend reports whether the member may deposit, withdraw or transfer funds.
+func (m *WalletMember) CanSpend() bool {
+	return m.Role == MemberRoleOwner || m.Role == MemberRoleSpender
+}
+
+// WithinLimit reports whether the member may move the amount, given in the wallet currency.
+func (m *WalletMember) WithinLimit(amount float64) bool {
+	return m.SpendLimit == nil || amount <= *m.SpendLimit
+}
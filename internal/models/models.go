@@ -1,9 +1,11 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -22,15 +24,17 @@ type User struct {
 }
 
 type Wallet struct {
-	WalletID   WalletID   `json:"walletId"`
-	UserID     UserID     `json:"userId"`
-	WalletName string     `json:"walletName"`
-	Balance    float64    `json:"balance"`
-	Currency   string     `json:"currency"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	UpdatedAt  time.Time  `json:"updatedAt"`
-	DeletedAt  *time.Time `json:"deletedAt"`
-	Active     bool       `json:"active"`
+	WalletID   WalletID      `json:"walletId"`
+	UserID     UserID        `json:"userId"`
+	WalletName string        `json:"walletName"`
+	Balance    float64       `json:"balance"`
+	Currency   string        `json:"currency"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+	DeletedAt  *time.Time    `json:"deletedAt"`
+	Active     bool          `json:"active"`
+	FrozenAt   *time.Time    `json:"frozenAt,omitempty"`
+	Membership *WalletMember `json:"membership,omitempty"`
 }
 
 type WalletUpdate struct {
@@ -40,11 +44,25 @@ type WalletUpdate struct {
 }
 
 type GetWalletsRequest struct {
-	Sorting    string `json:"sorting,omitempty"`
-	Descending bool   `json:"descending,omitempty"`
-	Limit      int    `json:"limit,omitempty"`
-	Filter     string `json:"filter,omitempty"`
-	Offset     int    `json:"offset,omitempty"`
+	Sorting           string            `json:"sorting,omitempty"`
+	Descending        bool              `json:"descending,omitempty"`
+	Limit             int               `json:"limit,omitempty"`
+	Filter            string            `json:"filter,omitempty"`
+	Offset            int               `json:"offset,omitempty"`
+	Cursor            *Cursor           `json:"-"`
+	From              *time.Time        `json:"from,omitempty"`
+	To                *time.Time        `json:"to,omitempty"`
+	MinAmount         *float64          `json:"minAmount,omitempty"`
+	MaxAmount         *float64          `json:"maxAmount,omitempty"`
+	MinBalance        *float64          `json:"minBalance,omitempty"`
+	MaxBalance        *float64          `json:"maxBalance,omitempty"`
+	Types             []string          `json:"types,omitempty"`
+	Currencies        []string          `json:"currencies,omitempty"`
+	Counterparty      *WalletID         `json:"counterpartyWalletId,omitempty"`
+	Categories        []CategoryID      `json:"categories,omitempty"`
+	Uncategorized     bool              `json:"uncategorized,omitempty"`
+	ExternalReference *string           `json:"externalReference,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
 }
 
 var (
@@ -63,6 +81,20 @@ var (
 	ErrInsufficientFunds    = errors.New("wallet has insufficient funds")
 	ErrInvalidTransaction   = errors.New("invalid wallets' data in transaction")
 	ErrInvalidUUIDFormat    = errors.New("invalid UUID format")
+	ErrInvalidFilter        = errors.New("invalid list filter")
+
+	ErrInvalidDescription         = errors.New("invalid transaction description")
+	ErrInvalidExternalReference   = errors.New("invalid transaction external reference")
+	ErrInvalidMetadata            = errors.New("invalid transaction metadata")
+	ErrDuplicateExternalReference = errors.New("duplicate transaction external reference")
+)
+
+const (
+	MaxDescriptionLength       = 500
+	MaxExternalReferenceLength = 128
+	MaxMetadataKeys            = 20
+	MaxMetadataKeyLength       = 40
+	MaxMetadataValueLength     = 500
 )
 
 type XRRequest struct {
@@ -78,6 +110,8 @@ type Claims struct {
 	UserID UserID `json:"userId"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// Scopes limits what the token may do. Tokens without the claim are not limited.
+	Scopes []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -85,21 +119,63 @@ type UserInfo struct {
 	UserID UserID `json:"userId"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// Scopes is nil for unrestricted tokens.
+	Scopes   []string  `json:"scopes,omitempty"`
+	APIKeyID *APIKeyID `json:"apiKeyId,omitempty"`
+}
+
+// HasScope reports whether the caller was granted the scope.
+func (u *UserInfo) HasScope(scope string) bool {
+	return u.Scopes == nil || slices.Contains(u.Scopes, scope)
+}
+
+// userInfoKey keys the caller's UserInfo in a context. UserInfo itself holds a slice, so it
+// cannot be a context key.
+type userInfoKey struct{}
+
+func ContextWithUserInfo(ctx context.Context, userInfo UserInfo) context.Context {
+	return context.WithValue(ctx, userInfoKey{}, userInfo)
+}
+
+func UserInfoFromContext(ctx context.Context) UserInfo {
+	val, _ := ctx.Value(userInfoKey{}).(UserInfo)
+
+	return val
 }
 
 type Transaction struct {
-	ID           TxID      `json:"transactionId"`
-	Type         string    `json:"type"`
-	ToWalletID   *WalletID `json:"toWalletId"`
-	FromWalletID *WalletID `json:"fromWalletId"`
-	Amount       float64   `json:"amount"`
-	Currency     string    `json:"currency"`
-	CommittedAt  time.Time `json:"committedAt"`
+	ID           TxID        `json:"transactionId"`
+	Type         string      `json:"type"`
+	ToWalletID   *WalletID   `json:"toWalletId"`
+	FromWalletID *WalletID   `json:"fromWalletId"`
+	Amount       float64     `json:"amount"`
+	Currency     string      `json:"currency"`
+	CommittedAt  time.Time   `json:"committedAt"`
+	Description  string      `json:"description,omitempty"`
+	CategoryID   *CategoryID `json:"categoryId,omitempty"`
+
+	ExternalReference *string           `json:"externalReference,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	PerformedBy       *UserID           `json:"performedBy,omitempty"`
+	Counterparty      *Counterparty     `json:"counterparty,omitempty"`
+}
+
+// WalletIDs returns the wallets the transaction moved money in or out of.
+func (t Transaction) WalletIDs() []WalletID {
+	walletIDs := make([]WalletID, 0, 2) //nolint:mnd
+
+	for _, walletID := range []*WalletID{t.FromWalletID, t.ToWalletID} {
+		if walletID != nil {
+			walletIDs = append(walletIDs, *walletID)
+		}
+	}
+
+	return walletIDs
 }
 
 func (w *Wallet) Validate() error {
 	if w.WalletName == "" {
-		return ErrWalletEmptyName
+		return fieldError("walletName", ErrWalletEmptyName)
 	}
 
 	w.Balance = 0
@@ -116,30 +192,50 @@ func (u *UserInfo) Validate(walletUserID UserID) error {
 	return nil
 }
 
+// Validate reports the first invalid field of the transaction as a ValidationError.
 func (t *Transaction) Validate() error {
 	switch {
 	case t.Amount == 0:
-		return ErrZeroAmount
+		return fieldError("amount", ErrZeroAmount)
 	case t.Amount < 0:
-		return ErrNegativeAmount
+		return fieldError("amount", ErrNegativeAmount)
 	case t.FromWalletID == t.ToWalletID:
-		return ErrSameWallet
+		return fieldError("toWalletId", ErrSameWallet)
+	case len([]rune(t.Description)) > MaxDescriptionLength:
+		return fieldError("description", ErrInvalidDescription)
+	case t.ExternalReference != nil &&
+		(*t.ExternalReference == "" || len([]rune(*t.ExternalReference)) > MaxExternalReferenceLength):
+		return fieldError("externalReference", ErrInvalidExternalReference)
+	case !validMetadata(t.Metadata):
+		return fieldError("metadata", ErrInvalidMetadata)
 	default:
 		if t.Type == "deposit" {
-			if t.ToWalletID == nil || t.FromWalletID != nil {
-				return ErrInvalidTransaction
+			if t.ToWalletID == nil {
+				return fieldError("toWalletId", ErrInvalidTransaction)
+			}
+
+			if t.FromWalletID != nil {
+				return fieldError("fromWalletId", ErrInvalidTransaction)
 			}
 		}
 
 		if t.Type == "withdraw" {
-			if t.ToWalletID != nil || t.FromWalletID == nil {
-				return ErrInvalidTransaction
+			if t.FromWalletID == nil {
+				return fieldError("fromWalletId", ErrInvalidTransaction)
+			}
+
+			if t.ToWalletID != nil {
+				return fieldError("toWalletId", ErrInvalidTransaction)
 			}
 		}
 
 		if t.Type == "transfer" {
-			if t.ToWalletID == nil || t.FromWalletID == nil {
-				return ErrInvalidTransaction
+			if t.FromWalletID == nil {
+				return fieldError("fromWalletId", ErrInvalidTransaction)
+			}
+
+			if t.ToWalletID == nil {
+				return fieldError("toWalletId", ErrInvalidTransaction)
 			}
 		}
 	}
@@ -147,6 +243,22 @@ func (t *Transaction) Validate() error {
 	return nil
 }
 
+// validMetadata keeps integration metadata small: a bounded number of non-empty keys
+// with bounded key and value lengths.
+func validMetadata(metadata map[string]string) bool {
+	if len(metadata) > MaxMetadataKeys {
+		return false
+	}
+
+	for key, value := range metadata {
+		if key == "" || len([]rune(key)) > MaxMetadataKeyLength || len([]rune(value)) > MaxMetadataValueLength {
+			return false
+		}
+	}
+
+	return true
+}
+
 func unmarshalUUID(id *uuid.UUID, data []byte) error {
 	var s string
 	if err := json.Unmarshal(data, &s); err != nil {
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NotificationBudgetAlert        = "budget.alert"
+	NotificationBalanceUpdated     = "balance.updated"
+	NotificationTransactionCreated = "transaction.created"
+	NotificationWalletFrozen       = "wallet.frozen"
+	NotificationWalletUnfrozen     = "wallet.unfrozen"
+)
+
+// Notification is pushed to the subscribers of a user. Notifications of wallet events carry
+// the ID of the event, which clients use to resume a stream.
+type Notification struct {
+	ID        int64     `json:"id,omitempty"`
+	Type      string    `json:"type"`
+	UserID    UserID    `json:"userId"`
+	Payload   any       `json:"payload"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WalletEvent is a balance change, a new transaction, a wallet frozen or unfrozen with the
+// lifecycle of its owner or a budget alert of a user, recorded when it commits.
+type WalletEvent struct {
+	EventID    int64           `json:"eventId"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Recipients []UserID        `json:"-"`
+}
+
+func (e *WalletEvent) Notification(userID UserID) Notification {
+	return Notification{
+		ID:        e.EventID,
+		Type:      e.Type,
+		UserID:    userID,
+		Payload:   e.Payload,
+		CreatedAt: e.CreatedAt,
+	}
+}
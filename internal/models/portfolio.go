@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type RateQuote struct {
+	FromCurrency string    `json:"fromCurrency"`
+	ToCurrency   string    `json:"toCurrency"`
+	Rate         float64   `json:"rate"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+type PortfolioWallet struct {
+	WalletID         WalletID `json:"walletId"`
+	WalletName       string   `json:"walletName"`
+	Currency         string   `json:"currency"`
+	Balance          float64  `json:"balance"`
+	ConvertedBalance *float64 `json:"convertedBalance"`
+}
+
+type PortfolioSummary struct {
+	Currency           string            `json:"currency"`
+	Wallets            []PortfolioWallet `json:"wallets"`
+	NetWorth           float64           `json:"netWorth"`
+	Complete           bool              `json:"complete"`
+	Rates              []RateQuote       `json:"rates"`
+	UnpricedCurrencies []string          `json:"unpricedCurrencies,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// Problem is an RFC 7807 error response. Code is a stable machine-readable identifier of the
+// error, Errors lists the request fields that failed validation.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError ties a validation error to the request field it was found in.
+// It unwraps to Err, so callers keep matching the sentinel with errors.Is.
+type ValidationError struct {
+	Field   string
+	Message string
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Reason()
+	}
+
+	return e.Field + ": " + e.Reason()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Reason returns the message without the field name, defaulting to the message of Err.
+func (e *ValidationError) Reason() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return e.Err.Error()
+}
+
+func fieldError(field string, err error) error {
+	return &ValidationError{Field: field, Err: err}
+}
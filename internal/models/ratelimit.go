@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Rate limit budgets. Money movements draw from their own, smaller budget so that a client
+// polling balances cannot starve its own transfers and vice versa.
+const (
+	RateLimitDefault = "default"
+	RateLimitMoney   = "money"
+)
+
+var (
+	ErrRateLimited      = errors.New("rate limit exceeded")
+	ErrInvalidRateLimit = errors.New("rate limit override must allow at least one request")
+)
+
+// RateLimit allows Requests requests per Period, refilled continuously. Zero requests means
+// the budget is not limited.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitOverride replaces the configured number of requests of a budget for one user. Unlike
+// the configured limits, an override cannot lift the limit, so it allows at least one request.
+type RateLimitOverride struct {
+	UserID   UserID `json:"userId"`
+	Budget   string `json:"budget"`
+	Requests int    `json:"requests"`
+}
+
+// RateLimitBucket is the state of a token bucket after taking a token from it.
+type RateLimitBucket struct {
+	Tokens  float64
+	Allowed bool
+}
+
+// RateLimitResult is reported to clients in the RateLimit-* headers.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero if it is allowed now.
+	RetryAfter time.Duration
+}
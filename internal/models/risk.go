@@ -0,0 +1,171 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AssessmentID uuid.UUID
+
+const (
+	RoleRiskReviewer = "risk_reviewer"
+
+	RiskRuleVelocity        = "velocity"
+	RiskRuleAmountSpike     = "amount_spike"
+	RiskRuleNewCounterparty = "new_counterparty"
+	RiskRuleCurrencyCycling = "currency_cycling"
+
+	RiskOutcomeAllow  = "allow"
+	RiskOutcomeReview = "review"
+	RiskOutcomeBlock  = "block"
+
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusDeclined = "declined"
+)
+
+var (
+	ErrTransactionBlocked = errors.New("transaction blocked by risk screening")
+	ErrTransactionHeld    = errors.New("transaction held for review")
+	ErrInvalidRiskRule    = errors.New("invalid risk rule")
+	ErrRiskRuleNotFound   = errors.New("risk rule not found")
+	ErrAssessmentNotFound = errors.New("risk assessment not found")
+	ErrAssessmentDecided  = errors.New("risk assessment already decided")
+)
+
+//nolint:gochecknoglobals
+var riskRuleName = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// RiskRule screens withdrawals and transfers. Rules are stored in the database, so operators
+// change them without a redeploy, and each names the outcome of the transactions it triggers on.
+type RiskRule struct {
+	Name      string         `json:"name"`
+	Kind      string         `json:"kind"`
+	Action    string         `json:"action"`
+	Enabled   bool           `json:"enabled"`
+	Params    RiskRuleParams `json:"params"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// RiskRuleParams holds the thresholds of all rule kinds. Each kind uses some of them:
+//   - velocity: more than MaxCount withdrawals and transfers within WindowMinutes,
+//   - amount_spike: an amount above Multiplier times the average of at least MinHistory
+//     transactions in the same currency within WindowMinutes,
+//   - new_counterparty: a transfer of at least MinAmount to a wallet the user has not
+//     transferred to within WindowMinutes,
+//   - currency_cycling: more than MaxCount cross-currency transfers within WindowMinutes.
+type RiskRuleParams struct {
+	WindowMinutes int     `json:"windowMinutes"`
+	MaxCount      int     `json:"maxCount,omitempty"`
+	Multiplier    float64 `json:"multiplier,omitempty"`
+	MinHistory    int     `json:"minHistory,omitempty"`
+	MinAmount     float64 `json:"minAmount,omitempty"`
+}
+
+func (r *RiskRule) Validate() error {
+	if !riskRuleName.MatchString(r.Name) {
+		return &ValidationError{Field: "name", Message: "must be 1-64 lowercase letters, digits, - or _", Err: ErrInvalidRiskRule}
+	}
+
+	if r.Action != RiskOutcomeReview && r.Action != RiskOutcomeBlock {
+		return &ValidationError{Field: "action", Message: "must be review or block", Err: ErrInvalidRiskRule}
+	}
+
+	if r.Params.WindowMinutes <= 0 {
+		return &ValidationError{Field: "params.windowMinutes", Message: "must be positive", Err: ErrInvalidRiskRule}
+	}
+
+	switch r.Kind {
+	case RiskRuleVelocity, RiskRuleCurrencyCycling:
+		if r.Params.MaxCount <= 0 {
+			return &ValidationError{Field: "params.maxCount", Message: "must be positive", Err: ErrInvalidRiskRule}
+		}
+	case RiskRuleAmountSpike:
+		if r.Params.Multiplier <= 1 {
+			return &ValidationError{Field: "params.multiplier", Message: "must be greater than 1", Err: ErrInvalidRiskRule}
+		}
+
+		if r.Params.MinHistory <= 0 {
+			return &ValidationError{Field: "params.minHistory", Message: "must be positive", Err: ErrInvalidRiskRule}
+		}
+	case RiskRuleNewCounterparty:
+		if r.Params.MinAmount < 0 {
+			return &ValidationError{Field: "params.minAmount", Message: "must not be negative", Err: ErrInvalidRiskRule}
+		}
+	default:
+		return &ValidationError{Field: "kind", Message: "unknown rule kind", Err: ErrInvalidRiskRule}
+	}
+
+	return nil
+}
+
+// RiskCheck is a withdrawal or transfer about to be committed. ToCurrency is the currency of
+// the receiving wallet of transfers.
+type RiskCheck struct {
+	UserID      UserID
+	Transaction Transaction
+	ToCurrency  string
+	At          time.Time
+}
+
+// RiskTransaction is a past withdrawal or transfer of the user the rules compare against.
+type RiskTransaction struct {
+	Type         string
+	FromWalletID *WalletID
+	ToWalletID   *WalletID
+	Amount       float64
+	Currency     string
+	ToCurrency   string
+	CommittedAt  time.Time
+}
+
+// RiskAssessment records a screening that blocked or held a transaction together with the
+// rules that triggered. Held transactions wait in the review queue until a reviewer approves
+// or declines them.
+type RiskAssessment struct {
+	AssessmentID AssessmentID `json:"assessmentId"`
+	UserID       UserID       `json:"userId"`
+	Outcome      string       `json:"outcome"`
+	Rules        []string     `json:"rules"`
+	Transaction  Transaction  `json:"transaction"`
+	Status       string       `json:"status,omitempty"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	DecidedAt    *time.Time   `json:"decidedAt,omitempty"`
+	DecidedBy    *UserID      `json:"decidedBy,omitempty"`
+}
+
+// RiskError reports a transaction that risk screening did not allow.
+type RiskError struct {
+	Assessment RiskAssessment
+}
+
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Unwrap(), strings.Join(e.Assessment.Rules, ", "))
+}
+
+func (e *RiskError) Unwrap() error {
+	if e.Assessment.Outcome == RiskOutcomeReview {
+		return ErrTransactionHeld
+	}
+
+	return ErrTransactionBlocked
+}
+
+func (u *UserInfo) CanReviewRisk() bool {
+	return u.Role == RoleRiskReviewer || u.Role == RoleAdmin
+}
+
+func (a *AssessmentID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(a), data)
+}
+
+//nolint:wrapcheck
+func (a AssessmentID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(a).String())
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// UserEventVersion is the version of the user event contract produced to the users topic.
+	UserEventVersion = 1
+
+	UserEventCreated  = "user.created"
+	UserEventDeleted  = "user.deleted"
+	UserEventRestored = "user.restored"
+
+	currencyCodeLength = 3
+)
+
+var (
+	ErrUserDeleted             = errors.New("user is deleted")
+	ErrWalletFrozen            = errors.New("wallet is frozen")
+	ErrInvalidUserEvent        = errors.New("invalid user event")
+	ErrUnsupportedEventVersion = errors.New("unsupported user event version")
+	ErrInvalidPreferences      = errors.New("invalid user preferences")
+	errMissingUserID           = errors.New("missing user ID")
+)
+
+// UserEvent is a change in the lifecycle of a user, published to the users topic. Events of a
+// user are keyed by the user ID, so that they stay in order, and an event older than the last
+// one applied to the user is ignored.
+type UserEvent struct {
+	Version    int          `json:"version"`
+	Type       string       `json:"type"`
+	UserID     UserID       `json:"userId"`
+	OccurredAt time.Time    `json:"occurredAt"`
+	Profile    *UserProfile `json:"profile,omitempty"`
+}
+
+type UserProfile struct {
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Gender    string `json:"gender,omitempty"`
+	Age       int    `json:"age,omitempty"`
+}
+
+// UserPreferences are set by the user. Currency is the one amounts are shown in by default and
+// Timezone an IANA time zone name.
+type UserPreferences struct {
+	Currency string `json:"currency,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// UserAccount is what the service knows of a user: the profile from the users topic, how many
+// wallets the user owns and the preferences the user has set.
+type UserAccount struct {
+	UserID      UserID          `json:"userId"`
+	Profile     *UserProfile    `json:"profile,omitempty"`
+	WalletCount int             `json:"walletCount"`
+	Preferences UserPreferences `json:"preferences"`
+	DeletedAt   *time.Time      `json:"deletedAt,omitempty"`
+}
+
+// UserSearch finds users for admins. Names are stored encrypted, so they match exactly, ignoring
+// case, rather than by prefix.
+type UserSearch struct {
+	UserID    *UserID
+	FirstName string
+	LastName  string
+	Deleted   *bool
+	Limit     int
+	Offset    int
+}
+
+// Counterparty is the owner of the other wallet of a transfer.
+type Counterparty struct {
+	UserID      UserID `json:"userId"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// legacyUser is the unversioned message published before the event contract, in the form of
+// both models.User and the user generator, which spelled the ID userid and flagged deletions.
+type legacyUser struct {
+	UserProfile
+
+	UserID    *UserID    `json:"userId"`
+	DeletedAt *time.Time `json:"deletedAt"`
+	Deleted   bool       `json:"deleted"`
+}
+
+// ParseUserEvent decodes a message of the users topic. Unversioned messages carry the state of
+// the user rather than a change, so one that is not deleted restores the user.
+func ParseUserEvent(data []byte, receivedAt time.Time) (UserEvent, error) {
+	var envelope struct {
+		Version int `json:"version"`
+	}
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidUserEvent, err)
+	}
+
+	switch envelope.Version {
+	case 0:
+		return parseLegacyUser(data, receivedAt)
+	case UserEventVersion:
+	default:
+		return UserEvent{}, fmt.Errorf("%w: %d", ErrUnsupportedEventVersion, envelope.Version)
+	}
+
+	var event UserEvent
+
+	if err := json.Unmarshal(data, &event); err != nil {
+		return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidUserEvent, err)
+	}
+
+	switch event.Type {
+	case UserEventCreated, UserEventDeleted, UserEventRestored:
+	default:
+		return UserEvent{}, fmt.Errorf("%w: unknown type %q", ErrInvalidUserEvent, event.Type)
+	}
+
+	if event.UserID == (UserID{}) {
+		return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidUserEvent, errMissingUserID)
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = receivedAt
+	}
+
+	return event, nil
+}
+
+func parseLegacyUser(data []byte, receivedAt time.Time) (UserEvent, error) {
+	var user legacyUser
+
+	if err := json.Unmarshal(data, &user); err != nil {
+		return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidUserEvent, err)
+	}
+
+	if user.UserID == nil || *user.UserID == (UserID{}) {
+		return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidUserEvent, errMissingUserID)
+	}
+
+	event := UserEvent{
+		Version:    0,
+		Type:       UserEventRestored,
+		UserID:     *user.UserID,
+		OccurredAt: receivedAt,
+	}
+
+	if user.UserProfile != (UserProfile{}) {
+		event.Profile = &user.UserProfile
+	}
+
+	if user.Deleted || user.DeletedAt != nil {
+		event.Type = UserEventDeleted
+
+		if user.DeletedAt != nil {
+			event.OccurredAt = *user.DeletedAt
+		}
+	}
+
+	return event, nil
+}
+
+// DisplayName is the first name and the initial of the last name, which is enough for other
+// users to recognize whom they transferred money to without showing the full name.
+func (p *UserProfile) DisplayName() string {
+	lastName := []rune(strings.TrimSpace(p.LastName))
+
+	if len(lastName) == 0 {
+		return strings.TrimSpace(p.FirstName)
+	}
+
+	return strings.TrimSpace(strings.TrimSpace(p.FirstName) + " " + string(lastName[0]) + ".")
+}
+
+func (p *UserPreferences) Validate() error {
+	p.Currency = strings.ToUpper(strings.TrimSpace(p.Currency))
+
+	if p.Currency != "" && len(p.Currency) != currencyCodeLength {
+		return fieldError("currency", ErrInvalidPreferences)
+	}
+
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return fieldError("timezone", ErrInvalidPreferences)
+		}
+	}
+
+	return nil
+}
+
+func (u *UserInfo) CanSearchUsers() bool {
+	return u.Role == RoleAdmin
+}
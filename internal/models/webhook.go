@@ -0,0 +1,175 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	WebhookID  uuid.UUID
+	DeliveryID uuid.UUID
+)
+
+const (
+	WebhookEventDeposit       = "transaction.deposit"
+	WebhookEventWithdraw      = "transaction.withdraw"
+	WebhookEventTransfer      = "transaction.transfer"
+	WebhookEventWalletCreated = "wallet.created"
+	WebhookEventWalletUpdated = "wallet.updated"
+	WebhookEventWalletDeleted = "wallet.deleted"
+
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+//nolint:gochecknoglobals
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+//nolint:gochecknoglobals
+var webhookEvents = []string{
+	WebhookEventDeposit,
+	WebhookEventWithdraw,
+	WebhookEventTransfer,
+	WebhookEventWalletCreated,
+	WebhookEventWalletUpdated,
+	WebhookEventWalletDeleted,
+}
+
+// Webhook subscribes a URL to events of the user. The secret signs the payloads and is
+// returned only when the webhook is created.
+type Webhook struct {
+	WebhookID  WebhookID `json:"webhookId"`
+	UserID     UserID    `json:"userId"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// WebhookEvent is the envelope posted to every webhook subscribed to the event type. It is
+// delivered to the webhooks of the acting user and of every member of the affected wallets.
+type WebhookEvent struct {
+	EventID   uuid.UUID       `json:"eventId"`
+	Type      string          `json:"type"`
+	UserID    UserID          `json:"userId"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+	WalletIDs []WalletID      `json:"-"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID    DeliveryID        `json:"deliveryId"`
+	WebhookID     WebhookID         `json:"webhookId"`
+	EventType     string            `json:"eventType"`
+	Payload       json.RawMessage   `json:"payload"`
+	Status        string            `json:"status"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt *time.Time        `json:"nextAttemptAt,omitempty"`
+	LastError     *string           `json:"lastError,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+	History       []DeliveryAttempt `json:"history,omitempty"`
+}
+
+type DeliveryAttempt struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  *int      `json:"statusCode,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+// WebhookDispatch is a due delivery claimed by the delivery worker, with the target of its webhook.
+type WebhookDispatch struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+func (w *Webhook) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return ErrInvalidWebhook
+	}
+
+	slices.Sort(w.EventTypes)
+	w.EventTypes = slices.Compact(w.EventTypes)
+
+	if len(w.EventTypes) == 0 {
+		return ErrInvalidWebhook
+	}
+
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(webhookEvents, eventType) {
+			return ErrInvalidWebhook
+		}
+	}
+
+	return nil
+}
+
+// PublicHost reports whether the URL may point outside the service's network: its host is neither
+// localhost nor an internal IP literal. Host names are checked again once resolved, on delivery.
+func (w *Webhook) PublicHost() bool {
+	target, err := url.Parse(w.URL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(target.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(host)
+
+	return err != nil || PublicAddress(addr)
+}
+
+// PublicAddress reports whether webhooks may be delivered to the address. Loopback, private,
+// link-local, multicast, unspecified and carrier-grade NAT addresses reach the service's own network.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+func (w *WebhookID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(w), data)
+}
+
+//nolint:wrapcheck
+func (w WebhookID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(w).String())
+}
+
+func (d *DeliveryID) UnmarshalText(data []byte) error {
+	return unmarshalUUID((*uuid.UUID)(d), data)
+}
+
+//nolint:wrapcheck
+func (d DeliveryID) MarshalText() ([]byte, error) {
+	return json.Marshal(uuid.UUID(d).String())
+}
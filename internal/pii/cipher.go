@@ -0,0 +1,99 @@
+// Package pii encrypts personal data stored in the database field by field.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const keySize = 32
+
+var (
+	ErrInvalidKey         = errors.New("encryption key must be 32 bytes encoded in base64")
+	errCiphertextTooShort = errors.New("ciphertext is shorter than its nonce")
+)
+
+// Cipher encrypts values with AES-256-GCM under a random nonce, so equal values have different
+// ciphertexts. Values that must be searched for also get a blind index: a keyed hash of the
+// normalized value, which matches equal values without revealing them.
+type Cipher struct {
+	aead     cipher.AEAD
+	indexKey []byte
+}
+
+// New creates a cipher from a base64 encoded 32 byte key. The index key is derived from it, so a
+// single secret is configured.
+func New(encodedKey string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != keySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("blind-index"))
+
+	return &Cipher{aead: aead, indexKey: mac.Sum(nil)}, nil
+}
+
+// Encrypt returns the nonce followed by the sealed value. Empty values are stored as NULL.
+func (c *Cipher) Encrypt(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, []byte(value), nil), nil
+}
+
+func (c *Cipher) Decrypt(ciphertext []byte) (string, error) {
+	if len(ciphertext) == 0 {
+		return "", nil
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", errCiphertextTooShort
+	}
+
+	value, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	return string(value), nil
+}
+
+// Index returns the blind index of the value, ignoring case and surrounding spaces.
+func (c *Cipher) Index(value string) []byte {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return nil
+	}
+
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,94 @@
+//nolint:testpackage
+package pii
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, 32)
+
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(newKey(t))
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		first, err := c.Encrypt("Ann")
+		require.NoError(t, err)
+
+		second, err := c.Encrypt("Ann")
+		require.NoError(t, err)
+
+		require.False(t, bytes.Equal(first, second))
+		require.NotContains(t, string(first), "Ann")
+
+		value, err := c.Decrypt(first)
+		require.NoError(t, err)
+		require.Equal(t, "Ann", value)
+	})
+
+	t.Run("empty values are not stored", func(t *testing.T) {
+		t.Parallel()
+
+		ciphertext, err := c.Encrypt("")
+		require.NoError(t, err)
+		require.Nil(t, ciphertext)
+
+		value, err := c.Decrypt(nil)
+		require.NoError(t, err)
+		require.Empty(t, value)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		t.Parallel()
+
+		ciphertext, err := c.Encrypt("Ann")
+		require.NoError(t, err)
+
+		ciphertext[len(ciphertext)-1] ^= 1
+
+		_, err = c.Decrypt(ciphertext)
+		require.Error(t, err)
+
+		_, err = c.Decrypt(ciphertext[:4])
+		require.Error(t, err)
+	})
+
+	t.Run("index ignores case and spaces", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, c.Index("Ann"), c.Index(" ann "))
+		require.NotEqual(t, c.Index("Ann"), c.Index("Anna"))
+		require.Nil(t, c.Index(" "))
+
+		other, err := New(newKey(t))
+		require.NoError(t, err)
+		require.NotEqual(t, c.Index("Ann"), other.Index("Ann"))
+	})
+}
+
+func TestInvalidKey(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range []string{"", "not base64", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		_, err := New(key)
+		require.ErrorIs(t, err, ErrInvalidKey)
+	}
+}
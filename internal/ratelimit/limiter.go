@@ -0,0 +1,171 @@
+// Package ratelimit limits the requests of each user with token buckets kept in memory or,
+// shared between replicas, in Postgres.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPeriod        = time.Minute
+	defaultRefreshPeriod = time.Minute
+)
+
+// Backend keeps the token buckets. The store implements it on top of Postgres.
+type Backend interface {
+	TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitBucket, error)
+	PurgeRateLimitBuckets(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+type overrideStore interface {
+	GetRateLimitOverrides(ctx context.Context) ([]models.RateLimitOverride, error)
+}
+
+type Config struct {
+	// Limits maps budgets to the requests allowed per Period. Missing budgets are not limited.
+	Limits map[string]int
+	Period time.Duration
+	// RefreshPeriod is how often the overrides are reloaded and idle buckets purged.
+	RefreshPeriod time.Duration
+}
+
+type Limiter struct {
+	cfg       Config
+	backend   Backend
+	overrides overrideStore
+
+	mu         sync.RWMutex
+	userLimits map[models.UserID]map[string]int
+}
+
+// New creates a limiter. Without an override store every user gets the configured limits.
+func New(ctx context.Context, cfg Config, backend Backend, overrides overrideStore) *Limiter {
+	if cfg.Period <= 0 {
+		cfg.Period = defaultPeriod
+	}
+
+	if cfg.RefreshPeriod <= 0 {
+		cfg.RefreshPeriod = defaultRefreshPeriod
+	}
+
+	l := &Limiter{
+		cfg:        cfg,
+		backend:    backend,
+		overrides:  overrides,
+		userLimits: make(map[models.UserID]map[string]int),
+	}
+
+	if err := l.reloadOverrides(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to load rate limit overrides")
+	}
+
+	return l
+}
+
+// Run reloads the overrides and purges idle buckets every refresh period. Failures keep the
+// previous overrides, since the limits must not go away when the database does.
+func (l *Limiter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.cfg.RefreshPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := l.reloadOverrides(ctx); err != nil {
+				log.Warn().Err(err).Msg("failed to reload rate limit overrides")
+			}
+
+			// A bucket left alone for a period is full, which is how missing buckets start.
+			if _, err := l.backend.PurgeRateLimitBuckets(ctx, l.cfg.Period); err != nil {
+				log.Warn().Err(err).Msg("failed to purge rate limit buckets")
+			}
+		}
+	}
+}
+
+func (l *Limiter) reloadOverrides(ctx context.Context) error {
+	if l.overrides == nil {
+		return nil
+	}
+
+	overrides, err := l.overrides.GetRateLimitOverrides(ctx)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	userLimits := make(map[models.UserID]map[string]int)
+
+	for _, override := range overrides {
+		if userLimits[override.UserID] == nil {
+			userLimits[override.UserID] = make(map[string]int)
+		}
+
+		userLimits[override.UserID][override.Budget] = override.Requests
+	}
+
+	l.mu.Lock()
+	l.userLimits = userLimits
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Limit returns the limit of the user for the budget, taking overrides into account.
+func (l *Limiter) Limit(userID models.UserID, budget string) models.RateLimit {
+	l.mu.RLock()
+	requests, ok := l.userLimits[userID][budget]
+	l.mu.RUnlock()
+
+	if !ok {
+		requests = l.cfg.Limits[budget]
+	}
+
+	return models.RateLimit{Requests: requests, Period: l.cfg.Period}
+}
+
+// Allow takes a token from the user's bucket for the budget. When the backend fails the
+// request is allowed and the error returned, so that callers can log it.
+func (l *Limiter) Allow(ctx context.Context, userID models.UserID, budget string) (models.RateLimitResult, error) {
+	limit := l.Limit(userID, budget)
+	if limit.Requests <= 0 {
+		return models.RateLimitResult{Allowed: true}, nil
+	}
+
+	bucket, err := l.backend.TakeRateLimitToken(ctx, uuid.UUID(userID).String()+":"+budget, limit)
+	if err != nil {
+		return models.RateLimitResult{Allowed: true}, err //nolint:wrapcheck
+	}
+
+	return newResult(bucket, limit), nil
+}
+
+func newResult(bucket models.RateLimitBucket, limit models.RateLimit) models.RateLimitResult {
+	perSecond := float64(limit.Requests) / limit.Period.Seconds()
+	tokens := math.Max(bucket.Tokens, 0)
+
+	result := models.RateLimitResult{
+		Allowed:   bucket.Allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Requests) - tokens) / perSecond),
+	}
+
+	if !bucket.Allowed {
+		result.RetryAfter = seconds((1 - tokens) / perSecond)
+	}
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
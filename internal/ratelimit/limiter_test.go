@@ -0,0 +1,119 @@
+//nolint:testpackage
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOverrides []models.RateLimitOverride
+
+func (f fakeOverrides) GetRateLimitOverrides(context.Context) ([]models.RateLimitOverride, error) {
+	return f, nil
+}
+
+func newTestLimiter(t *testing.T, overrides overrideStore) (*Limiter, *MemoryBackend, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	backend := NewMemoryBackend()
+	backend.now = func() time.Time { return now }
+
+	limiter := New(context.Background(), Config{
+		Limits: map[string]int{
+			models.RateLimitDefault: 60,
+			models.RateLimitMoney:   2,
+		},
+		Period: time.Minute,
+	}, backend, overrides)
+
+	return limiter, backend, &now
+}
+
+func TestAllow(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	limiter, _, now := newTestLimiter(t, nil)
+	userID := models.UserID(uuid.New())
+
+	result, err := limiter.Allow(ctx, userID, models.RateLimitMoney)
+	require.NoError(t, err)
+	require.Equal(t, models.RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 30 * time.Second}, result)
+
+	result, err = limiter.Allow(ctx, userID, models.RateLimitMoney)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	result, err = limiter.Allow(ctx, userID, models.RateLimitMoney)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, time.Minute, result.Reset)
+	require.Equal(t, 30*time.Second, result.RetryAfter)
+
+	result, err = limiter.Allow(ctx, userID, models.RateLimitDefault)
+	require.NoError(t, err)
+	require.True(t, result.Allowed, "budgets are separate")
+
+	result, err = limiter.Allow(ctx, models.UserID(uuid.New()), models.RateLimitMoney)
+	require.NoError(t, err)
+	require.True(t, result.Allowed, "users are separate")
+
+	*now = now.Add(30 * time.Second)
+
+	result, err = limiter.Allow(ctx, userID, models.RateLimitMoney)
+	require.NoError(t, err)
+	require.True(t, result.Allowed, "buckets refill over the period")
+	require.Equal(t, 0, result.Remaining)
+
+	result, err = limiter.Allow(ctx, userID, "unknown")
+	require.NoError(t, err)
+	require.Equal(t, models.RateLimitResult{Allowed: true}, result, "budgets without a limit are not limited")
+}
+
+func TestOverrides(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	raised, unlimited := models.UserID(uuid.New()), models.UserID(uuid.New())
+
+	limiter, _, _ := newTestLimiter(t, fakeOverrides{
+		{UserID: raised, Budget: models.RateLimitMoney, Requests: 5},
+		{UserID: unlimited, Budget: models.RateLimitMoney, Requests: 0},
+	})
+
+	require.Equal(t, 5, limiter.Limit(raised, models.RateLimitMoney).Requests)
+	require.Equal(t, 60, limiter.Limit(raised, models.RateLimitDefault).Requests, "overrides are per budget")
+
+	for range 10 {
+		result, err := limiter.Allow(ctx, unlimited, models.RateLimitMoney)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+}
+
+func TestPurgeMemoryBuckets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	limiter, backend, now := newTestLimiter(t, nil)
+
+	_, err := limiter.Allow(ctx, models.UserID(uuid.New()), models.RateLimitMoney)
+	require.NoError(t, err)
+
+	purged, err := backend.PurgeRateLimitBuckets(ctx, time.Minute)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	*now = now.Add(2 * time.Minute)
+
+	purged, err = backend.PurgeRateLimitBuckets(ctx, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+}
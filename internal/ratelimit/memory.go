@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryBackend keeps the buckets of a single replica in memory.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// TakeRateLimitToken refills the bucket for the time passed since its last use and takes a
+// token if one is left. Missing buckets start full.
+func (m *MemoryBackend) TakeRateLimitToken(_ context.Context, key string, limit models.RateLimit) (models.RateLimitBucket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	capacity := float64(limit.Requests)
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: capacity, updatedAt: now}
+		m.buckets[key] = bucket
+	}
+
+	if elapsed := now.Sub(bucket.updatedAt); elapsed > 0 {
+		bucket.tokens = min(capacity, bucket.tokens+elapsed.Seconds()*capacity/limit.Period.Seconds())
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens < 1 {
+		return models.RateLimitBucket{Tokens: bucket.tokens}, nil
+	}
+
+	bucket.tokens--
+
+	return models.RateLimitBucket{Tokens: bucket.tokens, Allowed: true}, nil
+}
+
+func (m *MemoryBackend) PurgeRateLimitBuckets(_ context.Context, idle time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged int64
+
+	for key, bucket := range m.buckets {
+		if m.now().Sub(bucket.updatedAt) > idle {
+			delete(m.buckets, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The REST and gRPC servers count their rejections together, as a metric can only be registered once.
+//
+//nolint:gochecknoglobals
+var (
+	registerRejected sync.Once
+	rejected         *prometheus.CounterVec
+)
+
+// Rejected returns the counter of requests rejected by the limiter, labelled by budget.
+func Rejected() *prometheus.CounterVec {
+	registerRejected.Do(func() {
+		rejected = promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "wallets_service",
+				Subsystem: "server",
+				Name:      "rate_limited_total",
+				Help:      "Number of requests rejected by the rate limiter.",
+			},
+			[]string{"budget"})
+	})
+
+	return rejected
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Server) getAnalytics(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	request := models.AnalyticsRequest{
+		Granularity:       queryParams.Get("granularity"),
+		ReportingCurrency: queryParams.Get("currency"),
+	}
+
+	var err error
+
+	if request.From, err = parseTimeParam(queryParams, "from"); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	if request.To, err = parseTimeParam(queryParams, "to"); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	if walletIDStr := chi.URLParam(r, "walletId"); walletIDStr != "" {
+		walletID, err := uuid.Parse(walletIDStr)
+		if err != nil {
+			s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+			return
+		}
+
+		id := models.WalletID(walletID)
+		request.WalletID = &id
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	report, err := s.service.GetAnalytics(ctx, request, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Warn().Err(err).Msg("error while encoding analytics report")
+
+		return
+	}
+}
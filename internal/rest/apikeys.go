@@ -0,0 +1,210 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	apiKeyIDHeader        = "X-Api-Key-Id"
+	apiKeyTimestampHeader = "X-Api-Timestamp"
+	apiKeySignatureHeader = "X-Api-Signature"
+
+	maxSignedBodySize = 1 << 20
+)
+
+// authenticate sends requests carrying an API key through apiKeyAuth and all others through jwtAuth.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	withJWT := s.jwtAuth(next)
+	withAPIKey := s.apiKeyAuth(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyIDHeader) != "" {
+			withAPIKey.ServeHTTP(w, r)
+
+			return
+		}
+
+		withJWT.ServeHTTP(w, r)
+	})
+}
+
+// apiKeyAuth verifies HMAC signed requests of service accounts and stores the identity of the
+// key owner in the context, like jwtAuth does for users.
+//
+//nolint:funlen
+func (s *Server) apiKeyAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID, err := uuid.Parse(r.Header.Get(apiKeyIDHeader))
+		if err != nil {
+			s.errorResponse(w, r, models.ErrInvalidSignature)
+
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(apiKeyTimestampHeader), 10, 64)
+		if err != nil {
+			s.errorResponse(w, r, models.ErrInvalidSignature)
+
+			return
+		}
+
+		var body []byte
+
+		if r.Body != nil {
+			if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodySize)); err != nil {
+				s.errorResponse(w, r, errBodyTooLarge)
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		ctx := r.Context()
+
+		userInfo, err := s.service.AuthenticateAPIKey(ctx, models.SignedRequest{
+			KeyID:     models.APIKeyID(keyID),
+			Timestamp: time.Unix(timestamp, 0),
+			Signature: strings.ToLower(r.Header.Get(apiKeySignatureHeader)),
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			Body:      body,
+		})
+
+		if err != nil {
+			s.errorResponse(w, r, err)
+
+			return
+		}
+
+		r = r.WithContext(models.ContextWithUserInfo(ctx, userInfo))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// userOnly rejects service accounts, so that API keys cannot manage API keys or act on the
+// account of the user. Scoped tokens are limited by the scope each route requires.
+func (s *Server) userOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.getUserInfo(r.Context()).APIKeyID != nil {
+			s.errorResponse(w, r, withDetail(models.ErrForbidden, "not available to api keys"))
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var key models.APIKey
+
+	if err := decodeBody(r, &key); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	key.KeyID = models.APIKeyID(uuid.New())
+
+	credentials, err := s.service.CreateAPIKey(ctx, key, userInfo)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(credentials); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	keys, err := s.service.GetAPIKeys(ctx, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(keys); err != nil {
+		log.Warn().Err(err).Msg("error while encoding api keys")
+
+		return
+	}
+}
+
+func (s *Server) rotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("keyId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	credentials, err := s.service.RotateAPIKey(ctx, models.APIKeyID(keyID), userInfo)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(credentials); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("keyId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	err = s.service.RevokeAPIKey(ctx, models.APIKeyID(keyID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,366 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxAuditBodySize = 64 << 10
+	redactedValue    = "[REDACTED]"
+)
+
+//nolint:gochecknoglobals
+var sensitiveFields = map[string]struct{}{
+	"password":      {},
+	"secret":        {},
+	"token":         {},
+	"accesstoken":   {},
+	"refreshtoken":  {},
+	"apikey":        {},
+	"authorization": {},
+	"cardnumber":    {},
+	"cvv":           {},
+	"pin":           {},
+}
+
+func (s *Server) auditTrack(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var body []byte
+
+		if isMutatingMethod(r.Method) && r.Body != nil {
+			body, r.Body = readAuditBody(r.Body)
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		userInfo := s.getUserInfo(r.Context())
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		record := models.AuditRecord{
+			RequestID:   middleware.GetReqID(r.Context()),
+			UserID:      userInfo.UserID,
+			Role:        userInfo.Role,
+			Method:      r.Method,
+			Route:       routePattern(r),
+			WalletIDs:   auditWalletIDs(r, body),
+			Status:      status,
+			LatencyMs:   time.Since(start).Milliseconds(),
+			RequestBody: redactBody(body),
+		}
+
+		//nolint:contextcheck
+		if err := s.service.RecordAudit(context.WithoutCancel(r.Context()), record); err != nil {
+			log.Error().Err(err).Str("requestId", record.RequestID).Msg("failed to write audit record")
+		}
+	})
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// readAuditBody reads up to maxAuditBodySize bytes of the body and returns a reader
+// that replays them in front of the remaining stream for the handler.
+func readAuditBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	data, err := io.ReadAll(io.LimitReader(body, maxAuditBodySize+1))
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body for audit")
+	}
+
+	replay := struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(data), body),
+		Closer: body,
+	}
+
+	if len(data) > maxAuditBodySize {
+		return []byte(`{"truncated":true}`), replay
+	}
+
+	return data, replay
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+
+	return r.URL.Path
+}
+
+func auditWalletIDs(r *http.Request, body []byte) []models.WalletID {
+	seen := make(map[models.WalletID]struct{})
+	ids := make([]models.WalletID, 0)
+
+	add := func(id *models.WalletID) {
+		if id == nil || *id == models.WalletID(uuid.Nil) {
+			return
+		}
+
+		if _, ok := seen[*id]; ok {
+			return
+		}
+
+		seen[*id] = struct{}{}
+		ids = append(ids, *id)
+	}
+
+	if walletID, err := uuid.Parse(chi.URLParam(r, "walletId")); err == nil {
+		id := models.WalletID(walletID)
+		add(&id)
+	}
+
+	var touched struct {
+		WalletID     *models.WalletID `json:"walletId"`
+		ToWalletID   *models.WalletID `json:"toWalletId"`
+		FromWalletID *models.WalletID `json:"fromWalletId"`
+	}
+
+	if len(body) > 0 && json.Unmarshal(body, &touched) == nil {
+		add(touched.WalletID)
+		add(touched.ToWalletID)
+		add(touched.FromWalletID)
+	}
+
+	return ids
+}
+
+func redactBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var payload any
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return json.RawMessage(`{"unparsable":true}`)
+	}
+
+	redacted, err := json.Marshal(redactValue(payload))
+	if err != nil {
+		return nil
+	}
+
+	return redacted
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+			if _, ok := sensitiveFields[normalized]; ok {
+				v[key] = redactedValue
+
+				continue
+			}
+
+			v[key] = redactValue(val)
+		}
+
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+func (s *Server) getAuditRecords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanAudit() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	request, err := parseAuditQuery(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	records, err := s.service.GetAuditRecords(ctx, request)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		log.Warn().Err(err).Msg("error while encoding audit records")
+
+		return
+	}
+}
+
+func (s *Server) exportAuditRecords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanAudit() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	request, err := parseAuditQuery(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	if r.URL.Query().Get("limit") == "" {
+		request.Limit = 0
+	}
+
+	records, err := s.service.GetAuditRecords(ctx, request)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit_log.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+
+	if err := writeAuditCSV(writer, records); err != nil {
+		log.Warn().Err(err).Msg("error while writing audit export")
+	}
+}
+
+func writeAuditCSV(writer *csv.Writer, records []models.AuditRecord) error {
+	header := []string{
+		"id", "created_at", "request_id", "user_id", "role", "method", "route",
+		"wallet_ids", "status", "latency_ms", "request_body",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for _, record := range records {
+		walletIDs := make([]string, 0, len(record.WalletIDs))
+		for _, id := range record.WalletIDs {
+			walletIDs = append(walletIDs, uuid.UUID(id).String())
+		}
+
+		if err := writer.Write([]string{
+			strconv.FormatInt(record.ID, 10),
+			record.CreatedAt.UTC().Format(time.RFC3339Nano),
+			record.RequestID,
+			uuid.UUID(record.UserID).String(),
+			record.Role,
+			record.Method,
+			record.Route,
+			strings.Join(walletIDs, ";"),
+			strconv.Itoa(record.Status),
+			strconv.FormatInt(record.LatencyMs, 10),
+			string(record.RequestBody),
+		}); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error() //nolint:wrapcheck
+}
+
+func parseAuditQuery(r *http.Request) (models.AuditQuery, error) {
+	queryParams := r.URL.Query()
+
+	request := models.AuditQuery{
+		Route:  queryParams.Get("route"),
+		Method: queryParams.Get("method"),
+		Limit:  DefaultLimit,
+	}
+
+	if u := queryParams.Get("userId"); u != "" {
+		userID, err := uuid.Parse(u)
+		if err != nil {
+			return models.AuditQuery{}, invalidField("userId", models.ErrInvalidUUIDFormat)
+		}
+
+		id := models.UserID(userID)
+		request.UserID = &id
+	}
+
+	if wID := queryParams.Get("walletId"); wID != "" {
+		walletID, err := uuid.Parse(wID)
+		if err != nil {
+			return models.AuditQuery{}, invalidField("walletId", models.ErrInvalidUUIDFormat)
+		}
+
+		id := models.WalletID(walletID)
+		request.WalletID = &id
+	}
+
+	for param, target := range map[string]**time.Time{"from": &request.From, "to": &request.To} {
+		if v := queryParams.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return models.AuditQuery{}, invalidField(param, errInvalidParameter)
+			}
+
+			*target = &t
+		}
+	}
+
+	if l := queryParams.Get("limit"); l != "" {
+		if limit, _ := strconv.Atoi(l); limit > 0 {
+			request.Limit = limit
+		}
+	}
+
+	if o := queryParams.Get("offset"); o != "" {
+		request.Offset, _ = strconv.Atoi(o)
+	}
+
+	return request, nil
+}
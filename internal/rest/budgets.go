@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Server) createBudget(w http.ResponseWriter, r *http.Request) {
+	var budget models.Budget
+
+	if err := decodeBody(r, &budget); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	budget.BudgetID = models.BudgetID(uuid.New())
+
+	created, err := s.service.CreateBudget(ctx, budget, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(created); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) getBudgets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	progress, err := s.service.GetBudgetsProgress(ctx, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(progress); err != nil {
+		log.Warn().Err(err).Msg("error while encoding budgets")
+
+		return
+	}
+}
+
+func (s *Server) getBudget(w http.ResponseWriter, r *http.Request) {
+	budgetID, err := uuid.Parse(chi.URLParam(r, "budgetId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("budgetId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	progress, err := s.service.GetBudgetProgress(ctx, models.BudgetID(budgetID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(progress); err != nil {
+		log.Warn().Err(err).Msg("error while encoding budget")
+
+		return
+	}
+}
+
+func (s *Server) deleteBudget(w http.ResponseWriter, r *http.Request) {
+	budgetID, err := uuid.Parse(chi.URLParam(r, "budgetId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("budgetId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	err = s.service.DeleteBudget(ctx, models.BudgetID(budgetID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
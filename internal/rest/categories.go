@@ -0,0 +1,270 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Server) createCategory(w http.ResponseWriter, r *http.Request) {
+	var category models.Category
+
+	if err := decodeBody(r, &category); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	category.CategoryID = models.CategoryID(uuid.New())
+
+	created, err := s.service.CreateCategory(ctx, category, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(created); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) getCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	categories, err := s.service.GetCategories(ctx, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(categories); err != nil {
+		log.Warn().Err(err).Msg("error while encoding categories")
+
+		return
+	}
+}
+
+func (s *Server) deleteCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := uuid.Parse(chi.URLParam(r, "categoryId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("categoryId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	err = s.service.DeleteCategory(ctx, models.CategoryID(categoryID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createCategoryRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.CategoryRule
+
+	if err := decodeBody(r, &rule); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	rule.RuleID = models.RuleID(uuid.New())
+
+	created, err := s.service.CreateCategoryRule(ctx, rule, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(created); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) getCategoryRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	rules, err := s.service.GetCategoryRules(ctx, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(rules); err != nil {
+		log.Warn().Err(err).Msg("error while encoding category rules")
+
+		return
+	}
+}
+
+func (s *Server) deleteCategoryRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("ruleId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	err = s.service.DeleteCategoryRule(ctx, models.RuleID(ruleID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) applyCategoryRules(w http.ResponseWriter, r *http.Request) {
+	force := false
+
+	if value := r.URL.Query().Get("force"); value != "" {
+		var err error
+
+		if force, err = strconv.ParseBool(value); err != nil {
+			s.errorResponse(w, r, invalidField("force", errInvalidParameter))
+
+			return
+		}
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	result, err := s.service.ApplyCategoryRules(ctx, userInfo.UserID, force)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) setTransactionCategory(w http.ResponseWriter, r *http.Request) {
+	walletID, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	txID, err := uuid.Parse(chi.URLParam(r, "transactionId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("transactionId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	var assignment models.CategoryAssignment
+
+	if err = decodeBody(r, &assignment); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	transaction, err := s.service.SetTransactionCategory(ctx, models.TxID(txID), models.WalletID(walletID),
+		assignment.CategoryID, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(transaction); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) getCategoryTotals(w http.ResponseWriter, r *http.Request) {
+	request, err := parseGetRequest(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	walletID, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	totals, err := s.service.GetCategoryTotals(ctx, request, models.WalletID(walletID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(totals); err != nil {
+		log.Warn().Err(err).Msg("error while encoding category totals")
+
+		return
+	}
+}
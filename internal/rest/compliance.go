@@ -0,0 +1,374 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	exportFormatCSV = "csv"
+	exportFormatXML = "xml"
+)
+
+func (s *Server) getComplianceCases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanInvestigate() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	status, err := parseCaseStatus(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	cases, err := s.service.GetComplianceCases(ctx, status)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(cases); err != nil {
+		log.Warn().Err(err).Msg("error while encoding compliance cases")
+
+		return
+	}
+}
+
+func (s *Server) getComplianceCase(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanInvestigate() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("caseId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	complianceCase, err := s.service.GetComplianceCase(ctx, models.CaseID(caseID))
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(complianceCase); err != nil {
+		log.Warn().Err(err).Msg("error while encoding compliance case")
+
+		return
+	}
+}
+
+func (s *Server) updateComplianceCase(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanInvestigate() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("caseId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	var update models.CaseUpdate
+
+	if err = decodeBody(r, &update); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	complianceCase, err := s.service.UpdateComplianceCase(ctx, models.CaseID(caseID), update)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(complianceCase); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) addComplianceCaseNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanInvestigate() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("caseId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	var note models.CaseNote
+
+	if err = decodeBody(r, &note); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	note.CaseID = models.CaseID(caseID)
+	note.AuthorID = userInfo.UserID
+
+	created, err := s.service.AddComplianceCaseNote(ctx, note)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(created); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+// exportComplianceCases writes the cases for filing with a regulator, as CSV with a row per
+// attached transaction or as an XML report.
+func (s *Server) exportComplianceCases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanInvestigate() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	status, err := parseCaseStatus(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatCSV
+	}
+
+	if format != exportFormatCSV && format != exportFormatXML {
+		s.errorResponse(w, r, invalidField("format", errInvalidParameter))
+
+		return
+	}
+
+	cases, err := s.service.GetComplianceCases(ctx, status)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	if format == exportFormatXML {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Disposition", `attachment; filename="compliance_cases.xml"`)
+		w.WriteHeader(http.StatusOK)
+
+		if err = writeCasesXML(w, cases, time.Now()); err != nil {
+			log.Warn().Err(err).Msg("error while writing compliance export")
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="compliance_cases.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	if err = writeCasesCSV(csv.NewWriter(w), cases); err != nil {
+		log.Warn().Err(err).Msg("error while writing compliance export")
+	}
+}
+
+func parseCaseStatus(r *http.Request) (string, error) {
+	status := r.URL.Query().Get("status")
+
+	if status != "" && !models.ValidCaseStatus(status) {
+		return "", invalidField("status", errInvalidParameter)
+	}
+
+	return status, nil
+}
+
+func writeCasesCSV(writer *csv.Writer, cases []models.ComplianceCase) error {
+	header := []string{
+		"case_id", "kind", "status", "subject_user_id", "case_currency", "case_amount", "opened_at",
+		"transaction_id", "transaction_type", "committed_at", "amount", "currency", "from_wallet_id", "to_wallet_id",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for _, complianceCase := range cases {
+		for _, t := range complianceCase.Transactions {
+			if err := writer.Write([]string{
+				uuid.UUID(complianceCase.CaseID).String(),
+				complianceCase.Kind,
+				complianceCase.Status,
+				uuid.UUID(complianceCase.UserID).String(),
+				complianceCase.Currency,
+				strconv.FormatFloat(complianceCase.Amount, 'f', -1, 64),
+				complianceCase.CreatedAt.UTC().Format(time.RFC3339),
+				uuid.UUID(t.ID).String(),
+				t.Type,
+				t.CommittedAt.UTC().Format(time.RFC3339Nano),
+				strconv.FormatFloat(t.Amount, 'f', -1, 64),
+				t.Currency,
+				walletIDString(t.FromWalletID),
+				walletIDString(t.ToWalletID),
+			}); err != nil {
+				return err //nolint:wrapcheck
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error() //nolint:wrapcheck
+}
+
+type xmlCaseReport struct {
+	XMLName     xml.Name  `xml:"ComplianceReport"`
+	GeneratedAt string    `xml:"generatedAt,attr"`
+	Cases       []xmlCase `xml:"Case"`
+}
+
+type xmlCase struct {
+	ID           string           `xml:"id,attr"`
+	Kind         string           `xml:"kind,attr"`
+	Status       string           `xml:"status,attr"`
+	OpenedAt     string           `xml:"OpenedAt"`
+	SubjectID    string           `xml:"Subject>UserId"`
+	Currency     string           `xml:"Total>Currency"`
+	Amount       string           `xml:"Total>Amount"`
+	Transactions []xmlTransaction `xml:"Transactions>Transaction"`
+	Notes        []xmlNote        `xml:"Notes>Note,omitempty"`
+}
+
+type xmlTransaction struct {
+	ID           string `xml:"id,attr"`
+	Type         string `xml:"type,attr"`
+	CommittedAt  string `xml:"CommittedAt"`
+	Amount       string `xml:"Amount"`
+	Currency     string `xml:"Currency"`
+	FromWalletID string `xml:"FromWalletId,omitempty"`
+	ToWalletID   string `xml:"ToWalletId,omitempty"`
+}
+
+type xmlNote struct {
+	AuthorID  string `xml:"authorId,attr"`
+	CreatedAt string `xml:"createdAt,attr"`
+	Text      string `xml:",chardata"`
+}
+
+func writeCasesXML(w io.Writer, cases []models.ComplianceCase, now time.Time) error {
+	report := xmlCaseReport{
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+		Cases:       make([]xmlCase, 0, len(cases)),
+	}
+
+	for _, complianceCase := range cases {
+		c := xmlCase{
+			ID:        uuid.UUID(complianceCase.CaseID).String(),
+			Kind:      complianceCase.Kind,
+			Status:    complianceCase.Status,
+			OpenedAt:  complianceCase.CreatedAt.UTC().Format(time.RFC3339),
+			SubjectID: uuid.UUID(complianceCase.UserID).String(),
+			Currency:  complianceCase.Currency,
+			Amount:    strconv.FormatFloat(complianceCase.Amount, 'f', -1, 64),
+		}
+
+		for _, t := range complianceCase.Transactions {
+			c.Transactions = append(c.Transactions, xmlTransaction{
+				ID:           uuid.UUID(t.ID).String(),
+				Type:         t.Type,
+				CommittedAt:  t.CommittedAt.UTC().Format(time.RFC3339Nano),
+				Amount:       strconv.FormatFloat(t.Amount, 'f', -1, 64),
+				Currency:     t.Currency,
+				FromWalletID: walletIDString(t.FromWalletID),
+				ToWalletID:   walletIDString(t.ToWalletID),
+			})
+		}
+
+		for _, note := range complianceCase.Notes {
+			c.Notes = append(c.Notes, xmlNote{
+				AuthorID:  uuid.UUID(note.AuthorID).String(),
+				CreatedAt: note.CreatedAt.UTC().Format(time.RFC3339),
+				Text:      note.Text,
+			})
+		}
+
+		report.Cases = append(report.Cases, c)
+	}
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return encoder.Encode(report) //nolint:wrapcheck
+}
+
+func walletIDString(walletID *models.WalletID) string {
+	if walletID == nil {
+		return ""
+	}
+
+	return uuid.UUID(*walletID).String()
+}
@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+// uncategorized selects transactions without a category in the category filter.
+const uncategorized = "none"
+
+//nolint:gochecknoglobals
+var (
+	currencyCodeRegexp   = regexp.MustCompile(`^[A-Z]{3}$`)
+	validTransactionType = map[string]struct{}{
+		"deposit":  {},
+		"withdraw": {},
+		"transfer": {},
+	}
+)
+
+// parseListFilters reads the typed list filters shared by the wallet and transaction listings.
+// Set-valued filters accept both repeated parameters and comma-separated values.
+func parseListFilters(queryParams url.Values, parameters *models.GetWalletsRequest) error {
+	var err error
+
+	if parameters.From, err = parseTimeParam(queryParams, "from"); err != nil {
+		return err
+	}
+
+	if parameters.To, err = parseTimeParam(queryParams, "to"); err != nil {
+		return err
+	}
+
+	for param, target := range map[string]**float64{
+		"minAmount":  &parameters.MinAmount,
+		"maxAmount":  &parameters.MaxAmount,
+		"minBalance": &parameters.MinBalance,
+		"maxBalance": &parameters.MaxBalance,
+	} {
+		if *target, err = parseFloatParam(queryParams, param); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range splitListParam(queryParams, "type") {
+		t = strings.ToLower(t)
+		if _, ok := validTransactionType[t]; !ok {
+			return filterError("type", fmt.Sprintf("unknown transaction type %q", t))
+		}
+
+		parameters.Types = append(parameters.Types, t)
+	}
+
+	for _, c := range splitListParam(queryParams, "currency") {
+		c = strings.ToUpper(c)
+		if !currencyCodeRegexp.MatchString(c) {
+			return filterError("currency", fmt.Sprintf("invalid currency %q", c))
+		}
+
+		parameters.Currencies = append(parameters.Currencies, c)
+	}
+
+	if c := queryParams.Get("counterpartyWalletId"); c != "" {
+		counterparty, err := uuid.Parse(c)
+		if err != nil {
+			return filterError("counterpartyWalletId", "invalid wallet id")
+		}
+
+		id := models.WalletID(counterparty)
+		parameters.Counterparty = &id
+	}
+
+	for _, c := range splitListParam(queryParams, "category") {
+		if strings.EqualFold(c, uncategorized) {
+			parameters.Uncategorized = true
+
+			continue
+		}
+
+		categoryID, err := uuid.Parse(c)
+		if err != nil {
+			return filterError("category", fmt.Sprintf("invalid category %q", c))
+		}
+
+		parameters.Categories = append(parameters.Categories, models.CategoryID(categoryID))
+	}
+
+	if ref := queryParams.Get("externalReference"); ref != "" {
+		parameters.ExternalReference = &ref
+	}
+
+	for _, pair := range queryParams["metadata"] {
+		key, value, ok := strings.Cut(pair, ":")
+		if !ok || key == "" {
+			return filterError("metadata", "must be key:value")
+		}
+
+		if parameters.Metadata == nil {
+			parameters.Metadata = make(map[string]string)
+		}
+
+		parameters.Metadata[key] = value
+	}
+
+	if parameters.From != nil && parameters.To != nil && !parameters.From.Before(*parameters.To) {
+		return filterError("from", "must be before to")
+	}
+
+	return nil
+}
+
+func parseTimeParam(queryParams url.Values, name string) (*time.Time, error) {
+	value := queryParams.Get(name)
+	if value == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, filterError(name, "must be an RFC 3339 timestamp")
+	}
+
+	return &t, nil
+}
+
+func parseFloatParam(queryParams url.Values, name string) (*float64, error) {
+	value := queryParams.Get(name)
+	if value == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, filterError(name, "must be a number")
+	}
+
+	return &f, nil
+}
+
+func splitListParam(queryParams url.Values, name string) []string {
+	var values []string
+
+	for _, raw := range queryParams[name] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
+func filterError(field, message string) error {
+	return &models.ValidationError{Field: field, Message: message, Err: models.ErrInvalidFilter}
+}
@@ -3,8 +3,6 @@ package rest
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -23,18 +21,64 @@ type service interface {
 	GetWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) (models.Wallet, error)
 	UpdateWallet(ctx context.Context, walletID models.WalletID, updatedWallet models.WalletUpdate, userID models.UserID) (models.Wallet, error)
 	DeleteWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) error
-	GetAllWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) ([]models.Wallet, error)
+	GetAllWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) (models.WalletsPage, error)
 	Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID) error
 	Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID) error
 	Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID) error
-	GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.Transaction, error)
+	GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) (models.TransactionsPage, error)
+	RecordAudit(ctx context.Context, record models.AuditRecord) error
+	GetAuditRecords(ctx context.Context, request models.AuditQuery) ([]models.AuditRecord, error)
+	GetAnalytics(ctx context.Context, request models.AnalyticsRequest, userID models.UserID) (models.AnalyticsReport, error)
+	GetPortfolioSummary(ctx context.Context, currency string, userID models.UserID) (models.PortfolioSummary, error)
+	CreateCategory(ctx context.Context, category models.Category, userID models.UserID) (models.Category, error)
+	GetCategories(ctx context.Context, userID models.UserID) ([]models.Category, error)
+	DeleteCategory(ctx context.Context, categoryID models.CategoryID, userID models.UserID) error
+	CreateCategoryRule(ctx context.Context, rule models.CategoryRule, userID models.UserID) (models.CategoryRule, error)
+	GetCategoryRules(ctx context.Context, userID models.UserID) ([]models.CategoryRule, error)
+	DeleteCategoryRule(ctx context.Context, ruleID models.RuleID, userID models.UserID) error
+	SetTransactionCategory(ctx context.Context, txID models.TxID, walletID models.WalletID, categoryID *models.CategoryID, userID models.UserID) (models.Transaction, error)
+	ApplyCategoryRules(ctx context.Context, userID models.UserID, force bool) (models.ApplyRulesResult, error)
+	GetCategoryTotals(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.CategoryTotal, error)
+	CreateBudget(ctx context.Context, budget models.Budget, userID models.UserID) (models.Budget, error)
+	GetBudgetsProgress(ctx context.Context, userID models.UserID) ([]models.BudgetProgress, error)
+	GetBudgetProgress(ctx context.Context, budgetID models.BudgetID, userID models.UserID) (models.BudgetProgress, error)
+	DeleteBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) error
+	GetWalletMembers(ctx context.Context, walletID models.WalletID, userID models.UserID) ([]models.WalletMember, error)
+	AddWalletMember(ctx context.Context, member models.WalletMember, userID models.UserID) (models.WalletMember, error)
+	UpdateWalletMember(ctx context.Context, member models.WalletMember, userID models.UserID) (models.WalletMember, error)
+	RemoveWalletMember(ctx context.Context, walletID models.WalletID, memberID models.UserID, userID models.UserID) error
+	CreateAPIKey(ctx context.Context, key models.APIKey, userInfo models.UserInfo) (models.APIKeyCredentials, error)
+	GetAPIKeys(ctx context.Context, userID models.UserID) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, keyID models.APIKeyID, userInfo models.UserInfo) (models.APIKeyCredentials, error)
+	RevokeAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) error
+	AuthenticateAPIKey(ctx context.Context, request models.SignedRequest) (models.UserInfo, error)
+	CreateWebhook(ctx context.Context, webhook models.Webhook, userID models.UserID) (models.Webhook, error)
+	GetWebhooks(ctx context.Context, userID models.UserID) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID models.WebhookID, userID models.UserID) error
+	GetWebhookDeliveries(ctx context.Context, webhookID models.WebhookID, userID models.UserID, status string) ([]models.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error)
+	StreamNotifications(ctx context.Context, userID models.UserID, lastEventID int64, replay func(models.Notification) error) (<-chan models.Notification, func(), error)
+	GetComplianceCases(ctx context.Context, status string) ([]models.ComplianceCase, error)
+	GetComplianceCase(ctx context.Context, caseID models.CaseID) (models.ComplianceCase, error)
+	UpdateComplianceCase(ctx context.Context, caseID models.CaseID, update models.CaseUpdate) (models.ComplianceCase, error)
+	AddComplianceCaseNote(ctx context.Context, note models.CaseNote) (models.CaseNote, error)
+	GetRiskRules(ctx context.Context) ([]models.RiskRule, error)
+	SaveRiskRule(ctx context.Context, rule models.RiskRule) (models.RiskRule, error)
+	DeleteRiskRule(ctx context.Context, name string) error
+	GetRiskReviews(ctx context.Context, status string) ([]models.RiskAssessment, error)
+	ApproveRiskReview(ctx context.Context, assessmentID models.AssessmentID, reviewerID models.UserID) (models.RiskAssessment, error)
+	DeclineRiskReview(ctx context.Context, assessmentID models.AssessmentID, reviewerID models.UserID) (models.RiskAssessment, error)
+	GetUserAccount(ctx context.Context, userID models.UserID) (models.UserAccount, error)
+	UpdateUserPreferences(ctx context.Context, userID models.UserID, preferences models.UserPreferences) (models.UserAccount, error)
+	SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserAccount, error)
 }
 
 func (s *Server) createWallet(w http.ResponseWriter, r *http.Request) {
 	var wallet models.Wallet
 
-	if err := json.NewDecoder(r.Body).Decode(&wallet); err != nil {
-		http.Error(w, fmt.Sprintf("%d: %s", http.StatusBadRequest, err), http.StatusBadRequest)
+	if err := decodeBody(r, &wallet); err != nil {
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -44,21 +88,21 @@ func (s *Server) createWallet(w http.ResponseWriter, r *http.Request) {
 
 	err := wallet.Validate()
 	if err != nil {
-		http.Error(w, "wallet validation error", http.StatusBadRequest)
+		s.errorResponse(w, r, err)
 
 		return
 	}
 
 	err = userInfo.Validate(wallet.UserID)
 	if err != nil {
-		http.Error(w, "user validation error", http.StatusNotFound)
+		s.errorResponse(w, r, err)
 
 		return
 	}
 
 	createdWallet, err := s.service.CreateWallet(ctx, wallet, userInfo.UserID)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -78,7 +122,7 @@ func (s *Server) getWallet(w http.ResponseWriter, r *http.Request) {
 
 	walletID, err := uuid.Parse(walletIDStr)
 	if err != nil {
-		http.Error(w, "invalid wallet id", http.StatusBadRequest)
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
 
 		return
 	}
@@ -87,26 +131,20 @@ func (s *Server) getWallet(w http.ResponseWriter, r *http.Request) {
 	userInfo := s.getUserInfo(ctx)
 
 	if walletID == uuid.Nil {
-		http.Error(w, "walletID empty", http.StatusBadRequest)
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
 
 		return
 	}
 
 	if userInfo.UserID == models.UserID(uuid.Nil) {
-		http.Error(w, "userID empty", http.StatusBadRequest)
+		s.errorResponse(w, r, models.ErrInvalidToken)
 
 		return
 	}
 
 	wallet, err := s.service.GetWallet(ctx, models.WalletID(walletID), userInfo.UserID)
 	if err != nil {
-		if errors.Is(err, models.ErrWalletNotFound) {
-			http.Error(w, "wallet not found", http.StatusNotFound)
-
-			return
-		}
-
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -126,7 +164,7 @@ func (s *Server) updateWallet(w http.ResponseWriter, r *http.Request) {
 
 	walletID, err := uuid.Parse(walletIDStr)
 	if err != nil {
-		http.Error(w, "invalid wallet id", http.StatusBadRequest)
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
 
 		return
 	}
@@ -136,25 +174,15 @@ func (s *Server) updateWallet(w http.ResponseWriter, r *http.Request) {
 
 	var updatedDecodedWallet models.WalletUpdate
 
-	if err := json.NewDecoder(r.Body).Decode(&updatedDecodedWallet); err != nil {
-		http.Error(w, "error decoding json when updating wallet", http.StatusBadRequest)
+	if err := decodeBody(r, &updatedDecodedWallet); err != nil {
+		s.errorResponse(w, r, err)
 
 		return
 	}
 
 	updatedWallet, err := s.service.UpdateWallet(ctx, models.WalletID(walletID), updatedDecodedWallet, userInfo.UserID)
-
-	switch {
-	case errors.Is(err, models.ErrWalletNotFound):
-		http.Error(w, "error wallet not found", http.StatusNotFound)
-
-		return
-	case errors.Is(err, models.ErrWrongCurrency):
-		http.Error(w, "error wrong currency", http.StatusUnprocessableEntity)
-
-		return
-	case err != nil:
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	if err != nil {
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -174,7 +202,7 @@ func (s *Server) deleteWallet(w http.ResponseWriter, r *http.Request) {
 
 	walletID, err := uuid.Parse(walletIDStr)
 	if err != nil {
-		http.Error(w, "invalid wallet id", http.StatusBadRequest)
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
 
 		return
 	}
@@ -183,18 +211,8 @@ func (s *Server) deleteWallet(w http.ResponseWriter, r *http.Request) {
 	userInfo := s.getUserInfo(ctx)
 
 	err = s.service.DeleteWallet(ctx, models.WalletID(walletID), userInfo.UserID)
-
-	switch {
-	case errors.Is(err, models.ErrWalletNotFound):
-		http.Error(w, "wallet not found", http.StatusNotFound)
-
-		return
-	case errors.Is(err, models.ErrNonZeroBalanceWallet):
-		http.Error(w, "wallet has non-zero balance, deletion forbidden", http.StatusBadRequest)
-
-		return
-	case err != nil:
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	if err != nil {
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -204,13 +222,19 @@ func (s *Server) deleteWallet(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) getWallets(w http.ResponseWriter, r *http.Request) {
-	request := parseGetRequest(r)
+	request, err := parseGetRequest(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
 	ctx := r.Context()
 	userInfo := s.getUserInfo(ctx)
 
 	wallets, err := s.service.GetAllWallets(ctx, request, userInfo.UserID)
 	if err != nil {
-		http.Error(w, "failed to obtain wallets", http.StatusNotFound)
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -225,7 +249,35 @@ func (s *Server) getWallets(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func parseGetRequest(r *http.Request) models.GetWalletsRequest {
+func (s *Server) getPortfolioSummary(w http.ResponseWriter, r *http.Request) {
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		s.errorResponse(w, r, invalidField("currency", errMissingParameter))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	summary, err := s.service.GetPortfolioSummary(ctx, currency, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		log.Warn().Err(err).Msg("error while encoding portfolio summary")
+
+		return
+	}
+}
+
+func parseGetRequest(r *http.Request) (models.GetWalletsRequest, error) {
 	queryParams := r.URL.Query()
 
 	parameters := models.GetWalletsRequest{
@@ -243,7 +295,7 @@ func parseGetRequest(r *http.Request) models.GetWalletsRequest {
 	}
 
 	if l := queryParams.Get("limit"); l != "" {
-		if limit, _ = strconv.ParseInt(l, 0, 64); limit == 0 {
+		if limit, _ = strconv.ParseInt(l, 0, 64); limit <= 0 {
 			limit = DefaultLimit
 		}
 
@@ -257,14 +309,27 @@ func parseGetRequest(r *http.Request) models.GetWalletsRequest {
 		parameters.Offset = int(offset)
 	}
 
-	return parameters
+	if c := queryParams.Get("cursor"); c != "" {
+		cursor, err := models.DecodeCursor(c)
+		if err != nil {
+			return models.GetWalletsRequest{}, err //nolint:wrapcheck
+		}
+
+		parameters.Cursor = cursor
+	}
+
+	if err := parseListFilters(queryParams, &parameters); err != nil {
+		return models.GetWalletsRequest{}, err
+	}
+
+	return parameters, nil
 }
 
 func (s *Server) deposit(w http.ResponseWriter, r *http.Request) {
 	var transaction models.Transaction
 
-	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
-		http.Error(w, "error", http.StatusBadRequest)
+	if err := decodeBody(r, &transaction); err != nil {
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -275,26 +340,15 @@ func (s *Server) deposit(w http.ResponseWriter, r *http.Request) {
 	userInfo := s.getUserInfo(ctx)
 
 	if err := transaction.Validate(); err != nil {
-		http.Error(w, "transaction validation error", http.StatusBadRequest)
+		s.errorResponse(w, r, err)
 
 		return
 	}
 
 	if err := s.service.Deposit(ctx, transaction, userInfo.UserID); err != nil {
-		switch {
-		case errors.Is(err, models.ErrWalletNotFound):
-			http.Error(w, "wallet not found", http.StatusNotFound)
-
-			return
-		case errors.Is(err, models.ErrWrongCurrency):
-			http.Error(w, "invalid currency", http.StatusUnprocessableEntity)
+		s.errorResponse(w, r, err)
 
-			return
-		default:
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-
-			return
-		}
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -304,8 +358,8 @@ func (s *Server) deposit(w http.ResponseWriter, r *http.Request) {
 func (s *Server) withdraw(w http.ResponseWriter, r *http.Request) {
 	var transaction models.Transaction
 
-	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
-		http.Error(w, "error", http.StatusBadRequest)
+	if err := decodeBody(r, &transaction); err != nil {
+		s.errorResponse(w, r, err)
 
 		return
 	}
@@ -316,38 +370,25 @@ func (s *Server) withdraw(w http.ResponseWriter, r *http.Request) {
 	userInfo := s.getUserInfo(ctx)
 
 	if err := transaction.Validate(); err != nil {
-		http.Error(w, "transaction validation error", http.StatusBadRequest)
+		s.errorResponse(w, r, err)
 
 		return
 	}
 
 	if err := s.service.Withdraw(ctx, transaction, userInfo.UserID); err != nil {
-		switch {
-		case errors.Is(err, models.ErrWalletNotFound):
-			http.Error(w, "wallet not found", http.StatusNotFound)
-
-			return
-		case errors.Is(err, models.ErrWrongCurrency):
-			http.Error(w, "invalid currency", http.StatusUnprocessableEntity)
+		s.screenedResponse(w, r, err)
 
-			return
-		case errors.Is(err, models.ErrInsufficientFunds):
-			http.Error(w, "insufficient funds", http.StatusConflict)
-
-			return
-		default:
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-
-			return
-		}
+		return
 	}
 }
 
 func (s *Server) transfer(w http.ResponseWriter, r *http.Request) {
 	var transaction models.Transaction
 
-	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
-		http.Error(w, "error", http.StatusBadRequest)
+	if err := decodeBody(r, &transaction); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
 	}
 
 	transaction.Type = "transfer"
@@ -356,41 +397,32 @@ func (s *Server) transfer(w http.ResponseWriter, r *http.Request) {
 	userInfo := s.getUserInfo(ctx)
 
 	if err := transaction.Validate(); err != nil {
-		http.Error(w, "transaction validation error", http.StatusBadRequest)
+		s.errorResponse(w, r, err)
 
 		return
 	}
 
 	if err := s.service.Transfer(ctx, transaction, userInfo.UserID); err != nil {
-		switch {
-		case errors.Is(err, models.ErrWalletNotFound):
-			http.Error(w, "wallet not found", http.StatusNotFound)
-
-			return
-		case errors.Is(err, models.ErrWrongCurrency):
-			http.Error(w, "invalid currency", http.StatusUnprocessableEntity)
-
-			return
-		case errors.Is(err, models.ErrInsufficientFunds):
-			http.Error(w, "insufficient funds", http.StatusConflict)
+		s.screenedResponse(w, r, err)
 
-			return
-		default:
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-
-			return
-		}
+		return
 	}
 }
 
 func (s *Server) getTransactions(w http.ResponseWriter, r *http.Request) {
-	request := parseGetRequest(r)
+	request, err := parseGetRequest(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
 	ctx := r.Context()
 	walletIDStr := chi.URLParam(r, "walletId")
 
 	walletID, err := uuid.Parse(walletIDStr)
 	if err != nil {
-		http.Error(w, "invalid wallet id", http.StatusBadRequest)
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
 
 		return
 	}
@@ -399,7 +431,7 @@ func (s *Server) getTransactions(w http.ResponseWriter, r *http.Request) {
 
 	transactions, err := s.service.GetTransactions(ctx, request, models.WalletID(walletID), userInfo.UserID)
 	if err != nil {
-		http.Error(w, "failed to obrain transactions", http.StatusNotFound)
+		s.errorResponse(w, r, err)
 
 		return
 	}
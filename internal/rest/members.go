@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Server) getWalletMembers(w http.ResponseWriter, r *http.Request) {
+	walletID, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	members, err := s.service.GetWalletMembers(ctx, models.WalletID(walletID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(members); err != nil {
+		log.Warn().Err(err).Msg("error while encoding wallet members")
+
+		return
+	}
+}
+
+func (s *Server) addWalletMember(w http.ResponseWriter, r *http.Request) {
+	walletID, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	var member models.WalletMember
+
+	if err = decodeBody(r, &member); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	member.WalletID = models.WalletID(walletID)
+
+	created, err := s.service.AddWalletMember(ctx, member, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(created); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) updateWalletMember(w http.ResponseWriter, r *http.Request) {
+	walletID, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	memberID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("userId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	var member models.WalletMember
+
+	if err = decodeBody(r, &member); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	member.WalletID = models.WalletID(walletID)
+	member.UserID = models.UserID(memberID)
+
+	updated, err := s.service.UpdateWalletMember(ctx, member, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(updated); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) removeWalletMember(w http.ResponseWriter, r *http.Request) {
+	walletID, err := uuid.Parse(chi.URLParam(r, "walletId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("walletId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	memberID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("userId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if err = s.service.RemoveWalletMember(ctx, models.WalletID(walletID), models.UserID(memberID), userInfo.UserID); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
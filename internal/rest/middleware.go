@@ -2,9 +2,6 @@ package rest
 
 import (
 	"context"
-	"crypto/rsa"
-	_ "embed"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -16,61 +13,42 @@ import (
 )
 
 const (
-	tokenLength    = 3
-	authFailedText = "authorization failed"
-	tokenDuration  = 24 * time.Hour
+	tokenLength   = 3
+	tokenDuration = 24 * time.Hour
 )
 
-//go:embed keys/public_key.pem
-var publicKeyData []byte
+type tokenVerifier interface {
+	Verify(ctx context.Context, token string) (*models.Claims, error)
+}
 
-//nolintlint:funlen
 func (s *Server) jwtAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		header := r.Header.Get("Authorization")
 		if header == "" {
-			s.errorUnauthorizedResponse(w, models.ErrInvalidToken)
+			s.errorResponse(w, r, models.ErrInvalidToken)
 
 			return
 		}
 
 		headerParts := strings.Split(header, " ")
 
-		if headerParts[0] != "Bearer" {
-			s.errorUnauthorizedResponse(w, models.ErrInvalidToken)
+		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+			s.errorResponse(w, r, models.ErrInvalidToken)
 
 			return
 		}
 
 		encodedToken := strings.Split(headerParts[1], ".")
 		if len(encodedToken) != tokenLength {
-			s.errorUnauthorizedResponse(w, models.ErrInvalidToken)
+			s.errorResponse(w, r, models.ErrInvalidToken)
 
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(headerParts[1], &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, models.ErrInvalidSigningMethod
-			}
-
-			return s.key, nil
-		})
+		claims, err := s.verifier.Verify(r.Context(), headerParts[1])
 		if err != nil {
-			s.errorUnauthorizedResponse(w, models.ErrInvalidToken)
-
-			return
-		}
-
-		claims, ok := token.Claims.(*models.Claims)
-		if !ok || !token.Valid {
-			s.errorUnauthorizedResponse(w, models.ErrInvalidToken)
-
-			return
-		}
-
-		if claims.ExpiresAt.Before(time.Now()) {
-			s.errorUnauthorizedResponse(w, models.ErrInvalidToken)
+			log.Debug().Err(err).Msg("token rejected")
+			s.errorResponse(w, r, models.ErrInvalidToken)
 
 			return
 		}
@@ -79,33 +57,34 @@ func (s *Server) jwtAuth(next http.Handler) http.Handler {
 			UserID: claims.UserID,
 			Email:  claims.Email,
 			Role:   claims.Role,
+			Scopes: claims.Scopes,
 		}
 
-		r = r.WithContext(context.WithValue(r.Context(), models.UserInfo{}, userInfo))
+		r = r.WithContext(models.ContextWithUserInfo(r.Context(), userInfo))
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func (s *Server) getUserInfo(ctx context.Context) models.UserInfo {
-	val, _ := ctx.Value(models.UserInfo{}).(models.UserInfo)
+// requireScope rejects callers whose token or API key was not granted the scope.
+func (s *Server) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userInfo := s.getUserInfo(r.Context())
 
-	return val
-}
+			if !userInfo.HasScope(scope) {
+				s.errorResponse(w, r, withDetail(models.ErrMissingScope, fmt.Sprintf("%s: %s", models.ErrMissingScope, scope)))
 
-func (s *Server) errorUnauthorizedResponse(w http.ResponseWriter, err error) {
-	errResp := fmt.Errorf("%s: %w", authFailedText, err).Error()
+				return
+			}
 
-	response, err := json.Marshal(errResp)
-	if err != nil {
-		log.Warn().Err(err).Msg("error marshalling response")
+			next.ServeHTTP(w, r)
+		})
 	}
+}
 
-	w.WriteHeader(http.StatusUnauthorized)
-
-	if _, err = w.Write(response); err != nil {
-		log.Warn().Err(err).Msg("error writing response")
-	}
+func (s *Server) getUserInfo(ctx context.Context) models.UserInfo {
+	return models.UserInfoFromContext(ctx)
 }
 
 func NewClaims() *models.Claims {
@@ -119,24 +98,6 @@ func NewClaims() *models.Claims {
 	}
 }
 
-func ReadPublicKey() (*rsa.PublicKey, error) {
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyData)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing public key: %w", err)
-	}
-
-	return publicKey, nil
-}
-
-func GetPublicKey() *rsa.PublicKey {
-	key, err := ReadPublicKey()
-	if err != nil {
-		return nil
-	}
-
-	return key
-}
-
 func (s *Server) metricTrack(next http.Handler) http.Handler {
 	var fn http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
 		defer s.metrics.trackHTTPRequest(time.Now(), r)
@@ -0,0 +1,72 @@
+//nolint:testpackage
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScope(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{}
+
+	tests := []struct {
+		name    string
+		scopes  []string
+		allowed bool
+	}{
+		{
+			name:    "unrestricted token",
+			allowed: true,
+		},
+		{
+			name:    "granted scope",
+			scopes:  []string{models.ScopeWalletsRead, models.ScopeTransactionsWrite},
+			allowed: true,
+		},
+		{
+			name:   "missing scope",
+			scopes: []string{models.ScopeWalletsRead},
+		},
+		{
+			name:   "no scopes",
+			scopes: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := s.requireScope(models.ScopeTransactionsWrite)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			request := httptest.NewRequest(http.MethodPut, "/api/v1/wallets/id/deposit", nil)
+			request = request.WithContext(models.ContextWithUserInfo(request.Context(), models.UserInfo{Scopes: tc.scopes}))
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			if tc.allowed {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				return
+			}
+
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+
+			var problem models.Problem
+
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&problem))
+			require.Equal(t, "missing_scope", problem.Code)
+			require.Contains(t, problem.Detail, models.ScopeTransactionsWrite)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	apiPrefix       = "/api/v1"
+	jsonContentType = "application/json"
+	eventStreamType = "text/event-stream"
+)
+
+// specValidator checks requests, and in debug mode responses, against the OpenAPI spec.
+type specValidator struct {
+	doc               *openapi3.T
+	router            routers.Router
+	validateResponses bool
+}
+
+func newSpecValidator(spec []byte, validateResponses bool) (*specValidator, error) {
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi spec: %w", err)
+	}
+
+	if err = doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build openapi router: %w", err)
+	}
+
+	return &specValidator{
+		doc:               doc,
+		router:            router,
+		validateResponses: validateResponses,
+	}, nil
+}
+
+// validateSpec rejects requests that do not conform to the spec. Requests the spec does not
+// know are left to the router, which answers them with 404 or 405.
+func (s *Server) validateSpec(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := s.spec.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		// The handlers have always decoded bodies as JSON regardless of the header.
+		if route.Operation.RequestBody != nil && r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", jsonContentType)
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+
+		if err = openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			s.errorResponse(w, r, specViolation(err))
+
+			return
+		}
+
+		if !s.spec.validateResponses || isEventStream(route.Operation) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		var body bytes.Buffer
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&body)
+
+		next.ServeHTTP(ww, r)
+
+		s.spec.checkResponse(r.Context(), input, ww.Status(), ww.Header(), body.Bytes())
+	})
+}
+
+// checkResponse logs responses that do not conform to the spec. They have already been sent,
+// so this only surfaces handlers that drifted from the documented API.
+func (v *specValidator) checkResponse(ctx context.Context, input *openapi3filter.RequestValidationInput,
+	status int, header http.Header, body []byte,
+) {
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	err := openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	if err != nil {
+		log.Error().Err(err).
+			Str("method", input.Request.Method).
+			Str("route", input.Route.Path).
+			Int("status", status).
+			Msg("response does not match the openapi spec")
+	}
+}
+
+func isEventStream(operation *openapi3.Operation) bool {
+	response := operation.Responses.Status(http.StatusOK)
+
+	return response != nil && response.Value != nil && response.Value.Content.Get(eventStreamType) != nil
+}
+
+// specViolation converts a request validation error into a validation error naming the
+// offending parameter or body field.
+func specViolation(err error) error {
+	var (
+		requestErr *openapi3filter.RequestError
+		schemaErr  *openapi3.SchemaError
+	)
+
+	if !errors.As(err, &requestErr) {
+		return &models.ValidationError{Message: err.Error(), Err: errInvalidParameter}
+	}
+
+	reason := requestErr.Reason
+
+	switch {
+	case errors.As(requestErr.Err, &schemaErr):
+		reason = schemaErr.Reason
+	case reason == "" && requestErr.Err != nil:
+		reason = requestErr.Err.Error()
+	}
+
+	if requestErr.Parameter != nil {
+		sentinel := errInvalidParameter
+		if errors.Is(requestErr.Err, openapi3filter.ErrInvalidRequired) {
+			sentinel = errMissingParameter
+		}
+
+		return &models.ValidationError{Field: requestErr.Parameter.Name, Message: reason, Err: sentinel}
+	}
+
+	field := ""
+	if schemaErr != nil {
+		field = strings.Join(schemaErr.JSONPointer(), ".")
+	}
+
+	return &models.ValidationError{Field: field, Message: reason, Err: errInvalidBody}
+}
+
+// checkSpecCoverage reports the routes under apiPrefix that the spec does not document and the
+// documented operations that have no route.
+func checkSpecCoverage(routes chi.Routes, doc *openapi3.T) error {
+	registered := make(map[string]struct{})
+
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if path, ok := strings.CutPrefix(route, apiPrefix); ok {
+			registered[method+" "+path] = struct{}{}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	documented := make(map[string]struct{})
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = struct{}{}
+		}
+	}
+
+	var problems []string
+
+	for route := range registered {
+		if _, ok := documented[route]; !ok {
+			problems = append(problems, "route missing from the spec: "+route)
+		}
+	}
+
+	for operation := range documented {
+		if _, ok := registered[operation]; !ok {
+			problems = append(problems, "spec operation without a route: "+operation)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+
+	return errors.New(strings.Join(problems, "\n")) //nolint:err113
+}
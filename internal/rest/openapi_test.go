@@ -0,0 +1,134 @@
+//nolint:testpackage
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	httpapi "github.com/romanpitatelev/wallets-service/api/http"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecCoversRoutes(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Config{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	routes, ok := server.server.Handler.(chi.Routes)
+	require.True(t, ok)
+
+	require.NoError(t, checkSpecCoverage(routes, server.spec.doc))
+}
+
+//nolint:funlen
+func TestValidateSpec(t *testing.T) {
+	t.Parallel()
+
+	spec, err := newSpecValidator(httpapi.Spec, false)
+	require.NoError(t, err)
+
+	s := &Server{spec: spec}
+
+	walletPath := "/api/v1/wallets/20b6abd2-bb57-4331-8538-2c3408cf8b1e"
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		body          string
+		expectedCode  string
+		expectedField string
+	}{
+		{
+			name:   "valid request without content type",
+			method: http.MethodPut,
+			target: walletPath + "/deposit",
+			body:   `{"amount": 10, "currency": "RUB"}`,
+		},
+		{
+			name:   "route unknown to the spec",
+			method: http.MethodGet,
+			target: "/api/v1/unknown",
+		},
+		{
+			name:          "wrong body field type",
+			method:        http.MethodPut,
+			target:        walletPath + "/deposit",
+			body:          `{"amount": "10", "currency": "RUB"}`,
+			expectedCode:  "invalid_body",
+			expectedField: "amount",
+		},
+		{
+			name:         "missing body",
+			method:       http.MethodPut,
+			target:       walletPath + "/transfer",
+			expectedCode: "invalid_body",
+		},
+		{
+			name:          "malformed query parameter",
+			method:        http.MethodGet,
+			target:        "/api/v1/wallets?limit=ten",
+			expectedCode:  "invalid_parameter",
+			expectedField: "limit",
+		},
+		{
+			name:          "missing required parameter",
+			method:        http.MethodGet,
+			target:        "/api/v1/wallets/summary",
+			expectedCode:  "missing_parameter",
+			expectedField: "currency",
+		},
+		{
+			name:          "value outside the enum",
+			method:        http.MethodGet,
+			target:        "/api/v1/webhooks/20b6abd2-bb57-4331-8538-2c3408cf8b1e/deliveries?status=lost",
+			expectedCode:  "invalid_parameter",
+			expectedField: "status",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			called := false
+			handler := s.validateSpec(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				called = true
+
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			request := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			if tc.expectedCode == "" {
+				require.True(t, called)
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				return
+			}
+
+			require.False(t, called)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			require.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+
+			var problem models.Problem
+
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&problem))
+			require.Equal(t, tc.expectedCode, problem.Code)
+
+			if tc.expectedField != "" {
+				require.Len(t, problem.Errors, 1)
+				require.Equal(t, tc.expectedField, problem.Errors[0].Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:wallets-service:problem:"
+	codeInternalError  = "internal_error"
+)
+
+var (
+	errInvalidBody       = errors.New("invalid request body")
+	errBodyTooLarge      = errors.New("request body too large")
+	errInvalidParameter  = errors.New("invalid parameter")
+	errMissingParameter  = errors.New("missing parameter")
+	errStreamUnsupported = errors.New("streaming unsupported")
+)
+
+type problemType struct {
+	err    error
+	status int
+	code   string
+}
+
+// problemTypes maps the errors handlers report to their status and stable problem code.
+// The first entry found in the error chain wins.
+//
+//nolint:gochecknoglobals
+var problemTypes = []problemType{
+	{errInvalidBody, http.StatusBadRequest, "invalid_body"},
+	{errBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large"},
+	{errInvalidParameter, http.StatusBadRequest, "invalid_parameter"},
+	{errMissingParameter, http.StatusBadRequest, "missing_parameter"},
+	{errStreamUnsupported, http.StatusInternalServerError, "streaming_unsupported"},
+
+	{models.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{models.ErrInvalidSignature, http.StatusUnauthorized, "invalid_signature"},
+	{models.ErrReplayedRequest, http.StatusUnauthorized, "replayed_request"},
+	{models.ErrMissingScope, http.StatusForbidden, "missing_scope"},
+	{models.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{models.ErrInsufficientRights, http.StatusForbidden, "insufficient_rights"},
+	{models.ErrSpendLimitExceeded, http.StatusForbidden, "spend_limit_exceeded"},
+	{models.ErrTransactionBlocked, http.StatusForbidden, "transaction_blocked"},
+	{models.ErrUserDeleted, http.StatusForbidden, "user_deleted"},
+
+	{models.ErrWalletNotFound, http.StatusNotFound, "wallet_not_found"},
+	{models.ErrWrongUserID, http.StatusNotFound, "user_mismatch"},
+	{models.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{models.ErrMemberNotFound, http.StatusNotFound, "member_not_found"},
+	{models.ErrCategoryNotFound, http.StatusNotFound, "category_not_found"},
+	{models.ErrRuleNotFound, http.StatusNotFound, "category_rule_not_found"},
+	{models.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found"},
+	{models.ErrBudgetNotFound, http.StatusNotFound, "budget_not_found"},
+	{models.ErrAPIKeyNotFound, http.StatusNotFound, "api_key_not_found"},
+	{models.ErrWebhookNotFound, http.StatusNotFound, "webhook_not_found"},
+	{models.ErrDeliveryNotFound, http.StatusNotFound, "webhook_delivery_not_found"},
+	{models.ErrRiskRuleNotFound, http.StatusNotFound, "risk_rule_not_found"},
+	{models.ErrAssessmentNotFound, http.StatusNotFound, "risk_assessment_not_found"},
+	{models.ErrCaseNotFound, http.StatusNotFound, "compliance_case_not_found"},
+
+	{models.ErrWalletEmptyName, http.StatusBadRequest, "wallet_name_empty"},
+	{models.ErrNonZeroBalanceWallet, http.StatusBadRequest, "wallet_balance_not_zero"},
+	{models.ErrZeroAmount, http.StatusBadRequest, "amount_zero"},
+	{models.ErrNegativeAmount, http.StatusBadRequest, "amount_negative"},
+	{models.ErrSameWallet, http.StatusBadRequest, "same_wallet"},
+	{models.ErrInvalidTransaction, http.StatusBadRequest, "invalid_transaction_wallets"},
+	{models.ErrInvalidDescription, http.StatusBadRequest, "invalid_description"},
+	{models.ErrInvalidExternalReference, http.StatusBadRequest, "invalid_external_reference"},
+	{models.ErrInvalidMetadata, http.StatusBadRequest, "invalid_metadata"},
+	{models.ErrInvalidUUIDFormat, http.StatusBadRequest, "invalid_uuid"},
+	{models.ErrInvalidFilter, http.StatusBadRequest, "invalid_filter"},
+	{models.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{models.ErrInvalidGranularity, http.StatusBadRequest, "invalid_granularity"},
+	{models.ErrCategoryEmptyName, http.StatusBadRequest, "category_name_empty"},
+	{models.ErrInvalidRule, http.StatusBadRequest, "invalid_category_rule"},
+	{models.ErrInvalidBudget, http.StatusBadRequest, "invalid_budget"},
+	{models.ErrInvalidMember, http.StatusBadRequest, "invalid_member"},
+	{models.ErrInvalidAPIKey, http.StatusBadRequest, "invalid_api_key"},
+	{models.ErrInvalidWebhook, http.StatusBadRequest, "invalid_webhook"},
+	{models.ErrInvalidRiskRule, http.StatusBadRequest, "invalid_risk_rule"},
+	{models.ErrInvalidCaseStatus, http.StatusBadRequest, "invalid_case_status"},
+	{models.ErrInvalidCaseNote, http.StatusBadRequest, "invalid_case_note"},
+	{models.ErrInvalidPreferences, http.StatusBadRequest, "invalid_preferences"},
+	{models.ErrWrongCurrency, http.StatusUnprocessableEntity, "wrong_currency"},
+
+	{models.ErrInsufficientFunds, http.StatusConflict, "insufficient_funds"},
+	{models.ErrWalletFrozen, http.StatusConflict, "wallet_frozen"},
+	{models.ErrDuplicateExternalReference, http.StatusConflict, "duplicate_external_reference"},
+	{models.ErrCategoryExists, http.StatusConflict, "category_exists"},
+	{models.ErrMemberExists, http.StatusConflict, "member_exists"},
+	{models.ErrLastOwner, http.StatusConflict, "last_owner"},
+	{models.ErrAssessmentDecided, http.StatusConflict, "risk_assessment_decided"},
+	{models.ErrInvalidCaseTransition, http.StatusConflict, "invalid_case_transition"},
+
+	{models.ErrRateLimited, http.StatusTooManyRequests, "rate_limited"},
+}
+
+func lookupProblemType(err error) (problemType, bool) {
+	for _, p := range problemTypes {
+		if errors.Is(err, p.err) {
+			return p, true
+		}
+	}
+
+	return problemType{}, false
+}
+
+// errorResponse writes err as an application/problem+json response. Known errors carry their
+// message as the detail and the invalid field, if any; unknown errors are logged and reported
+// as internal errors without details.
+func (s *Server) errorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	requestID := middleware.GetReqID(r.Context())
+
+	problem := models.Problem{
+		Instance:  r.URL.Path,
+		RequestID: requestID,
+	}
+
+	known, ok := lookupProblemType(err)
+	if !ok {
+		log.Error().Err(err).Str("requestId", requestID).Str("path", r.URL.Path).Msg("request failed")
+
+		known = problemType{status: http.StatusInternalServerError, code: codeInternalError}
+	}
+
+	problem.Type = problemTypePrefix + known.code
+	problem.Title = http.StatusText(known.status)
+	problem.Status = known.status
+	problem.Code = known.code
+
+	var (
+		validationErr *models.ValidationError
+		detailedErr   *detailedError
+	)
+
+	switch {
+	case !ok:
+	case errors.As(err, &validationErr):
+		problem.Detail = validationErr.Error()
+
+		if validationErr.Field != "" {
+			problem.Errors = []models.FieldError{{
+				Field:   validationErr.Field,
+				Code:    known.code,
+				Message: validationErr.Reason(),
+			}}
+		}
+	case errors.As(err, &detailedErr):
+		problem.Detail = detailedErr.detail
+	default:
+		problem.Detail = known.err.Error()
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+
+	if err = json.NewEncoder(w).Encode(problem); err != nil {
+		log.Warn().Err(err).Msg("failed to encode problem")
+	}
+}
+
+// invalidField reports a malformed path or query parameter.
+func invalidField(field string, err error) error {
+	return &models.ValidationError{Field: field, Err: err}
+}
+
+// decodeBody decodes the JSON request body into v. Decoding failures are reported as invalid
+// bodies, naming the field when the decoder knows it.
+func decodeBody(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, models.ErrInvalidUUIDFormat):
+		return err //nolint:wrapcheck
+	case errors.As(err, &typeErr):
+		return &models.ValidationError{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("cannot decode a JSON %s", typeErr.Value),
+			Err:     errInvalidBody,
+		}
+	default:
+		return &models.ValidationError{Message: err.Error(), Err: errInvalidBody}
+	}
+}
+
+// detailedError gives a known error a more specific client-facing detail.
+type detailedError struct {
+	err    error
+	detail string
+}
+
+func (e *detailedError) Error() string {
+	return e.detail
+}
+
+func (e *detailedError) Unwrap() error {
+	return e.err
+}
+
+func withDetail(err error, detail string) error {
+	return &detailedError{err: err, detail: detail}
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/ratelimit"
+	"github.com/rs/zerolog/log"
+)
+
+type rateLimiter interface {
+	Allow(ctx context.Context, userID models.UserID, budget string) (models.RateLimitResult, error)
+}
+
+// rateLimit takes a token from the caller's bucket for the budget and rejects the request
+// with 429 when it is empty. Requests are let through when the limiter fails.
+func (s *Server) rateLimit(budget string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.limiter == nil {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			ctx := r.Context()
+
+			result, err := s.limiter.Allow(ctx, s.getUserInfo(ctx).UserID, budget)
+			if err != nil {
+				log.Warn().Err(err).Str("budget", budget).Msg("rate limiter failed")
+			}
+
+			if result.Limit > 0 {
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+				w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			}
+
+			if !result.Allowed {
+				ratelimit.Rejected().WithLabelValues(budget).Inc()
+
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				s.errorResponse(w, r, models.ErrRateLimited)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+//nolint:gochecknoglobals
+var validReviewStatus = map[string]struct{}{
+	models.ReviewStatusPending:  {},
+	models.ReviewStatusApproved: {},
+	models.ReviewStatusDeclined: {},
+}
+
+// screenedResponse answers a withdrawal or transfer that risk screening held with 202 and the
+// assessment, which a reviewer approves or declines later. Other errors, blocks included, are
+// problems.
+func (s *Server) screenedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var riskErr *models.RiskError
+
+	if !errors.As(err, &riskErr) {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	if riskErr.Assessment.Outcome != models.RiskOutcomeReview {
+		s.errorResponse(w, r, withDetail(err, riskErr.Error()))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	if err = json.NewEncoder(w).Encode(riskErr.Assessment); err != nil {
+		log.Warn().Err(err).Msg("error while encoding risk assessment")
+
+		return
+	}
+}
+
+func (s *Server) getRiskRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanReviewRisk() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	rules, err := s.service.GetRiskRules(ctx)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(rules); err != nil {
+		log.Warn().Err(err).Msg("error while encoding risk rules")
+
+		return
+	}
+}
+
+func (s *Server) saveRiskRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanReviewRisk() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	var rule models.RiskRule
+
+	if err := decodeBody(r, &rule); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	rule.Name = chi.URLParam(r, "name")
+
+	saved, err := s.service.SaveRiskRule(ctx, rule)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(saved); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) deleteRiskRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanReviewRisk() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	if err := s.service.DeleteRiskRule(ctx, chi.URLParam(r, "name")); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getRiskReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanReviewRisk() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.ReviewStatusPending
+	}
+
+	if _, ok := validReviewStatus[status]; !ok {
+		s.errorResponse(w, r, invalidField("status", errInvalidParameter))
+
+		return
+	}
+
+	reviews, err := s.service.GetRiskReviews(ctx, status)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(reviews); err != nil {
+		log.Warn().Err(err).Msg("error while encoding risk reviews")
+
+		return
+	}
+}
+
+func (s *Server) approveRiskReview(w http.ResponseWriter, r *http.Request) {
+	s.handleRiskReview(w, r, s.service.ApproveRiskReview)
+}
+
+func (s *Server) declineRiskReview(w http.ResponseWriter, r *http.Request) {
+	s.handleRiskReview(w, r, s.service.DeclineRiskReview)
+}
+
+//nolint:lll
+func (s *Server) handleRiskReview(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, assessmentID models.AssessmentID, reviewerID models.UserID) (models.RiskAssessment, error)) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanReviewRisk() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	assessmentID, err := uuid.Parse(chi.URLParam(r, "assessmentId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("assessmentId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	decided, err := fn(ctx, models.AssessmentID(assessmentID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(decided); err != nil {
+		log.Warn().Err(err).Msg("error while encoding risk assessment")
+
+		return
+	}
+}
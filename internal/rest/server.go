@@ -2,7 +2,6 @@ package rest
 
 import (
 	"context"
-	"crypto/rsa"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,6 +10,8 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	httpapi "github.com/romanpitatelev/wallets-service/api/http"
+	"github.com/romanpitatelev/wallets-service/internal/models"
 	"github.com/rs/zerolog/log"
 )
 
@@ -21,17 +22,26 @@ const (
 
 type Config struct {
 	Port int
+	// ValidateResponses checks responses against the OpenAPI spec too and logs mismatches.
+	ValidateResponses bool
 }
 
 type Server struct {
-	server  *http.Server
-	service service
-	port    int
-	key     *rsa.PublicKey
-	metrics *metrics
+	server   *http.Server
+	service  service
+	port     int
+	verifier tokenVerifier
+	limiter  rateLimiter
+	metrics  *metrics
+	spec     *specValidator
 }
 
-func New(cfg Config, service service, key *rsa.PublicKey) *Server {
+func New(cfg Config, service service, verifier tokenVerifier, limiter rateLimiter) (*Server, error) {
+	spec, err := newSpecValidator(httpapi.Spec, cfg.ValidateResponses)
+	if err != nil {
+		return nil, err
+	}
+
 	router := chi.NewRouter()
 	s := &Server{
 		service: service,
@@ -40,32 +50,107 @@ func New(cfg Config, service service, key *rsa.PublicKey) *Server {
 			Handler:           router,
 			ReadHeaderTimeout: ReadHeaderTimeoutValue * time.Second,
 		},
-		port:    cfg.Port,
-		key:     key,
-		metrics: newMetrics(),
+		port:     cfg.Port,
+		verifier: verifier,
+		limiter:  limiter,
+		metrics:  newMetrics(),
+		spec:     spec,
 	}
 
 	router.Get("/metrics", promhttp.Handler().ServeHTTP)
 
 	router.Route("/api", func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
+			r.Use(middleware.RequestID)
 			r.Use(middleware.Recoverer)
-			r.Use(s.jwtAuth)
+			r.Use(s.authenticate)
 			r.Use(s.metricTrack)
+			r.Use(s.auditTrack)
+			r.Use(s.validateSpec)
+
+			// Every route declares the scope a token or API key needs for it and the rate limit
+			// budget it draws from.
+			defaultBudget := s.rateLimit(models.RateLimitDefault)
+			walletsRead := r.With(s.requireScope(models.ScopeWalletsRead), defaultBudget)
+			walletsWrite := r.With(s.requireScope(models.ScopeWalletsWrite), defaultBudget)
+			transactionsRead := r.With(s.requireScope(models.ScopeTransactionsRead), defaultBudget)
+			transactionsWrite := r.With(s.requireScope(models.ScopeTransactionsWrite), defaultBudget)
+			moneyMovement := r.With(s.requireScope(models.ScopeTransactionsWrite), s.rateLimit(models.RateLimitMoney))
+
+			walletsWrite.Post("/wallets", s.createWallet)
+			walletsRead.Get("/wallets/summary", s.getPortfolioSummary)
+			walletsRead.Get("/wallets/{walletId}", s.getWallet)
+			walletsWrite.Patch("/wallets/{walletId}", s.updateWallet)
+			walletsWrite.Delete("/wallets/{walletId}", s.deleteWallet)
+			walletsRead.Get("/wallets", s.getWallets)
+			moneyMovement.Put("/wallets/{walletId}/deposit", s.deposit)
+			moneyMovement.Put("/wallets/{walletId}/withdrawal", s.withdraw)
+			moneyMovement.Put("/wallets/{walletId}/transfer", s.transfer)
+			transactionsRead.Get("/wallets/{walletId}/transactions", s.getTransactions)
+			transactionsRead.Get("/wallets/{walletId}/transactions/categories", s.getCategoryTotals)
+			transactionsWrite.Put("/wallets/{walletId}/transactions/{transactionId}/category", s.setTransactionCategory)
+			walletsRead.Get("/wallets/{walletId}/members", s.getWalletMembers)
+			walletsWrite.Post("/wallets/{walletId}/members", s.addWalletMember)
+			walletsWrite.Patch("/wallets/{walletId}/members/{userId}", s.updateWalletMember)
+			walletsWrite.Delete("/wallets/{walletId}/members/{userId}", s.removeWalletMember)
+
+			walletsWrite.Post("/categories", s.createCategory)
+			walletsRead.Get("/categories", s.getCategories)
+			walletsWrite.Delete("/categories/{categoryId}", s.deleteCategory)
+			walletsWrite.Post("/categories/rules", s.createCategoryRule)
+			walletsRead.Get("/categories/rules", s.getCategoryRules)
+			walletsWrite.Delete("/categories/rules/{ruleId}", s.deleteCategoryRule)
+			transactionsWrite.Post("/categories/rules/apply", s.applyCategoryRules)
+
+			walletsWrite.Post("/budgets", s.createBudget)
+			walletsRead.Get("/budgets", s.getBudgets)
+			walletsRead.Get("/budgets/{budgetId}", s.getBudget)
+			walletsWrite.Delete("/budgets/{budgetId}", s.deleteBudget)
+
+			walletsRead.Get("/stream", s.streamEvents)
+
+			transactionsRead.Get("/analytics", s.getAnalytics)
+			transactionsRead.Get("/analytics/wallets/{walletId}", s.getAnalytics)
+
+			walletsRead.Get("/audit", s.getAuditRecords)
+			walletsRead.Get("/audit/export", s.exportAuditRecords)
+
+			walletsWrite.Post("/webhooks", s.createWebhook)
+			walletsRead.Get("/webhooks", s.getWebhooks)
+			walletsWrite.Delete("/webhooks/{webhookId}", s.deleteWebhook)
+			walletsRead.Get("/webhooks/{webhookId}/deliveries", s.getWebhookDeliveries)
+			walletsRead.Get("/webhooks/{webhookId}/deliveries/{deliveryId}", s.getWebhookDelivery)
+			walletsWrite.Post("/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver", s.redeliverWebhook)
+
+			// Routes acting on the account itself rather than on its wallets are not available to API keys.
+			userRead := r.With(s.userOnly, s.requireScope(models.ScopeWalletsRead), defaultBudget)
+			userWrite := r.With(s.userOnly, s.requireScope(models.ScopeWalletsWrite), defaultBudget)
+
+			userRead.Get("/users/me", s.getMe)
+			userWrite.Patch("/users/me/preferences", s.updateMyPreferences)
+			userRead.Get("/users", s.searchUsers)
+
+			userWrite.Post("/api-keys", s.createAPIKey)
+			userRead.Get("/api-keys", s.getAPIKeys)
+			userWrite.Post("/api-keys/{keyId}/rotate", s.rotateAPIKey)
+			userWrite.Delete("/api-keys/{keyId}", s.revokeAPIKey)
+
+			userRead.Get("/risk/rules", s.getRiskRules)
+			userWrite.Put("/risk/rules/{name}", s.saveRiskRule)
+			userWrite.Delete("/risk/rules/{name}", s.deleteRiskRule)
+			userRead.Get("/risk/reviews", s.getRiskReviews)
+			userWrite.Post("/risk/reviews/{assessmentId}/approve", s.approveRiskReview)
+			userWrite.Post("/risk/reviews/{assessmentId}/decline", s.declineRiskReview)
 
-			r.Post("/wallets", s.createWallet)
-			r.Get("/wallets/{walletId}", s.getWallet)
-			r.Patch("/wallets/{walletId}", s.updateWallet)
-			r.Delete("/wallets/{walletId}", s.deleteWallet)
-			r.Get("/wallets", s.getWallets)
-			r.Put("/wallets/{walletId}/deposit", s.deposit)
-			r.Put("/wallets/{walletId}/withdrawal", s.withdraw)
-			r.Put("/wallets/{walletId}/transfer", s.transfer)
-			r.Get("/wallets/{walletId}/transactions", s.getTransactions)
+			userRead.Get("/compliance/cases", s.getComplianceCases)
+			userRead.Get("/compliance/cases/export", s.exportComplianceCases)
+			userRead.Get("/compliance/cases/{caseId}", s.getComplianceCase)
+			userWrite.Patch("/compliance/cases/{caseId}", s.updateComplianceCase)
+			userWrite.Post("/compliance/cases/{caseId}/notes", s.addComplianceCaseNote)
 		})
 	})
 
-	return s
+	return s, nil
 }
 
 func (s *Server) Run(ctx context.Context) error {
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	streamHeartbeat  = 15 * time.Second
+	streamRetryDelay = 3 * time.Second
+)
+
+// streamEvents pushes the notifications of the caller as server-sent events. Wallet events carry
+// their ID, so a reconnecting client resumes with the Last-Event-ID header or the lastEventId
+// query parameter. The stream ends when the client falls too far behind; the client then resumes.
+// Events are sent in commit order, which their IDs follow. New transactions are left out unless
+// the caller may read transactions.
+//
+//nolint:funlen
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.errorResponse(w, r, errStreamUnsupported)
+
+		return
+	}
+
+	lastEventID := int64(0)
+
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+
+	if value != "" {
+		var err error
+
+		if lastEventID, err = strconv.ParseInt(value, 10, 64); err != nil || lastEventID < 0 {
+			s.errorResponse(w, r, invalidField("lastEventId", errInvalidParameter))
+
+			return
+		}
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+	started := false
+
+	visible := func(notification models.Notification) bool {
+		return notification.Type != models.NotificationTransactionCreated || userInfo.HasScope(models.ScopeTransactionsRead)
+	}
+
+	start := func() error {
+		if started {
+			return nil
+		}
+
+		started = true
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetryDelay.Milliseconds()); err != nil {
+			return fmt.Errorf("failed to write retry delay: %w", err)
+		}
+
+		return nil
+	}
+
+	live, unsubscribe, err := s.service.StreamNotifications(ctx, userInfo.UserID, lastEventID, func(notification models.Notification) error {
+		if err := start(); err != nil {
+			return err
+		}
+
+		if !visible(notification) {
+			return nil
+		}
+
+		return writeEvent(w, notification)
+	})
+	if err != nil {
+		if !started {
+			s.errorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	defer unsubscribe()
+
+	if err = start(); err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case notification, ok := <-live:
+			if !ok {
+				return
+			}
+
+			if !visible(notification) {
+				continue
+			}
+
+			if err = writeEvent(w, notification); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, notification models.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to encode notification")
+
+		return nil
+	}
+
+	if notification.ID != 0 {
+		if _, err = fmt.Fprintf(w, "id: %d\n", notification.ID); err != nil {
+			return fmt.Errorf("failed to write event id: %w", err)
+		}
+	}
+
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", notification.Type, data); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Server) getMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	account, err := s.service.GetUserAccount(ctx, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(account); err != nil {
+		log.Warn().Err(err).Msg("error while encoding user")
+
+		return
+	}
+}
+
+func (s *Server) updateMyPreferences(w http.ResponseWriter, r *http.Request) {
+	var preferences models.UserPreferences
+
+	if err := decodeBody(r, &preferences); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	account, err := s.service.UpdateUserPreferences(ctx, userInfo.UserID, preferences)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(account); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) searchUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	if !userInfo.CanSearchUsers() {
+		s.errorResponse(w, r, models.ErrForbidden)
+
+		return
+	}
+
+	search, err := parseUserSearch(r)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	accounts, err := s.service.SearchUsers(ctx, search)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(accounts); err != nil {
+		log.Warn().Err(err).Msg("error while encoding users")
+
+		return
+	}
+}
+
+func parseUserSearch(r *http.Request) (models.UserSearch, error) {
+	queryParams := r.URL.Query()
+
+	search := models.UserSearch{
+		FirstName: queryParams.Get("firstName"),
+		LastName:  queryParams.Get("lastName"),
+		Limit:     DefaultLimit,
+	}
+
+	if u := queryParams.Get("userId"); u != "" {
+		userID, err := uuid.Parse(u)
+		if err != nil {
+			return models.UserSearch{}, invalidField("userId", models.ErrInvalidUUIDFormat)
+		}
+
+		id := models.UserID(userID)
+		search.UserID = &id
+	}
+
+	if d := queryParams.Get("deleted"); d != "" {
+		deleted, err := strconv.ParseBool(d)
+		if err != nil {
+			return models.UserSearch{}, invalidField("deleted", errInvalidParameter)
+		}
+
+		search.Deleted = &deleted
+	}
+
+	if l := queryParams.Get("limit"); l != "" {
+		if limit, _ := strconv.Atoi(l); limit > 0 {
+			search.Limit = limit
+		}
+	}
+
+	if o := queryParams.Get("offset"); o != "" {
+		search.Offset, _ = strconv.Atoi(o)
+	}
+
+	return search, nil
+}
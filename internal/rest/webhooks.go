@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+//nolint:gochecknoglobals
+var validDeliveryStatus = map[string]struct{}{
+	"":                             {},
+	models.DeliveryStatusPending:   {},
+	models.DeliveryStatusDelivered: {},
+	models.DeliveryStatusDead:      {},
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook models.Webhook
+
+	if err := decodeBody(r, &webhook); err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	webhook.WebhookID = models.WebhookID(uuid.New())
+
+	created, err := s.service.CreateWebhook(ctx, webhook, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(created); err != nil {
+		log.Warn().Err(err).Msg("failed to encode response")
+
+		return
+	}
+}
+
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	webhooks, err := s.service.GetWebhooks(ctx, userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(webhooks); err != nil {
+		log.Warn().Err(err).Msg("error while encoding webhooks")
+
+		return
+	}
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("webhookId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	err = s.service.DeleteWebhook(ctx, models.WebhookID(webhookID), userInfo.UserID)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("webhookId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if _, ok := validDeliveryStatus[status]; !ok {
+		s.errorResponse(w, r, invalidField("status", errInvalidParameter))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	deliveries, err := s.service.GetWebhookDeliveries(ctx, models.WebhookID(webhookID), userInfo.UserID, status)
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(deliveries); err != nil {
+		log.Warn().Err(err).Msg("error while encoding webhook deliveries")
+
+		return
+	}
+}
+
+func (s *Server) getWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	s.handleWebhookDelivery(w, r, s.service.GetWebhookDelivery)
+}
+
+func (s *Server) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	s.handleWebhookDelivery(w, r, s.service.RedeliverWebhook)
+}
+
+//nolint:lll
+func (s *Server) handleWebhookDelivery(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error)) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("webhookId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		s.errorResponse(w, r, invalidField("deliveryId", models.ErrInvalidUUIDFormat))
+
+		return
+	}
+
+	ctx := r.Context()
+	userInfo := s.getUserInfo(ctx)
+
+	delivery, err := fn(ctx, models.WebhookID(webhookID), models.DeliveryID(deliveryID), userInfo.UserID)
+
+	if err != nil {
+		s.errorResponse(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(w).Encode(delivery); err != nil {
+		log.Warn().Err(err).Msg("error while encoding webhook delivery")
+
+		return
+	}
+}
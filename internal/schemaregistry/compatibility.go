@@ -0,0 +1,112 @@
+package schemaregistry
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CheckCompatibility reports the changes from the previous schema of a message to the next one
+// that keep messages of one from being read with the other: a field or enum value removed
+// without reserving its number, a number reused, or a field changing its type or cardinality.
+// Adding fields and enum values and renaming them are compatible.
+func CheckCompatibility(previous, next protoreflect.MessageDescriptor) error {
+	checker := compatibilityChecker{seen: make(map[protoreflect.FullName]struct{})}
+	checker.checkMessage(previous, next)
+
+	return errors.Join(checker.errs...)
+}
+
+type compatibilityChecker struct {
+	seen map[protoreflect.FullName]struct{}
+	errs []error
+}
+
+func (c *compatibilityChecker) fail(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...)) //nolint:err113
+}
+
+func (c *compatibilityChecker) checkMessage(previous, next protoreflect.MessageDescriptor) {
+	if _, ok := c.seen[previous.FullName()]; ok {
+		return
+	}
+
+	c.seen[previous.FullName()] = struct{}{}
+
+	previousFields, nextFields := previous.Fields(), next.Fields()
+
+	for i := range previousFields.Len() {
+		field := previousFields.Get(i)
+
+		nextField := nextFields.ByNumber(field.Number())
+		if nextField == nil {
+			if !next.ReservedRanges().Has(field.Number()) {
+				c.fail("%s: field %d (%s) removed without reserving its number", previous.FullName(), field.Number(), field.Name())
+			}
+
+			continue
+		}
+
+		c.checkField(previous, field, nextField)
+	}
+
+	for i := range nextFields.Len() {
+		field := nextFields.Get(i)
+
+		if previous.ReservedRanges().Has(field.Number()) {
+			c.fail("%s: field %d (%s) reuses a reserved number", next.FullName(), field.Number(), field.Name())
+		}
+	}
+}
+
+func (c *compatibilityChecker) checkField(message protoreflect.MessageDescriptor, previous, next protoreflect.FieldDescriptor) {
+	name := fmt.Sprintf("%s: field %d (%s)", message.FullName(), previous.Number(), previous.Name())
+
+	switch {
+	case previous.Kind() != next.Kind():
+		c.fail("%s changed type from %s to %s", name, previous.Kind(), next.Kind())
+
+		return
+	case previous.IsMap() != next.IsMap(), previous.Cardinality() != next.Cardinality():
+		c.fail("%s changed cardinality", name)
+
+		return
+	}
+
+	switch {
+	case previous.IsMap():
+		c.checkField(previous.Message(), previous.MapKey(), next.MapKey())
+		c.checkField(previous.Message(), previous.MapValue(), next.MapValue())
+	case previous.Message() != nil:
+		c.checkMessage(previous.Message(), next.Message())
+	case previous.Enum() != nil:
+		c.checkEnum(previous.Enum(), next.Enum())
+	}
+}
+
+func (c *compatibilityChecker) checkEnum(previous, next protoreflect.EnumDescriptor) {
+	if _, ok := c.seen[previous.FullName()]; ok {
+		return
+	}
+
+	c.seen[previous.FullName()] = struct{}{}
+
+	previousValues, nextValues := previous.Values(), next.Values()
+
+	for i := range previousValues.Len() {
+		value := previousValues.Get(i)
+
+		if nextValues.ByNumber(value.Number()) == nil && !next.ReservedRanges().Has(value.Number()) {
+			c.fail("%s: value %d (%s) removed without reserving its number", previous.FullName(), value.Number(), value.Name())
+		}
+	}
+
+	for i := range nextValues.Len() {
+		value := nextValues.Get(i)
+
+		if previous.ReservedRanges().Has(value.Number()) {
+			c.fail("%s: value %d (%s) reuses a reserved number", next.FullName(), value.Number(), value.Name())
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package schemaregistry keeps the versions of the protobuf schemas of Kafka messages in a
+// directory of files, one per version of a subject, checked into the repository next to the
+// .proto files.
+package schemaregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const schemaExt = ".json"
+
+var (
+	ErrSubjectNotFound      = errors.New("subject not found")
+	ErrVersionNotFound      = errors.New("schema version not found")
+	ErrSchemaNotRegistered  = errors.New("schema is not registered")
+	ErrIncompatibleSchema   = errors.New("schema is incompatible with a registered version")
+	ErrRegistryNotWriteable = errors.New("schema registry is read only")
+)
+
+// Registry reads the schemas from a file system and, when opened on a directory, registers new
+// versions in it.
+type Registry struct {
+	fsys fs.FS
+	dir  string
+}
+
+// schemaFile is a registered version: the message and the files declaring it and its imports.
+type schemaFile struct {
+	Message string          `json:"message"`
+	Files   json.RawMessage `json:"files"`
+}
+
+// New returns a read only registry of the schemas in fsys, such as an embedded directory.
+func New(fsys fs.FS) *Registry {
+	return &Registry{fsys: fsys}
+}
+
+// Open returns a registry of the schemas in the directory that can register new versions.
+func Open(dir string) *Registry {
+	return &Registry{fsys: os.DirFS(dir), dir: dir}
+}
+
+// Versions returns the registered versions of the subject in ascending order.
+func (r *Registry) Versions(subject string) ([]int, error) {
+	entries, err := fs.ReadDir(r.fsys, subject)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrSubjectNotFound, subject)
+		}
+
+		return nil, fmt.Errorf("failed to read subject %s: %w", subject, err)
+	}
+
+	versions := make([]int, 0, len(entries))
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), schemaExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		version, err := strconv.Atoi(name)
+		if err != nil || version < 1 {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	slices.Sort(versions)
+
+	return versions, nil
+}
+
+// Schema returns the message descriptor of a registered version of the subject.
+func (r *Registry) Schema(subject string, version int) (protoreflect.MessageDescriptor, error) {
+	data, err := fs.ReadFile(r.fsys, path.Join(subject, strconv.Itoa(version)+schemaExt))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s version %d", ErrVersionNotFound, subject, version)
+		}
+
+		return nil, fmt.Errorf("failed to read schema %s version %d: %w", subject, version, err)
+	}
+
+	var file schemaFile
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode schema %s version %d: %w", subject, version, err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+
+	if err = protojson.Unmarshal(file.Files, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode schema %s version %d: %w", subject, version, err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s version %d: %w", subject, version, err)
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(file.Message))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s version %d: %w", subject, version, err)
+	}
+
+	message, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("invalid schema %s version %d: %s is not a message", subject, version, file.Message)
+	}
+
+	return message, nil
+}
+
+// Version returns the registered version of the subject with exactly the schema of the message.
+func (r *Registry) Version(subject string, message protoreflect.MessageDescriptor) (int, error) {
+	versions, err := r.Versions(subject)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		registered, err := r.Schema(subject, versions[i])
+		if err != nil {
+			return 0, err
+		}
+
+		if sameSchema(registered, message) {
+			return versions[i], nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s of %s", ErrSchemaNotRegistered, message.FullName(), subject)
+}
+
+// Register adds the schema of the message as the next version of the subject, unless it is
+// already the latest one, and returns its version. The schema must be compatible with every
+// registered version, so that messages of any version can be read with any other.
+func (r *Registry) Register(subject string, message protoreflect.MessageDescriptor) (int, error) {
+	if r.dir == "" {
+		return 0, ErrRegistryNotWriteable
+	}
+
+	versions, err := r.Versions(subject)
+	if err != nil && !errors.Is(err, ErrSubjectNotFound) {
+		return 0, err
+	}
+
+	for i, version := range versions {
+		registered, err := r.Schema(subject, version)
+		if err != nil {
+			return 0, err
+		}
+
+		if i == len(versions)-1 && sameSchema(registered, message) {
+			return version, nil
+		}
+
+		if err = CheckCompatibility(registered, message); err != nil {
+			return 0, fmt.Errorf("%w: version %d: %w", ErrIncompatibleSchema, version, err)
+		}
+	}
+
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1] + 1
+	}
+
+	data, err := encodeSchema(message)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = os.MkdirAll(filepath.Join(r.dir, subject), 0o755); err != nil { //nolint:mnd
+		return 0, fmt.Errorf("failed to create subject %s: %w", subject, err)
+	}
+
+	file := filepath.Join(r.dir, subject, strconv.Itoa(next)+schemaExt)
+
+	if err = os.WriteFile(file, data, 0o644); err != nil { //nolint:gosec,mnd
+		return 0, fmt.Errorf("failed to write schema %s version %d: %w", subject, next, err)
+	}
+
+	return next, nil
+}
+
+func encodeSchema(message protoreflect.MessageDescriptor) ([]byte, error) {
+	files, err := (protojson.MarshalOptions{Multiline: true, Indent: "  "}).Marshal(fileSet(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema of %s: %w", message.FullName(), err)
+	}
+
+	data, err := json.MarshalIndent(schemaFile{Message: string(message.FullName()), Files: files}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema of %s: %w", message.FullName(), err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// fileSet returns the file declaring the message and the files it imports, dependencies first.
+func fileSet(message protoreflect.MessageDescriptor) *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]struct{})
+
+	var add func(file protoreflect.FileDescriptor)
+
+	add = func(file protoreflect.FileDescriptor) {
+		if _, ok := seen[file.Path()]; ok {
+			return
+		}
+
+		seen[file.Path()] = struct{}{}
+
+		for i := range file.Imports().Len() {
+			add(file.Imports().Get(i).FileDescriptor)
+		}
+
+		fileProto := protodesc.ToFileDescriptorProto(file)
+		fileProto.SourceCodeInfo = nil
+		set.File = append(set.File, fileProto)
+	}
+
+	add(message.ParentFile())
+
+	return set
+}
+
+func sameSchema(a, b protoreflect.MessageDescriptor) bool {
+	return a.FullName() == b.FullName() && proto.Equal(fileSet(a), fileSet(b))
+}
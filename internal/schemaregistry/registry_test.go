@@ -0,0 +1,146 @@
+//nolint:testpackage
+package schemaregistry
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const subject = "events-value"
+
+func field(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     kind.Enum(),
+	}
+}
+
+func reserved(number int32) *descriptorpb.DescriptorProto_ReservedRange {
+	return &descriptorpb.DescriptorProto_ReservedRange{Start: proto.Int32(number), End: proto.Int32(number + 1)}
+}
+
+func newMessage(
+	t *testing.T,
+	fields []*descriptorpb.FieldDescriptorProto,
+	reservedRanges ...*descriptorpb.DescriptorProto_ReservedRange,
+) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("events.proto"),
+		Package: proto.String("events.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:          proto.String("Event"),
+			Field:         fields,
+			ReservedRange: reservedRanges,
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	return file.Messages().Get(0)
+}
+
+//nolint:funlen
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	registry := Open(t.TempDir())
+
+	first := newMessage(t, []*descriptorpb.FieldDescriptorProto{
+		field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+	})
+
+	version, err := registry.Register(subject, first)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	t.Run("same schema is not registered again", func(t *testing.T) {
+		version, err := registry.Register(subject, first)
+		require.NoError(t, err)
+		require.Equal(t, 1, version)
+	})
+
+	t.Run("incompatible changes are rejected", func(t *testing.T) {
+		for name, message := range map[string]protoreflect.MessageDescriptor{
+			"type changed": newMessage(t, []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}),
+			"field removed": newMessage(t, []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}),
+		} {
+			_, err := registry.Register(subject, message)
+			require.ErrorIs(t, err, ErrIncompatibleSchema, name)
+		}
+	})
+
+	second := newMessage(t, []*descriptorpb.FieldDescriptorProto{
+		field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		field("currency", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+	}, reserved(2))
+
+	t.Run("removed field with a reserved number is compatible", func(t *testing.T) {
+		version, err := registry.Register(subject, second)
+		require.NoError(t, err)
+		require.Equal(t, 2, version)
+	})
+
+	t.Run("reserved number cannot be reused", func(t *testing.T) {
+		_, err := registry.Register(subject, newMessage(t, []*descriptorpb.FieldDescriptorProto{
+			field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			field("currency", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			field("fee", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+		}))
+		require.ErrorIs(t, err, ErrIncompatibleSchema)
+	})
+
+	t.Run("versions are found by schema", func(t *testing.T) {
+		versions, err := registry.Versions(subject)
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2}, versions)
+
+		version, err := registry.Version(subject, first)
+		require.NoError(t, err)
+		require.Equal(t, 1, version)
+
+		schema, err := registry.Schema(subject, 2)
+		require.NoError(t, err)
+		require.True(t, sameSchema(second, schema))
+
+		_, err = registry.Version(subject, newMessage(t, nil))
+		require.ErrorIs(t, err, ErrSchemaNotRegistered)
+	})
+}
+
+func TestReadOnlyRegistry(t *testing.T) {
+	t.Parallel()
+
+	message := newMessage(t, []*descriptorpb.FieldDescriptorProto{field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)})
+
+	data, err := encodeSchema(message)
+	require.NoError(t, err)
+
+	registry := New(fstest.MapFS{subject + "/1.json": {Data: data}})
+
+	version, err := registry.Version(subject, message)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	_, err = registry.Register(subject, message)
+	require.ErrorIs(t, err, ErrRegistryNotWriteable)
+
+	_, err = registry.Versions("unknown")
+	require.ErrorIs(t, err, ErrSubjectNotFound)
+}
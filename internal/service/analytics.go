@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const rollupLag = 10 * time.Minute
+
+// RunRollups keeps the analytics rollup tables up to date with committed transactions.
+func (s *Service) RunRollups(ctx context.Context) error {
+	if s.cfg.RollupPeriod <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.cfg.RollupPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := s.walletStore.RefreshRollups(ctx, rollupLag); err != nil {
+			log.Warn().Err(err).Msg("failed to refresh analytics rollups")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+//nolint:lll
+func (s *Service) GetAnalytics(ctx context.Context, request models.AnalyticsRequest, userID models.UserID) (models.AnalyticsReport, error) {
+	if err := request.Validate(); err != nil {
+		return models.AnalyticsReport{}, err //nolint:wrapcheck
+	}
+
+	if request.WalletID != nil {
+		if _, err := s.walletStore.GetWallet(ctx, *request.WalletID, userID); err != nil {
+			return models.AnalyticsReport{}, fmt.Errorf("wallet not found: %w", err)
+		}
+	}
+
+	buckets, err := s.walletStore.GetAnalytics(ctx, request, userID)
+	if err != nil {
+		return models.AnalyticsReport{}, fmt.Errorf("error getting analytics: %w", err)
+	}
+
+	report := models.AnalyticsReport{
+		Granularity: request.Granularity,
+		WalletID:    request.WalletID,
+		Buckets:     buckets,
+	}
+
+	if request.ReportingCurrency == "" {
+		return report, nil
+	}
+
+	report.ReportingCurrency = strings.ToUpper(request.ReportingCurrency)
+	report.Total = &models.Amounts{}
+
+	rates := make(map[string]float64)
+
+	for i := range report.Buckets {
+		bucket := &report.Buckets[i]
+
+		rate, ok := rates[bucket.Currency]
+		if !ok {
+			quote, err := s.quoteRate(ctx, strings.ToUpper(bucket.Currency), report.ReportingCurrency)
+			if err != nil {
+				return models.AnalyticsReport{}, err
+			}
+
+			rate = quote.Rate
+			rates[bucket.Currency] = rate
+		}
+
+		bucket.Converted = &models.Amounts{
+			Inflow:  bucket.Inflow * rate,
+			Outflow: bucket.Outflow * rate,
+			Net:     bucket.Net * rate,
+		}
+
+		report.Total.Inflow += bucket.Converted.Inflow
+		report.Total.Outflow += bucket.Converted.Outflow
+		report.Total.Net += bucket.Converted.Net
+	}
+
+	return report, nil
+}
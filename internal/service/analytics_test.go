@@ -0,0 +1,135 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestGetAnalytics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+	walletID := models.WalletID(uuid.New())
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	wednesday := time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC)
+	sundayEvening := time.Date(2026, 10, 18, 18, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	nextMonday := monday.AddDate(0, 0, 7)
+	saturday := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	sunday := saturday.AddDate(0, 0, 1)
+
+	buckets := []models.AnalyticsBucket{
+		{PeriodStart: day, Type: "deposit", Currency: "USD", Inflow: 100, Net: 100, Count: 1},
+		{PeriodStart: day, Type: "withdraw", Currency: "USD", Outflow: 40, Net: -40, Count: 2},
+		{PeriodStart: day, Type: "deposit", Currency: "EUR", Inflow: 10, Net: 10, Count: 1},
+	}
+
+	tests := []struct {
+		name          string
+		request       models.AnalyticsRequest
+		setupMocks    func(*mocks.MockwalletStore, *mocks.MockxrClient)
+		expectedTotal *models.Amounts
+		expectedErr   error
+	}{
+		{
+			name:    "without reporting currency",
+			request: models.AnalyticsRequest{},
+			setupMocks: func(ws *mocks.MockwalletStore, _ *mocks.MockxrClient) {
+				ws.EXPECT().GetAnalytics(ctx, models.AnalyticsRequest{Granularity: models.GranularityDay}, userID).Return(append([]models.AnalyticsBucket(nil), buckets...), nil)
+			},
+		},
+		{
+			name: "bounds widened to whole periods in UTC",
+			request: models.AnalyticsRequest{
+				Granularity: models.GranularityWeek,
+				From:        &wednesday,
+				To:          &sundayEvening,
+			},
+			setupMocks: func(ws *mocks.MockwalletStore, _ *mocks.MockxrClient) {
+				ws.EXPECT().GetAnalytics(ctx, models.AnalyticsRequest{
+					Granularity: models.GranularityWeek,
+					From:        &monday,
+					To:          &nextMonday,
+				}, userID).Return(append([]models.AnalyticsBucket(nil), buckets...), nil)
+			},
+		},
+		{
+			name: "day bound on a boundary stays exclusive",
+			request: models.AnalyticsRequest{
+				From: &saturday,
+				To:   &sunday,
+			},
+			setupMocks: func(ws *mocks.MockwalletStore, _ *mocks.MockxrClient) {
+				ws.EXPECT().GetAnalytics(ctx, models.AnalyticsRequest{
+					Granularity: models.GranularityDay,
+					From:        &saturday,
+					To:          &sunday,
+				}, userID).Return(append([]models.AnalyticsBucket(nil), buckets...), nil)
+			},
+		},
+		{
+			name:    "converted with one rate lookup per currency",
+			request: models.AnalyticsRequest{Granularity: models.GranularityMonth, ReportingCurrency: "eur"},
+			setupMocks: func(ws *mocks.MockwalletStore, xr *mocks.MockxrClient) {
+				ws.EXPECT().GetAnalytics(ctx, gomock.Any(), userID).Return(append([]models.AnalyticsBucket(nil), buckets...), nil)
+				xr.EXPECT().GetRate(ctx, "USD", "EUR").Return(0.5, nil).Times(1)
+			},
+			expectedTotal: &models.Amounts{Inflow: 60, Outflow: 20, Net: 40},
+		},
+		{
+			name:    "wallet of another user",
+			request: models.AnalyticsRequest{WalletID: &walletID},
+			setupMocks: func(ws *mocks.MockwalletStore, _ *mocks.MockxrClient) {
+				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{}, models.ErrWalletNotFound)
+			},
+			expectedErr: models.ErrWalletNotFound,
+		},
+		{
+			name:        "invalid granularity",
+			request:     models.AnalyticsRequest{Granularity: "year"},
+			setupMocks:  func(*mocks.MockwalletStore, *mocks.MockxrClient) {},
+			expectedErr: models.ErrInvalidGranularity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWalletStore := mocks.NewMockwalletStore(ctrl)
+			mockXRClient := mocks.NewMockxrClient(ctrl)
+
+			tt.setupMocks(mockWalletStore, mockXRClient)
+
+			svc := &Service{
+				walletStore: mockWalletStore,
+				xrClient:    mockXRClient,
+				metrics:     getTestMetrics(),
+			}
+
+			report, err := svc.GetAnalytics(ctx, tt.request, userID)
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, report.Buckets, len(buckets))
+			require.Equal(t, tt.expectedTotal, report.Total)
+		})
+	}
+}
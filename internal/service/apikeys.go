@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const (
+	apiKeySecretPrefix      = "wsk_"
+	apiKeySecretBytes       = 32
+	defaultSignatureMaxSkew = 5 * time.Minute
+)
+
+// CreateAPIKey creates a key acting on behalf of the caller. A caller holding a scoped token can
+// grant the key only scopes of its own.
+//
+//nolint:lll
+func (s *Service) CreateAPIKey(ctx context.Context, key models.APIKey, userInfo models.UserInfo) (models.APIKeyCredentials, error) {
+	if err := key.Validate(); err != nil {
+		return models.APIKeyCredentials{}, err //nolint:wrapcheck
+	}
+
+	if err := checkAPIKeyScopes(key, userInfo); err != nil {
+		return models.APIKeyCredentials{}, err
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return models.APIKeyCredentials{}, err
+	}
+
+	key.UserID = userInfo.UserID
+
+	created, err := s.walletStore.CreateAPIKey(ctx, key, models.APIKeySigningKey(secret))
+	if err != nil {
+		return models.APIKeyCredentials{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return models.APIKeyCredentials{APIKey: created, Secret: secret}, nil
+}
+
+func (s *Service) GetAPIKeys(ctx context.Context, userID models.UserID) ([]models.APIKey, error) {
+	keys, err := s.walletStore.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RotateAPIKey replaces the secret of an active key. Requests signed with the old secret
+// are rejected from then on. As with creation, a caller holding a scoped token can rotate
+// only keys whose scopes it holds itself.
+//
+//nolint:lll
+func (s *Service) RotateAPIKey(ctx context.Context, keyID models.APIKeyID, userInfo models.UserInfo) (models.APIKeyCredentials, error) {
+	existing, err := s.walletStore.GetAPIKey(ctx, keyID, userInfo.UserID)
+	if err != nil {
+		return models.APIKeyCredentials{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	if err = checkAPIKeyScopes(existing, userInfo); err != nil {
+		return models.APIKeyCredentials{}, err
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return models.APIKeyCredentials{}, err
+	}
+
+	key, err := s.walletStore.RotateAPIKey(ctx, keyID, userInfo.UserID, models.APIKeySigningKey(secret))
+	if err != nil {
+		return models.APIKeyCredentials{}, fmt.Errorf("failed to rotate api key: %w", err)
+	}
+
+	return models.APIKeyCredentials{APIKey: key, Secret: secret}, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) error {
+	if err := s.walletStore.RevokeAPIKey(ctx, keyID, userID); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey verifies the signature of a request made with an API key and returns
+// the identity of the key owner restricted to the key scopes. Each signature is accepted once
+// and only while its timestamp is within the allowed clock skew.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, request models.SignedRequest) (models.UserInfo, error) {
+	maxSkew := s.cfg.SignatureMaxSkew
+	if maxSkew <= 0 {
+		maxSkew = defaultSignatureMaxSkew
+	}
+
+	if skew := time.Since(request.Timestamp).Abs(); skew > maxSkew {
+		return models.UserInfo{}, models.ErrInvalidSignature
+	}
+
+	key, signingKey, err := s.walletStore.GetAPIKeySecret(ctx, request.KeyID)
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			return models.UserInfo{}, models.ErrInvalidSignature
+		}
+
+		return models.UserInfo{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	if !hmac.Equal([]byte(request.Sign(signingKey)), []byte(request.Signature)) {
+		return models.UserInfo{}, models.ErrInvalidSignature
+	}
+
+	fresh, err := s.walletStore.RecordAPIKeySignature(ctx, key.KeyID, request.Signature, request.Timestamp.Add(maxSkew))
+	if err != nil {
+		return models.UserInfo{}, fmt.Errorf("failed to record api key signature: %w", err)
+	}
+
+	if !fresh {
+		return models.UserInfo{}, models.ErrReplayedRequest
+	}
+
+	return models.UserInfo{
+		UserID:   key.UserID,
+		Role:     models.RoleService,
+		Scopes:   key.Scopes,
+		APIKeyID: &key.KeyID,
+	}, nil
+}
+
+// checkAPIKeyScopes rejects a key granting scopes the caller does not hold.
+func checkAPIKeyScopes(key models.APIKey, userInfo models.UserInfo) error {
+	for _, scope := range key.Scopes {
+		if !userInfo.HasScope(scope) {
+			return fmt.Errorf("%w: %s", models.ErrMissingScope, scope)
+		}
+	}
+
+	return nil
+}
+
+func newAPIKeySecret() (string, error) {
+	buf := make([]byte, apiKeySecretBytes)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key secret: %w", err)
+	}
+
+	return apiKeySecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,244 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	secret := "wsk_test-secret"
+	key := models.APIKey{
+		KeyID:  models.APIKeyID(uuid.New()),
+		UserID: models.UserID(uuid.New()),
+		Name:   "payouts",
+		Scopes: []string{models.ScopeTransactionsWrite},
+	}
+
+	signed := func(timestamp time.Time, signingSecret string) models.SignedRequest {
+		request := models.SignedRequest{
+			KeyID:     key.KeyID,
+			Timestamp: timestamp,
+			Method:    "PUT",
+			URI:       "/api/v1/wallets/123/deposit",
+			Body:      []byte(`{"amount":10}`),
+		}
+		request.Signature = request.Sign(models.APIKeySigningKey(signingSecret))
+
+		return request
+	}
+
+	tests := []struct {
+		name        string
+		request     models.SignedRequest
+		mockSetup   func(ws *mocks.MockwalletStore)
+		expectedErr error
+	}{
+		{
+			name:    "valid signature",
+			request: signed(time.Now(), secret),
+			mockSetup: func(ws *mocks.MockwalletStore) {
+				ws.EXPECT().GetAPIKeySecret(ctx, key.KeyID).Return(key, models.APIKeySigningKey(secret), nil)
+				ws.EXPECT().RecordAPIKeySignature(ctx, key.KeyID, gomock.Any(), gomock.Any()).Return(true, nil)
+			},
+		},
+		{
+			name:    "wrong secret",
+			request: signed(time.Now(), "wsk_other"),
+			mockSetup: func(ws *mocks.MockwalletStore) {
+				ws.EXPECT().GetAPIKeySecret(ctx, key.KeyID).Return(key, models.APIKeySigningKey(secret), nil)
+			},
+			expectedErr: models.ErrInvalidSignature,
+		},
+		{
+			name:        "stale timestamp",
+			request:     signed(time.Now().Add(-time.Hour), secret),
+			mockSetup:   func(*mocks.MockwalletStore) {},
+			expectedErr: models.ErrInvalidSignature,
+		},
+		{
+			name:    "revoked key",
+			request: signed(time.Now(), secret),
+			mockSetup: func(ws *mocks.MockwalletStore) {
+				ws.EXPECT().GetAPIKeySecret(ctx, key.KeyID).Return(models.APIKey{}, nil, models.ErrAPIKeyNotFound)
+			},
+			expectedErr: models.ErrInvalidSignature,
+		},
+		{
+			name:    "replayed request",
+			request: signed(time.Now(), secret),
+			mockSetup: func(ws *mocks.MockwalletStore) {
+				ws.EXPECT().GetAPIKeySecret(ctx, key.KeyID).Return(key, models.APIKeySigningKey(secret), nil)
+				ws.EXPECT().RecordAPIKeySignature(ctx, key.KeyID, gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+			expectedErr: models.ErrReplayedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWalletStore := mocks.NewMockwalletStore(ctrl)
+			tt.mockSetup(mockWalletStore)
+
+			svc := &Service{walletStore: mockWalletStore, metrics: getTestMetrics()}
+
+			userInfo, err := svc.AuthenticateAPIKey(ctx, tt.request)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, key.UserID, userInfo.UserID)
+			require.Equal(t, models.RoleService, userInfo.Role)
+			require.Equal(t, key.Scopes, userInfo.Scopes)
+		})
+	}
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+
+	tests := []struct {
+		name         string
+		scopes       []string
+		callerScopes []string
+		expectedErr  error
+	}{
+		{
+			name:   "unscoped caller",
+			scopes: []string{models.ScopeWalletsWrite, models.ScopeTransactionsWrite},
+		},
+		{
+			name:         "scopes held by the caller",
+			scopes:       []string{models.ScopeTransactionsRead},
+			callerScopes: []string{models.ScopeWalletsWrite, models.ScopeTransactionsRead},
+		},
+		{
+			name:         "scope the caller does not hold",
+			scopes:       []string{models.ScopeWalletsRead, models.ScopeTransactionsWrite},
+			callerScopes: []string{models.ScopeWalletsWrite, models.ScopeWalletsRead},
+			expectedErr:  models.ErrMissingScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWalletStore := mocks.NewMockwalletStore(ctrl)
+
+			if tt.expectedErr == nil {
+				mockWalletStore.EXPECT().CreateAPIKey(ctx, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key models.APIKey, _ []byte) (models.APIKey, error) {
+						require.Equal(t, userID, key.UserID)
+
+						return key, nil
+					})
+			}
+
+			svc := &Service{walletStore: mockWalletStore}
+
+			credentials, err := svc.CreateAPIKey(ctx, models.APIKey{Name: "payouts", Scopes: tt.scopes},
+				models.UserInfo{UserID: userID, Scopes: tt.callerScopes})
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, credentials.Secret)
+		})
+	}
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+
+	tests := []struct {
+		name         string
+		keyScopes    []string
+		callerScopes []string
+		expectedErr  error
+	}{
+		{
+			name:      "unscoped caller",
+			keyScopes: []string{models.ScopeWalletsWrite, models.ScopeTransactionsWrite},
+		},
+		{
+			name:         "key scopes held by the caller",
+			keyScopes:    []string{models.ScopeTransactionsRead},
+			callerScopes: []string{models.ScopeWalletsWrite, models.ScopeTransactionsRead},
+		},
+		{
+			name:         "key broader than the caller",
+			keyScopes:    []string{models.ScopeWalletsWrite, models.ScopeTransactionsWrite},
+			callerScopes: []string{models.ScopeWalletsWrite},
+			expectedErr:  models.ErrMissingScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWalletStore := mocks.NewMockwalletStore(ctrl)
+
+			key := models.APIKey{
+				KeyID:  models.APIKeyID(uuid.New()),
+				UserID: userID,
+				Name:   "payouts",
+				Scopes: tt.keyScopes,
+			}
+
+			mockWalletStore.EXPECT().GetAPIKey(ctx, key.KeyID, userID).Return(key, nil)
+
+			if tt.expectedErr == nil {
+				mockWalletStore.EXPECT().RotateAPIKey(ctx, key.KeyID, userID, gomock.Any()).Return(key, nil)
+			}
+
+			svc := &Service{walletStore: mockWalletStore}
+
+			credentials, err := svc.RotateAPIKey(ctx, key.KeyID, models.UserInfo{UserID: userID, Scopes: tt.callerScopes})
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, credentials.Secret)
+		})
+	}
+}
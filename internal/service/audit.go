@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Service) RecordAudit(ctx context.Context, record models.AuditRecord) error {
+	if err := s.walletStore.InsertAuditRecord(ctx, record); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetAuditRecords(ctx context.Context, request models.AuditQuery) ([]models.AuditRecord, error) {
+	records, err := s.walletStore.GetAuditRecords(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit records: %w", err)
+	}
+
+	return records, nil
+}
+
+func (s *Service) purgeAuditRecords(ctx context.Context) error {
+	if s.cfg.AuditRetention <= 0 {
+		return nil
+	}
+
+	deleted, err := s.walletStore.PurgeAuditRecords(ctx, s.cfg.AuditRetention)
+	if err != nil {
+		return fmt.Errorf("failed to purge audit records: %w", err)
+	}
+
+	log.Debug().Int64("deleted", deleted).Msg("expired audit records purged")
+
+	return nil
+}
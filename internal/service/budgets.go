@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const percent = 100
+
+func (s *Service) CreateBudget(ctx context.Context, budget models.Budget, userID models.UserID) (models.Budget, error) {
+	if err := budget.Validate(); err != nil {
+		return models.Budget{}, err //nolint:wrapcheck
+	}
+
+	budget, err := s.walletStore.CreateBudget(ctx, budget, userID)
+	if err != nil {
+		return models.Budget{}, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return budget, nil
+}
+
+func (s *Service) DeleteBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) error {
+	if err := s.walletStore.DeleteBudget(ctx, budgetID, userID); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (s *Service) GetBudgetProgress(ctx context.Context, budgetID models.BudgetID, userID models.UserID) (models.BudgetProgress, error) {
+	budget, err := s.walletStore.GetBudget(ctx, budgetID, userID)
+	if err != nil {
+		return models.BudgetProgress{}, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	return s.budgetProgress(ctx, budget, time.Now())
+}
+
+func (s *Service) GetBudgetsProgress(ctx context.Context, userID models.UserID) ([]models.BudgetProgress, error) {
+	budgets, err := s.walletStore.GetBudgets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting budgets: %w", err)
+	}
+
+	now := time.Now()
+	progress := make([]models.BudgetProgress, 0, len(budgets))
+
+	for _, budget := range budgets {
+		p, err := s.budgetProgress(ctx, budget, now)
+		if err != nil {
+			return nil, err
+		}
+
+		progress = append(progress, p)
+	}
+
+	return progress, nil
+}
+
+// budgetProgress sums the spending of the current period converting every currency
+// into the budget currency.
+func (s *Service) budgetProgress(ctx context.Context, budget models.Budget, now time.Time) (models.BudgetProgress, error) {
+	start, end := budget.PeriodBounds(now)
+
+	amounts, err := s.walletStore.GetBudgetSpending(ctx, budget, start, end)
+	if err != nil {
+		return models.BudgetProgress{}, fmt.Errorf("error getting budget spending: %w", err)
+	}
+
+	progress := models.BudgetProgress{
+		Budget:      budget,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Crossed:     make([]int, 0, len(budget.Thresholds)),
+	}
+
+	for _, amount := range amounts {
+		quote, err := s.quoteRate(ctx, amount.Currency, budget.Currency)
+		if err != nil {
+			return models.BudgetProgress{}, err
+		}
+
+		progress.Spent += amount.Amount * quote.Rate
+	}
+
+	progress.Remaining = budget.Amount - progress.Spent
+	progress.Percent = progress.Spent / budget.Amount * percent
+
+	for _, threshold := range budget.Thresholds {
+		if progress.Percent >= float64(threshold) {
+			progress.Crossed = append(progress.Crossed, threshold)
+		}
+	}
+
+	return progress, nil
+}
+
+// evaluateWalletBudgets evaluates the budgets of every member of the debited wallet, as a budget
+// counts the spending of all the members of the wallets its owner shares.
+func (s *Service) evaluateWalletBudgets(ctx context.Context, walletID *models.WalletID) {
+	if walletID == nil {
+		return
+	}
+
+	members, err := s.walletStore.GetWalletMembers(ctx, *walletID)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load wallet members for budget evaluation")
+
+		return
+	}
+
+	for _, member := range members {
+		s.evaluateBudgets(ctx, member.UserID)
+	}
+}
+
+// evaluateBudgets raises an alert for every budget threshold crossed by the spending of the
+// user in the current period. Each threshold alerts at most once per period. Evaluation runs
+// after the transaction has committed, so failures are logged and never fail the transaction.
+func (s *Service) evaluateBudgets(ctx context.Context, userID models.UserID) {
+	budgets, err := s.walletStore.GetBudgets(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load budgets for evaluation")
+
+		return
+	}
+
+	now := time.Now()
+
+	for _, budget := range budgets {
+		progress, err := s.budgetProgress(ctx, budget, now)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to evaluate budget")
+
+			continue
+		}
+
+		for _, threshold := range progress.Crossed {
+			s.raiseBudgetAlert(ctx, progress, threshold, now)
+		}
+	}
+}
+
+func (s *Service) raiseBudgetAlert(ctx context.Context, progress models.BudgetProgress, threshold int, now time.Time) {
+	alert := models.BudgetAlert{
+		BudgetID:    progress.Budget.BudgetID,
+		UserID:      progress.Budget.UserID,
+		Name:        progress.Budget.Name,
+		Threshold:   threshold,
+		PeriodStart: progress.PeriodStart,
+		PeriodEnd:   progress.PeriodEnd,
+		Spent:       progress.Spent,
+		Amount:      progress.Budget.Amount,
+		Currency:    progress.Budget.Currency,
+		CreatedAt:   now,
+	}
+
+	// The alert reaches the streams of the owner as an event recorded with it.
+	raised, err := s.walletStore.RecordBudgetAlert(ctx, alert)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to record budget alert")
+
+		return
+	}
+
+	if !raised {
+		return
+	}
+
+	if err := s.producer.ProduceBudgetAlert(alert); err != nil {
+		log.Warn().Err(err).Msg("failed to produce budget alert")
+	}
+}
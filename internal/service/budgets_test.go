@@ -0,0 +1,97 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateBudgets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+	walletID := models.WalletID(uuid.New())
+
+	budget := models.Budget{
+		BudgetID:   models.BudgetID(uuid.New()),
+		UserID:     userID,
+		Name:       "groceries",
+		WalletID:   &walletID,
+		Period:     models.BudgetPeriodMonthly,
+		Amount:     100,
+		Currency:   "USD",
+		Thresholds: []int{50, 80, 100},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWalletStore := mocks.NewMockwalletStore(ctrl)
+	mockXRClient := mocks.NewMockxrClient(ctrl)
+	mockTxProducer := mocks.NewMocktxProducer(ctrl)
+
+	mockWalletStore.EXPECT().GetBudgets(ctx, userID).Return([]models.Budget{budget}, nil)
+	mockWalletStore.EXPECT().GetBudgetSpending(ctx, budget, gomock.Any(), gomock.Any()).Return([]models.CurrencyAmount{
+		{Currency: "USD", Amount: 40},
+		{Currency: "EUR", Amount: 20},
+	}, nil)
+	mockXRClient.EXPECT().GetRate(ctx, "EUR", "USD").Return(2.0, nil)
+
+	// 80% was already raised earlier in the period, only 50% is new.
+	mockWalletStore.EXPECT().RecordBudgetAlert(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, alert models.BudgetAlert) (bool, error) {
+			require.InDelta(t, 80.0, alert.Spent, 1e-9)
+
+			return alert.Threshold == 50, nil
+		},
+	).Times(2)
+	mockTxProducer.EXPECT().ProduceBudgetAlert(gomock.Any()).DoAndReturn(func(alert models.BudgetAlert) error {
+		require.Equal(t, 50, alert.Threshold)
+		require.Equal(t, budget.BudgetID, alert.BudgetID)
+
+		return nil
+	})
+
+	svc := &Service{
+		walletStore: mockWalletStore,
+		xrClient:    mockXRClient,
+		producer:    mockTxProducer,
+		metrics:     getTestMetrics(),
+	}
+
+	svc.evaluateBudgets(ctx, userID)
+}
+
+func TestEvaluateWalletBudgets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ownerID := models.UserID(uuid.New())
+	spenderID := models.UserID(uuid.New())
+	walletID := models.WalletID(uuid.New())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWalletStore := mocks.NewMockwalletStore(ctrl)
+
+	// The owner's budgets are evaluated although the spender moved the money.
+	mockWalletStore.EXPECT().GetWalletMembers(ctx, walletID).Return([]models.WalletMember{
+		{WalletID: walletID, UserID: ownerID, Role: models.MemberRoleOwner},
+		{WalletID: walletID, UserID: spenderID, Role: models.MemberRoleSpender},
+	}, nil)
+	mockWalletStore.EXPECT().GetBudgets(ctx, ownerID).Return(nil, nil)
+	mockWalletStore.EXPECT().GetBudgets(ctx, spenderID).Return(nil, nil)
+
+	svc := &Service{walletStore: mockWalletStore}
+
+	svc.evaluateWalletBudgets(ctx, &walletID)
+	svc.evaluateWalletBudgets(ctx, nil)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (s *Service) CreateCategory(ctx context.Context, category models.Category, userID models.UserID) (models.Category, error) {
+	if err := category.Validate(); err != nil {
+		return models.Category{}, err //nolint:wrapcheck
+	}
+
+	category, err := s.walletStore.CreateCategory(ctx, category, userID)
+	if err != nil {
+		return models.Category{}, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+func (s *Service) GetCategories(ctx context.Context, userID models.UserID) ([]models.Category, error) {
+	categories, err := s.walletStore.GetCategories(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (s *Service) DeleteCategory(ctx context.Context, categoryID models.CategoryID, userID models.UserID) error {
+	if err := s.walletStore.DeleteCategory(ctx, categoryID, userID); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (s *Service) CreateCategoryRule(ctx context.Context, rule models.CategoryRule, userID models.UserID) (models.CategoryRule, error) {
+	if err := rule.Validate(); err != nil {
+		return models.CategoryRule{}, err //nolint:wrapcheck
+	}
+
+	rule, err := s.walletStore.CreateCategoryRule(ctx, rule, userID)
+	if err != nil {
+		return models.CategoryRule{}, fmt.Errorf("failed to create category rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *Service) GetCategoryRules(ctx context.Context, userID models.UserID) ([]models.CategoryRule, error) {
+	rules, err := s.walletStore.GetCategoryRules(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting category rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (s *Service) DeleteCategoryRule(ctx context.Context, ruleID models.RuleID, userID models.UserID) error {
+	if err := s.walletStore.DeleteCategoryRule(ctx, ruleID, userID); err != nil {
+		return fmt.Errorf("failed to delete category rule: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (s *Service) SetTransactionCategory(ctx context.Context, txID models.TxID, walletID models.WalletID, categoryID *models.CategoryID, userID models.UserID) (models.Transaction, error) {
+	transaction, err := s.walletStore.SetTransactionCategory(ctx, txID, walletID, categoryID, userID)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to set transaction category: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func (s *Service) ApplyCategoryRules(ctx context.Context, userID models.UserID, force bool) (models.ApplyRulesResult, error) {
+	updated, err := s.walletStore.ApplyCategoryRules(ctx, userID, force)
+	if err != nil {
+		return models.ApplyRulesResult{}, fmt.Errorf("failed to apply category rules: %w", err)
+	}
+
+	return models.ApplyRulesResult{Updated: updated}, nil
+}
+
+//nolint:lll
+func (s *Service) GetCategoryTotals(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.CategoryTotal, error) {
+	totals, err := s.walletStore.GetCategoryTotals(ctx, request, walletID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting category totals: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// complianceLag is how far behind the watermark each scan starts again, so that transactions
+// committed while the previous scan ran are not missed.
+const complianceLag = 10 * time.Minute
+
+// RunCompliance opens compliance cases for newly committed transactions.
+func (s *Service) RunCompliance(ctx context.Context) error {
+	if s.cfg.ComplianceScanPeriod <= 0 || len(s.cfg.ComplianceRules.Thresholds) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.cfg.ComplianceScanPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := s.scanCompliance(ctx, time.Now()); err != nil {
+			log.Warn().Err(err).Msg("failed to scan transactions for compliance cases")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) scanCompliance(ctx context.Context, now time.Time) error {
+	rules := s.cfg.ComplianceRules
+
+	minAmounts := make(map[string]float64, len(rules.Thresholds))
+
+	for currency, threshold := range rules.Thresholds {
+		if threshold > 0 {
+			minAmounts[currency] = threshold * (1 - rules.StructuringMargin)
+		}
+	}
+
+	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
+		watermark, err := s.walletStore.LockComplianceWatermark(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to lock compliance watermark: %w", err)
+		}
+
+		since := now.Add(-rules.StructuringWindow)
+		if watermark != nil && watermark.Add(-complianceLag).Before(since) {
+			since = watermark.Add(-complianceLag)
+		}
+
+		candidates, err := s.walletStore.GetComplianceCandidates(ctx, since, minAmounts)
+		if err != nil {
+			return fmt.Errorf("failed to get compliance candidates: %w", err)
+		}
+
+		for _, complianceCase := range detectCases(candidates, rules, now) {
+			complianceCase.CaseID = models.CaseID(uuid.New())
+			complianceCase.Status = models.CaseStatusOpen
+
+			created, err := s.walletStore.CreateComplianceCase(ctx, complianceCase)
+			if err != nil {
+				return fmt.Errorf("failed to create compliance case: %w", err)
+			}
+
+			log.Info().
+				Str("caseId", uuid.UUID(created.CaseID).String()).
+				Str("kind", created.Kind).
+				Int("transactions", len(created.Transactions)).
+				Msg("compliance case opened")
+		}
+
+		return s.walletStore.SetComplianceWatermark(ctx, now) //nolint:wrapcheck
+	}); err != nil {
+		return fmt.Errorf("error in DoWithTX(): %w", err)
+	}
+
+	return nil
+}
+
+// detectCases opens a case for each transaction at or above the threshold of its currency and
+// one for each user and currency with enough deposits just below the threshold within the
+// structuring window. Candidates are ordered by commit time.
+func detectCases(candidates []models.Transaction, rules models.ComplianceRules, now time.Time) []models.ComplianceCase {
+	type structuringKey struct {
+		userID   models.UserID
+		currency string
+	}
+
+	var (
+		cases       []models.ComplianceCase
+		structuring = make(map[structuringKey][]models.Transaction)
+		keys        []structuringKey
+	)
+
+	for _, t := range candidates {
+		threshold, ok := rules.Threshold(t.Currency)
+		if !ok || t.PerformedBy == nil {
+			continue
+		}
+
+		currency := strings.ToUpper(t.Currency)
+
+		switch {
+		case t.Amount >= threshold:
+			cases = append(cases, models.ComplianceCase{
+				UserID:       *t.PerformedBy,
+				Kind:         models.CaseKindLargeTransaction,
+				Currency:     currency,
+				Amount:       t.Amount,
+				Transactions: []models.Transaction{t},
+			})
+		case t.Type == "deposit" && !t.CommittedAt.Before(now.Add(-rules.StructuringWindow)):
+			key := structuringKey{userID: *t.PerformedBy, currency: currency}
+
+			if _, ok := structuring[key]; !ok {
+				keys = append(keys, key)
+			}
+
+			structuring[key] = append(structuring[key], t)
+		}
+	}
+
+	for _, key := range keys {
+		deposits := structuring[key]
+
+		if rules.StructuringCount <= 0 || len(deposits) < rules.StructuringCount {
+			continue
+		}
+
+		var total float64
+
+		for _, t := range deposits {
+			total += t.Amount
+		}
+
+		cases = append(cases, models.ComplianceCase{
+			UserID:       key.userID,
+			Kind:         models.CaseKindStructuring,
+			Currency:     key.currency,
+			Amount:       total,
+			Transactions: deposits,
+		})
+	}
+
+	return cases
+}
+
+func (s *Service) GetComplianceCases(ctx context.Context, status string) ([]models.ComplianceCase, error) {
+	cases, err := s.walletStore.GetComplianceCases(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting compliance cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+func (s *Service) GetComplianceCase(ctx context.Context, caseID models.CaseID) (models.ComplianceCase, error) {
+	complianceCase, err := s.walletStore.GetComplianceCase(ctx, caseID)
+	if err != nil {
+		return models.ComplianceCase{}, fmt.Errorf("error getting compliance case: %w", err)
+	}
+
+	return complianceCase, nil
+}
+
+// UpdateComplianceCase moves a case along the status workflow.
+//
+//nolint:lll
+func (s *Service) UpdateComplianceCase(ctx context.Context, caseID models.CaseID, update models.CaseUpdate) (models.ComplianceCase, error) {
+	if !models.ValidCaseStatus(update.Status) {
+		return models.ComplianceCase{}, &models.ValidationError{Field: "status", Err: models.ErrInvalidCaseStatus}
+	}
+
+	complianceCase, err := s.walletStore.GetComplianceCase(ctx, caseID)
+	if err != nil {
+		return models.ComplianceCase{}, fmt.Errorf("error getting compliance case: %w", err)
+	}
+
+	if !complianceCase.CanMoveTo(update.Status) {
+		return models.ComplianceCase{}, fmt.Errorf("%w: from %s to %s", models.ErrInvalidCaseTransition, complianceCase.Status, update.Status)
+	}
+
+	if err = s.walletStore.UpdateComplianceCaseStatus(ctx, caseID, complianceCase.Status, update.Status); err != nil {
+		return models.ComplianceCase{}, fmt.Errorf("failed to update compliance case: %w", err)
+	}
+
+	return s.GetComplianceCase(ctx, caseID)
+}
+
+func (s *Service) AddComplianceCaseNote(ctx context.Context, note models.CaseNote) (models.CaseNote, error) {
+	if err := note.Validate(); err != nil {
+		return models.CaseNote{}, err //nolint:wrapcheck
+	}
+
+	created, err := s.walletStore.AddComplianceCaseNote(ctx, note)
+	if err != nil {
+		return models.CaseNote{}, fmt.Errorf("failed to add compliance case note: %w", err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,156 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestDetectCases(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	userID, otherUserID := models.UserID(uuid.New()), models.UserID(uuid.New())
+
+	rules := models.ComplianceRules{
+		Thresholds:        map[string]float64{"USD": 10000},
+		StructuringWindow: 24 * time.Hour,
+		StructuringCount:  3,
+		StructuringMargin: 0.1,
+	}
+
+	tx := func(user models.UserID, txType string, amount float64, age time.Duration) models.Transaction {
+		return models.Transaction{
+			ID:          models.TxID(uuid.New()),
+			Type:        txType,
+			Amount:      amount,
+			Currency:    "usd",
+			CommittedAt: now.Add(-age),
+			PerformedBy: &user,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []models.Transaction
+		expected   map[string][]int
+	}{
+		{
+			name:       "large transactions of any type",
+			candidates: []models.Transaction{tx(userID, "deposit", 10000, time.Hour), tx(userID, "withdraw", 25000, time.Hour)},
+			expected:   map[string][]int{models.CaseKindLargeTransaction: {1, 1}},
+		},
+		{
+			name: "structuring deposits",
+			candidates: []models.Transaction{
+				tx(userID, "deposit", 9500, 3*time.Hour),
+				tx(userID, "deposit", 9900, 2*time.Hour),
+				tx(userID, "deposit", 9100, time.Hour),
+			},
+			expected: map[string][]int{models.CaseKindStructuring: {3}},
+		},
+		{
+			name: "too few deposits within the window",
+			candidates: []models.Transaction{
+				tx(userID, "deposit", 9500, 48*time.Hour),
+				tx(userID, "deposit", 9900, 2*time.Hour),
+				tx(userID, "deposit", 9100, time.Hour),
+			},
+			expected: map[string][]int{},
+		},
+		{
+			name: "deposits of different users are not combined",
+			candidates: []models.Transaction{
+				tx(userID, "deposit", 9500, 3*time.Hour),
+				tx(otherUserID, "deposit", 9900, 2*time.Hour),
+				tx(userID, "deposit", 9100, time.Hour),
+			},
+			expected: map[string][]int{},
+		},
+		{
+			name: "withdrawals below the threshold are not structuring",
+			candidates: []models.Transaction{
+				tx(userID, "withdraw", 9500, 3*time.Hour),
+				tx(userID, "withdraw", 9900, 2*time.Hour),
+				tx(userID, "withdraw", 9100, time.Hour),
+			},
+			expected: map[string][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := make(map[string][]int)
+
+			for _, c := range detectCases(tt.candidates, rules, now) {
+				require.Equal(t, "USD", c.Currency)
+				got[c.Kind] = append(got[c.Kind], len(c.Transactions))
+			}
+
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestUpdateComplianceCase(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	caseID := models.CaseID(uuid.New())
+
+	tests := []struct {
+		name        string
+		from        string
+		to          string
+		expectedErr error
+	}{
+		{name: "open to investigating", from: models.CaseStatusOpen, to: models.CaseStatusInvestigating},
+		{name: "investigating to reported", from: models.CaseStatusInvestigating, to: models.CaseStatusReported},
+		{name: "open to reported", from: models.CaseStatusOpen, to: models.CaseStatusReported, expectedErr: models.ErrInvalidCaseTransition},
+		{name: "closed cases are final", from: models.CaseStatusClosed, to: models.CaseStatusOpen, expectedErr: models.ErrInvalidCaseTransition},
+		{name: "unknown status", from: models.CaseStatusOpen, to: "escalated", expectedErr: models.ErrInvalidCaseStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ws := mocks.NewMockwalletStore(ctrl)
+
+			if models.ValidCaseStatus(tt.to) {
+				ws.EXPECT().GetComplianceCase(ctx, caseID).Return(models.ComplianceCase{CaseID: caseID, Status: tt.from}, nil)
+			}
+
+			if tt.expectedErr == nil {
+				ws.EXPECT().UpdateComplianceCaseStatus(ctx, caseID, tt.from, tt.to).Return(nil)
+				ws.EXPECT().GetComplianceCase(ctx, caseID).Return(models.ComplianceCase{CaseID: caseID, Status: tt.to}, nil)
+			}
+
+			svc := &Service{walletStore: ws}
+
+			updated, err := svc.UpdateComplianceCase(ctx, caseID, models.CaseUpdate{Status: tt.to})
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.to, updated.Status)
+		})
+	}
+}
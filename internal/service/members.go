@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+// authorize checks that the wallet is not frozen and that the membership the wallet was loaded
+// with has one of the roles.
+func authorize(wallet models.Wallet, roles ...string) error {
+	if wallet.FrozenAt != nil {
+		return models.ErrWalletFrozen
+	}
+
+	if wallet.Membership == nil || !slices.Contains(roles, wallet.Membership.Role) {
+		return models.ErrInsufficientRights
+	}
+
+	return nil
+}
+
+// authorizeSpend checks that the member may move the amount, given in the wallet currency.
+func authorizeSpend(wallet models.Wallet, amount float64) error {
+	if err := authorize(wallet, models.MemberRoleOwner, models.MemberRoleSpender); err != nil {
+		return err
+	}
+
+	if !wallet.Membership.WithinLimit(amount) {
+		return models.ErrSpendLimitExceeded
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (s *Service) GetWalletMembers(ctx context.Context, walletID models.WalletID, userID models.UserID) ([]models.WalletMember, error) {
+	if _, err := s.walletStore.GetWallet(ctx, walletID, userID); err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	members, err := s.walletStore.GetWalletMembers(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting wallet members: %w", err)
+	}
+
+	return members, nil
+}
+
+//nolint:lll
+func (s *Service) AddWalletMember(ctx context.Context, member models.WalletMember, userID models.UserID) (models.WalletMember, error) {
+	if err := member.Validate(); err != nil {
+		return models.WalletMember{}, err //nolint:wrapcheck
+	}
+
+	wallet, err := s.walletStore.GetWallet(ctx, member.WalletID, userID)
+	if err != nil {
+		return models.WalletMember{}, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	if err = authorize(wallet, models.MemberRoleOwner); err != nil {
+		return models.WalletMember{}, err
+	}
+
+	member, err = s.walletStore.AddWalletMember(ctx, member)
+	if err != nil {
+		return models.WalletMember{}, fmt.Errorf("failed to add wallet member: %w", err)
+	}
+
+	return member, nil
+}
+
+//nolint:lll
+func (s *Service) UpdateWalletMember(ctx context.Context, member models.WalletMember, userID models.UserID) (models.WalletMember, error) {
+	if err := member.Validate(); err != nil {
+		return models.WalletMember{}, err //nolint:wrapcheck
+	}
+
+	wallet, err := s.walletStore.GetWallet(ctx, member.WalletID, userID)
+	if err != nil {
+		return models.WalletMember{}, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	if err = authorize(wallet, models.MemberRoleOwner); err != nil {
+		return models.WalletMember{}, err
+	}
+
+	member, err = s.walletStore.UpdateWalletMember(ctx, member)
+	if err != nil {
+		return models.WalletMember{}, fmt.Errorf("failed to update wallet member: %w", err)
+	}
+
+	return member, nil
+}
+
+// RemoveWalletMember removes a member from the wallet. Owners may remove anyone,
+// other members may only leave the wallet themselves.
+//
+//nolint:lll
+func (s *Service) RemoveWalletMember(ctx context.Context, walletID models.WalletID, memberID models.UserID, userID models.UserID) error {
+	wallet, err := s.walletStore.GetWallet(ctx, walletID, userID)
+	if err != nil {
+		return fmt.Errorf("wallet not found: %w", err)
+	}
+
+	if memberID != userID {
+		if err = authorize(wallet, models.MemberRoleOwner); err != nil {
+			return err
+		}
+	}
+
+	if err = s.walletStore.RemoveWalletMember(ctx, walletID, memberID); err != nil {
+		return fmt.Errorf("failed to remove wallet member: %w", err)
+	}
+
+	return nil
+}
@@ -36,128 +36,1003 @@ func (m *MockwalletStore) EXPECT() *MockwalletStoreMockRecorder {
 	return m.recorder
 }
 
+// AddComplianceCaseNote mocks base method.
+func (m *MockwalletStore) AddComplianceCaseNote(ctx context.Context, note models.CaseNote) (models.CaseNote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddComplianceCaseNote", ctx, note)
+	ret0, _ := ret[0].(models.CaseNote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddComplianceCaseNote indicates an expected call of AddComplianceCaseNote.
+func (mr *MockwalletStoreMockRecorder) AddComplianceCaseNote(ctx, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddComplianceCaseNote", reflect.TypeOf((*MockwalletStore)(nil).AddComplianceCaseNote), ctx, note)
+}
+
+// AddWalletMember mocks base method.
+func (m *MockwalletStore) AddWalletMember(ctx context.Context, member models.WalletMember) (models.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWalletMember", ctx, member)
+	ret0, _ := ret[0].(models.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWalletMember indicates an expected call of AddWalletMember.
+func (mr *MockwalletStoreMockRecorder) AddWalletMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWalletMember", reflect.TypeOf((*MockwalletStore)(nil).AddWalletMember), ctx, member)
+}
+
+// ApplyCategoryRules mocks base method.
+func (m *MockwalletStore) ApplyCategoryRules(ctx context.Context, userID models.UserID, force bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyCategoryRules", ctx, userID, force)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyCategoryRules indicates an expected call of ApplyCategoryRules.
+func (mr *MockwalletStoreMockRecorder) ApplyCategoryRules(ctx, userID, force interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyCategoryRules", reflect.TypeOf((*MockwalletStore)(nil).ApplyCategoryRules), ctx, userID, force)
+}
+
 // ArchiveStaleWallets mocks base method.
 func (m *MockwalletStore) ArchiveStaleWallets(ctx context.Context, checkPeriod time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ArchiveStaleWallets", ctx, checkPeriod)
+	ret := m.ctrl.Call(m, "ArchiveStaleWallets", ctx, checkPeriod)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveStaleWallets indicates an expected call of ArchiveStaleWallets.
+func (mr *MockwalletStoreMockRecorder) ArchiveStaleWallets(ctx, checkPeriod interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveStaleWallets", reflect.TypeOf((*MockwalletStore)(nil).ArchiveStaleWallets), ctx, checkPeriod)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockwalletStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDispatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, limit, lease)
+	ret0, _ := ret[0].([]models.WebhookDispatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockwalletStoreMockRecorder) ClaimWebhookDeliveries(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockwalletStore)(nil).ClaimWebhookDeliveries), ctx, limit, lease)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockwalletStore) CreateAPIKey(ctx context.Context, key models.APIKey, signingKey []byte) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key, signingKey)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockwalletStoreMockRecorder) CreateAPIKey(ctx, key, signingKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockwalletStore)(nil).CreateAPIKey), ctx, key, signingKey)
+}
+
+// CreateBudget mocks base method.
+func (m *MockwalletStore) CreateBudget(ctx context.Context, budget models.Budget, userID models.UserID) (models.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBudget", ctx, budget, userID)
+	ret0, _ := ret[0].(models.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBudget indicates an expected call of CreateBudget.
+func (mr *MockwalletStoreMockRecorder) CreateBudget(ctx, budget, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBudget", reflect.TypeOf((*MockwalletStore)(nil).CreateBudget), ctx, budget, userID)
+}
+
+// CreateCategory mocks base method.
+func (m *MockwalletStore) CreateCategory(ctx context.Context, category models.Category, userID models.UserID) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, category, userID)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockwalletStoreMockRecorder) CreateCategory(ctx, category, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockwalletStore)(nil).CreateCategory), ctx, category, userID)
+}
+
+// CreateCategoryRule mocks base method.
+func (m *MockwalletStore) CreateCategoryRule(ctx context.Context, rule models.CategoryRule, userID models.UserID) (models.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategoryRule", ctx, rule, userID)
+	ret0, _ := ret[0].(models.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategoryRule indicates an expected call of CreateCategoryRule.
+func (mr *MockwalletStoreMockRecorder) CreateCategoryRule(ctx, rule, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryRule", reflect.TypeOf((*MockwalletStore)(nil).CreateCategoryRule), ctx, rule, userID)
+}
+
+// CreateComplianceCase mocks base method.
+func (m *MockwalletStore) CreateComplianceCase(ctx context.Context, complianceCase models.ComplianceCase) (models.ComplianceCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComplianceCase", ctx, complianceCase)
+	ret0, _ := ret[0].(models.ComplianceCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComplianceCase indicates an expected call of CreateComplianceCase.
+func (mr *MockwalletStoreMockRecorder) CreateComplianceCase(ctx, complianceCase interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComplianceCase", reflect.TypeOf((*MockwalletStore)(nil).CreateComplianceCase), ctx, complianceCase)
+}
+
+// CreateRiskAssessment mocks base method.
+func (m *MockwalletStore) CreateRiskAssessment(ctx context.Context, assessment models.RiskAssessment) (models.RiskAssessment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRiskAssessment", ctx, assessment)
+	ret0, _ := ret[0].(models.RiskAssessment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRiskAssessment indicates an expected call of CreateRiskAssessment.
+func (mr *MockwalletStoreMockRecorder) CreateRiskAssessment(ctx, assessment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRiskAssessment", reflect.TypeOf((*MockwalletStore)(nil).CreateRiskAssessment), ctx, assessment)
+}
+
+// CreateWallet mocks base method.
+func (m *MockwalletStore) CreateWallet(ctx context.Context, wallet models.Wallet, userID models.UserID) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, wallet, userID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockwalletStoreMockRecorder) CreateWallet(ctx, wallet, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockwalletStore)(nil).CreateWallet), ctx, wallet, userID)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockwalletStore) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockwalletStoreMockRecorder) CreateWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockwalletStore)(nil).CreateWebhook), ctx, webhook)
+}
+
+// DecideRiskAssessment mocks base method.
+func (m *MockwalletStore) DecideRiskAssessment(ctx context.Context, assessmentID models.AssessmentID, status string, decidedBy models.UserID) (models.RiskAssessment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideRiskAssessment", ctx, assessmentID, status, decidedBy)
+	ret0, _ := ret[0].(models.RiskAssessment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideRiskAssessment indicates an expected call of DecideRiskAssessment.
+func (mr *MockwalletStoreMockRecorder) DecideRiskAssessment(ctx, assessmentID, status, decidedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideRiskAssessment", reflect.TypeOf((*MockwalletStore)(nil).DecideRiskAssessment), ctx, assessmentID, status, decidedBy)
+}
+
+// DeleteBudget mocks base method.
+func (m *MockwalletStore) DeleteBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBudget", ctx, budgetID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBudget indicates an expected call of DeleteBudget.
+func (mr *MockwalletStoreMockRecorder) DeleteBudget(ctx, budgetID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudget", reflect.TypeOf((*MockwalletStore)(nil).DeleteBudget), ctx, budgetID, userID)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockwalletStore) DeleteCategory(ctx context.Context, categoryID models.CategoryID, userID models.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, categoryID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockwalletStoreMockRecorder) DeleteCategory(ctx, categoryID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockwalletStore)(nil).DeleteCategory), ctx, categoryID, userID)
+}
+
+// DeleteCategoryRule mocks base method.
+func (m *MockwalletStore) DeleteCategoryRule(ctx context.Context, ruleID models.RuleID, userID models.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryRule", ctx, ruleID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategoryRule indicates an expected call of DeleteCategoryRule.
+func (mr *MockwalletStoreMockRecorder) DeleteCategoryRule(ctx, ruleID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryRule", reflect.TypeOf((*MockwalletStore)(nil).DeleteCategoryRule), ctx, ruleID, userID)
+}
+
+// DeleteRiskRule mocks base method.
+func (m *MockwalletStore) DeleteRiskRule(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRiskRule", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRiskRule indicates an expected call of DeleteRiskRule.
+func (mr *MockwalletStoreMockRecorder) DeleteRiskRule(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRiskRule", reflect.TypeOf((*MockwalletStore)(nil).DeleteRiskRule), ctx, name)
+}
+
+// DeleteWallet mocks base method.
+func (m *MockwalletStore) DeleteWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWallet", ctx, walletID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWallet indicates an expected call of DeleteWallet.
+func (mr *MockwalletStoreMockRecorder) DeleteWallet(ctx, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWallet", reflect.TypeOf((*MockwalletStore)(nil).DeleteWallet), ctx, walletID, userID)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockwalletStore) DeleteWebhook(ctx context.Context, webhookID models.WebhookID, userID models.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, webhookID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockwalletStoreMockRecorder) DeleteWebhook(ctx, webhookID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockwalletStore)(nil).DeleteWebhook), ctx, webhookID, userID)
+}
+
+// Deposit mocks base method.
+func (m *MockwalletStore) Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", ctx, transaction, userID, rate)
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockwalletStoreMockRecorder) Deposit(ctx, transaction, userID, rate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockwalletStore)(nil).Deposit), ctx, transaction, userID, rate)
+}
+
+// DoWithTx mocks base method.
+func (m *MockwalletStore) DoWithTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoWithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoWithTx indicates an expected call of DoWithTx.
+func (mr *MockwalletStoreMockRecorder) DoWithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoWithTx", reflect.TypeOf((*MockwalletStore)(nil).DoWithTx), ctx, fn)
+}
+
+// EnqueueWebhookEvent mocks base method.
+func (m *MockwalletStore) EnqueueWebhookEvent(ctx context.Context, event models.WebhookEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueWebhookEvent indicates an expected call of EnqueueWebhookEvent.
+func (mr *MockwalletStoreMockRecorder) EnqueueWebhookEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookEvent", reflect.TypeOf((*MockwalletStore)(nil).EnqueueWebhookEvent), ctx, event)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockwalletStore) GetAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", ctx, keyID, userID)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockwalletStoreMockRecorder) GetAPIKey(ctx, keyID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockwalletStore)(nil).GetAPIKey), ctx, keyID, userID)
+}
+
+// GetAPIKeySecret mocks base method.
+func (m *MockwalletStore) GetAPIKeySecret(ctx context.Context, keyID models.APIKeyID) (models.APIKey, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeySecret", ctx, keyID)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAPIKeySecret indicates an expected call of GetAPIKeySecret.
+func (mr *MockwalletStoreMockRecorder) GetAPIKeySecret(ctx, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeySecret", reflect.TypeOf((*MockwalletStore)(nil).GetAPIKeySecret), ctx, keyID)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockwalletStore) GetAPIKeys(ctx context.Context, userID models.UserID) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockwalletStoreMockRecorder) GetAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockwalletStore)(nil).GetAPIKeys), ctx, userID)
+}
+
+// GetActiveWallets mocks base method.
+func (m *MockwalletStore) GetActiveWallets(ctx context.Context, userID models.UserID) ([]models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveWallets", ctx, userID)
+	ret0, _ := ret[0].([]models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveWallets indicates an expected call of GetActiveWallets.
+func (mr *MockwalletStoreMockRecorder) GetActiveWallets(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveWallets", reflect.TypeOf((*MockwalletStore)(nil).GetActiveWallets), ctx, userID)
+}
+
+// GetAnalytics mocks base method.
+func (m *MockwalletStore) GetAnalytics(ctx context.Context, request models.AnalyticsRequest, userID models.UserID) ([]models.AnalyticsBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnalytics", ctx, request, userID)
+	ret0, _ := ret[0].([]models.AnalyticsBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAnalytics indicates an expected call of GetAnalytics.
+func (mr *MockwalletStoreMockRecorder) GetAnalytics(ctx, request, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnalytics", reflect.TypeOf((*MockwalletStore)(nil).GetAnalytics), ctx, request, userID)
+}
+
+// GetAuditRecords mocks base method.
+func (m *MockwalletStore) GetAuditRecords(ctx context.Context, request models.AuditQuery) ([]models.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditRecords", ctx, request)
+	ret0, _ := ret[0].([]models.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditRecords indicates an expected call of GetAuditRecords.
+func (mr *MockwalletStoreMockRecorder) GetAuditRecords(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditRecords", reflect.TypeOf((*MockwalletStore)(nil).GetAuditRecords), ctx, request)
+}
+
+// GetBudget mocks base method.
+func (m *MockwalletStore) GetBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) (models.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudget", ctx, budgetID, userID)
+	ret0, _ := ret[0].(models.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudget indicates an expected call of GetBudget.
+func (mr *MockwalletStoreMockRecorder) GetBudget(ctx, budgetID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudget", reflect.TypeOf((*MockwalletStore)(nil).GetBudget), ctx, budgetID, userID)
+}
+
+// GetBudgetSpending mocks base method.
+func (m *MockwalletStore) GetBudgetSpending(ctx context.Context, budget models.Budget, from, to time.Time) ([]models.CurrencyAmount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgetSpending", ctx, budget, from, to)
+	ret0, _ := ret[0].([]models.CurrencyAmount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgetSpending indicates an expected call of GetBudgetSpending.
+func (mr *MockwalletStoreMockRecorder) GetBudgetSpending(ctx, budget, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetSpending", reflect.TypeOf((*MockwalletStore)(nil).GetBudgetSpending), ctx, budget, from, to)
+}
+
+// GetBudgets mocks base method.
+func (m *MockwalletStore) GetBudgets(ctx context.Context, userID models.UserID) ([]models.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgets", ctx, userID)
+	ret0, _ := ret[0].([]models.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgets indicates an expected call of GetBudgets.
+func (mr *MockwalletStoreMockRecorder) GetBudgets(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgets", reflect.TypeOf((*MockwalletStore)(nil).GetBudgets), ctx, userID)
+}
+
+// GetCategories mocks base method.
+func (m *MockwalletStore) GetCategories(ctx context.Context, userID models.UserID) ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", ctx, userID)
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockwalletStoreMockRecorder) GetCategories(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockwalletStore)(nil).GetCategories), ctx, userID)
+}
+
+// GetCategoryRules mocks base method.
+func (m *MockwalletStore) GetCategoryRules(ctx context.Context, userID models.UserID) ([]models.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRules", ctx, userID)
+	ret0, _ := ret[0].([]models.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRules indicates an expected call of GetCategoryRules.
+func (mr *MockwalletStoreMockRecorder) GetCategoryRules(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRules", reflect.TypeOf((*MockwalletStore)(nil).GetCategoryRules), ctx, userID)
+}
+
+// GetCategoryTotals mocks base method.
+func (m *MockwalletStore) GetCategoryTotals(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.CategoryTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryTotals", ctx, request, walletID, userID)
+	ret0, _ := ret[0].([]models.CategoryTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryTotals indicates an expected call of GetCategoryTotals.
+func (mr *MockwalletStoreMockRecorder) GetCategoryTotals(ctx, request, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryTotals", reflect.TypeOf((*MockwalletStore)(nil).GetCategoryTotals), ctx, request, walletID, userID)
+}
+
+// GetComplianceCandidates mocks base method.
+func (m *MockwalletStore) GetComplianceCandidates(ctx context.Context, since time.Time, minAmounts map[string]float64) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComplianceCandidates", ctx, since, minAmounts)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComplianceCandidates indicates an expected call of GetComplianceCandidates.
+func (mr *MockwalletStoreMockRecorder) GetComplianceCandidates(ctx, since, minAmounts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComplianceCandidates", reflect.TypeOf((*MockwalletStore)(nil).GetComplianceCandidates), ctx, since, minAmounts)
+}
+
+// GetComplianceCase mocks base method.
+func (m *MockwalletStore) GetComplianceCase(ctx context.Context, caseID models.CaseID) (models.ComplianceCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComplianceCase", ctx, caseID)
+	ret0, _ := ret[0].(models.ComplianceCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComplianceCase indicates an expected call of GetComplianceCase.
+func (mr *MockwalletStoreMockRecorder) GetComplianceCase(ctx, caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComplianceCase", reflect.TypeOf((*MockwalletStore)(nil).GetComplianceCase), ctx, caseID)
+}
+
+// GetComplianceCases mocks base method.
+func (m *MockwalletStore) GetComplianceCases(ctx context.Context, status string) ([]models.ComplianceCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComplianceCases", ctx, status)
+	ret0, _ := ret[0].([]models.ComplianceCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComplianceCases indicates an expected call of GetComplianceCases.
+func (mr *MockwalletStoreMockRecorder) GetComplianceCases(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComplianceCases", reflect.TypeOf((*MockwalletStore)(nil).GetComplianceCases), ctx, status)
+}
+
+// GetRiskAssessments mocks base method.
+func (m *MockwalletStore) GetRiskAssessments(ctx context.Context, status string) ([]models.RiskAssessment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskAssessments", ctx, status)
+	ret0, _ := ret[0].([]models.RiskAssessment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskAssessments indicates an expected call of GetRiskAssessments.
+func (mr *MockwalletStoreMockRecorder) GetRiskAssessments(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskAssessments", reflect.TypeOf((*MockwalletStore)(nil).GetRiskAssessments), ctx, status)
+}
+
+// GetRiskHistory mocks base method.
+func (m *MockwalletStore) GetRiskHistory(ctx context.Context, userID models.UserID, since time.Time) ([]models.RiskTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskHistory", ctx, userID, since)
+	ret0, _ := ret[0].([]models.RiskTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskHistory indicates an expected call of GetRiskHistory.
+func (mr *MockwalletStoreMockRecorder) GetRiskHistory(ctx, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskHistory", reflect.TypeOf((*MockwalletStore)(nil).GetRiskHistory), ctx, userID, since)
+}
+
+// GetRiskRules mocks base method.
+func (m *MockwalletStore) GetRiskRules(ctx context.Context) ([]models.RiskRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskRules", ctx)
+	ret0, _ := ret[0].([]models.RiskRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskRules indicates an expected call of GetRiskRules.
+func (mr *MockwalletStoreMockRecorder) GetRiskRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskRules", reflect.TypeOf((*MockwalletStore)(nil).GetRiskRules), ctx)
+}
+
+// GetTransactions mocks base method.
+func (m *MockwalletStore) GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) (models.TransactionsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", ctx, request, walletID, userID)
+	ret0, _ := ret[0].(models.TransactionsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockwalletStoreMockRecorder) GetTransactions(ctx, request, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockwalletStore)(nil).GetTransactions), ctx, request, walletID, userID)
+}
+
+// GetUserAccount mocks base method.
+func (m *MockwalletStore) GetUserAccount(ctx context.Context, userID models.UserID) (models.UserAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccount", ctx, userID)
+	ret0, _ := ret[0].(models.UserAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccount indicates an expected call of GetUserAccount.
+func (mr *MockwalletStoreMockRecorder) GetUserAccount(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccount", reflect.TypeOf((*MockwalletStore)(nil).GetUserAccount), ctx, userID)
+}
+
+// GetWallet mocks base method.
+func (m *MockwalletStore) GetWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletID, userID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockwalletStoreMockRecorder) GetWallet(ctx, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockwalletStore)(nil).GetWallet), ctx, walletID, userID)
+}
+
+// GetWalletEvents mocks base method.
+func (m *MockwalletStore) GetWalletEvents(ctx context.Context, userID models.UserID, afterEventID int64, limit int) ([]models.WalletEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletEvents", ctx, userID, afterEventID, limit)
+	ret0, _ := ret[0].([]models.WalletEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletEvents indicates an expected call of GetWalletEvents.
+func (mr *MockwalletStoreMockRecorder) GetWalletEvents(ctx, userID, afterEventID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletEvents", reflect.TypeOf((*MockwalletStore)(nil).GetWalletEvents), ctx, userID, afterEventID, limit)
+}
+
+// GetWalletMembers mocks base method.
+func (m *MockwalletStore) GetWalletMembers(ctx context.Context, walletID models.WalletID) ([]models.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletMembers", ctx, walletID)
+	ret0, _ := ret[0].([]models.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletMembers indicates an expected call of GetWalletMembers.
+func (mr *MockwalletStoreMockRecorder) GetWalletMembers(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletMembers", reflect.TypeOf((*MockwalletStore)(nil).GetWalletMembers), ctx, walletID)
+}
+
+// GetWallets mocks base method.
+func (m *MockwalletStore) GetWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) (models.WalletsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallets", ctx, request, userID)
+	ret0, _ := ret[0].(models.WalletsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallets indicates an expected call of GetWallets.
+func (mr *MockwalletStoreMockRecorder) GetWallets(ctx, request, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallets", reflect.TypeOf((*MockwalletStore)(nil).GetWallets), ctx, request, userID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockwalletStore) GetWebhookDeliveries(ctx context.Context, webhookID models.WebhookID, userID models.UserID, status string) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookID, userID, status)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockwalletStoreMockRecorder) GetWebhookDeliveries(ctx, webhookID, userID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockwalletStore)(nil).GetWebhookDeliveries), ctx, webhookID, userID, status)
+}
+
+// GetWebhookDelivery mocks base method.
+func (m *MockwalletStore) GetWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", ctx, webhookID, deliveryID, userID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockwalletStoreMockRecorder) GetWebhookDelivery(ctx, webhookID, deliveryID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockwalletStore)(nil).GetWebhookDelivery), ctx, webhookID, deliveryID, userID)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockwalletStore) GetWebhooks(ctx context.Context, userID models.UserID) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx, userID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockwalletStoreMockRecorder) GetWebhooks(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockwalletStore)(nil).GetWebhooks), ctx, userID)
+}
+
+// InsertAuditRecord mocks base method.
+func (m *MockwalletStore) InsertAuditRecord(ctx context.Context, record models.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditRecord", ctx, record)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ArchiveStaleWallets indicates an expected call of ArchiveStaleWallets.
-func (mr *MockwalletStoreMockRecorder) ArchiveStaleWallets(ctx, checkPeriod interface{}) *gomock.Call {
+// InsertAuditRecord indicates an expected call of InsertAuditRecord.
+func (mr *MockwalletStoreMockRecorder) InsertAuditRecord(ctx, record interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveStaleWallets", reflect.TypeOf((*MockwalletStore)(nil).ArchiveStaleWallets), ctx, checkPeriod)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditRecord", reflect.TypeOf((*MockwalletStore)(nil).InsertAuditRecord), ctx, record)
 }
 
-// CreateWallet mocks base method.
-func (m *MockwalletStore) CreateWallet(ctx context.Context, wallet models.Wallet, userID models.UserID) (models.Wallet, error) {
+// ListenWalletEvents mocks base method.
+func (m *MockwalletStore) ListenWalletEvents(ctx context.Context, handle func(models.WalletEvent)) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", ctx, wallet, userID)
-	ret0, _ := ret[0].(models.Wallet)
+	ret := m.ctrl.Call(m, "ListenWalletEvents", ctx, handle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenWalletEvents indicates an expected call of ListenWalletEvents.
+func (mr *MockwalletStoreMockRecorder) ListenWalletEvents(ctx, handle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenWalletEvents", reflect.TypeOf((*MockwalletStore)(nil).ListenWalletEvents), ctx, handle)
+}
+
+// LockComplianceWatermark mocks base method.
+func (m *MockwalletStore) LockComplianceWatermark(ctx context.Context) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockComplianceWatermark", ctx)
+	ret0, _ := ret[0].(*time.Time)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockwalletStoreMockRecorder) CreateWallet(ctx, wallet, userID interface{}) *gomock.Call {
+// LockComplianceWatermark indicates an expected call of LockComplianceWatermark.
+func (mr *MockwalletStoreMockRecorder) LockComplianceWatermark(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockwalletStore)(nil).CreateWallet), ctx, wallet, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockComplianceWatermark", reflect.TypeOf((*MockwalletStore)(nil).LockComplianceWatermark), ctx)
 }
 
-// DeleteWallet mocks base method.
-func (m *MockwalletStore) DeleteWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) error {
+// PurgeAPIKeySignatures mocks base method.
+func (m *MockwalletStore) PurgeAPIKeySignatures(ctx context.Context) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteWallet", ctx, walletID, userID)
+	ret := m.ctrl.Call(m, "PurgeAPIKeySignatures", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeAPIKeySignatures indicates an expected call of PurgeAPIKeySignatures.
+func (mr *MockwalletStoreMockRecorder) PurgeAPIKeySignatures(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAPIKeySignatures", reflect.TypeOf((*MockwalletStore)(nil).PurgeAPIKeySignatures), ctx)
+}
+
+// PurgeAuditRecords mocks base method.
+func (m *MockwalletStore) PurgeAuditRecords(ctx context.Context, retention time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeAuditRecords", ctx, retention)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeAuditRecords indicates an expected call of PurgeAuditRecords.
+func (mr *MockwalletStoreMockRecorder) PurgeAuditRecords(ctx, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAuditRecords", reflect.TypeOf((*MockwalletStore)(nil).PurgeAuditRecords), ctx, retention)
+}
+
+// PurgeWalletEvents mocks base method.
+func (m *MockwalletStore) PurgeWalletEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeWalletEvents", ctx, retention)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeWalletEvents indicates an expected call of PurgeWalletEvents.
+func (mr *MockwalletStoreMockRecorder) PurgeWalletEvents(ctx, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeWalletEvents", reflect.TypeOf((*MockwalletStore)(nil).PurgeWalletEvents), ctx, retention)
+}
+
+// RecordAPIKeySignature mocks base method.
+func (m *MockwalletStore) RecordAPIKeySignature(ctx context.Context, keyID models.APIKeyID, signature string, expiresAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAPIKeySignature", ctx, keyID, signature, expiresAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAPIKeySignature indicates an expected call of RecordAPIKeySignature.
+func (mr *MockwalletStoreMockRecorder) RecordAPIKeySignature(ctx, keyID, signature, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAPIKeySignature", reflect.TypeOf((*MockwalletStore)(nil).RecordAPIKeySignature), ctx, keyID, signature, expiresAt)
+}
+
+// RecordBudgetAlert mocks base method.
+func (m *MockwalletStore) RecordBudgetAlert(ctx context.Context, alert models.BudgetAlert) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordBudgetAlert", ctx, alert)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordBudgetAlert indicates an expected call of RecordBudgetAlert.
+func (mr *MockwalletStoreMockRecorder) RecordBudgetAlert(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordBudgetAlert", reflect.TypeOf((*MockwalletStore)(nil).RecordBudgetAlert), ctx, alert)
+}
+
+// RecordDeliveryAttempt mocks base method.
+func (m *MockwalletStore) RecordDeliveryAttempt(ctx context.Context, deliveryID models.DeliveryID, attempt models.DeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeliveryAttempt", ctx, deliveryID, attempt, status, nextAttemptAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteWallet indicates an expected call of DeleteWallet.
-func (mr *MockwalletStoreMockRecorder) DeleteWallet(ctx, walletID, userID interface{}) *gomock.Call {
+// RecordDeliveryAttempt indicates an expected call of RecordDeliveryAttempt.
+func (mr *MockwalletStoreMockRecorder) RecordDeliveryAttempt(ctx, deliveryID, attempt, status, nextAttemptAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWallet", reflect.TypeOf((*MockwalletStore)(nil).DeleteWallet), ctx, walletID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeliveryAttempt", reflect.TypeOf((*MockwalletStore)(nil).RecordDeliveryAttempt), ctx, deliveryID, attempt, status, nextAttemptAt)
 }
 
-// Deposit mocks base method.
-func (m *MockwalletStore) Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error {
+// RedeliverWebhookDelivery mocks base method.
+func (m *MockwalletStore) RedeliverWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Deposit", ctx, transaction, userID, rate)
+	ret := m.ctrl.Call(m, "RedeliverWebhookDelivery", ctx, webhookID, deliveryID, userID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhookDelivery indicates an expected call of RedeliverWebhookDelivery.
+func (mr *MockwalletStoreMockRecorder) RedeliverWebhookDelivery(ctx, webhookID, deliveryID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhookDelivery", reflect.TypeOf((*MockwalletStore)(nil).RedeliverWebhookDelivery), ctx, webhookID, deliveryID, userID)
+}
+
+// RefreshRollups mocks base method.
+func (m *MockwalletStore) RefreshRollups(ctx context.Context, lag time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshRollups", ctx, lag)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Deposit indicates an expected call of Deposit.
-func (mr *MockwalletStoreMockRecorder) Deposit(ctx, transaction, userID, rate interface{}) *gomock.Call {
+// RefreshRollups indicates an expected call of RefreshRollups.
+func (mr *MockwalletStoreMockRecorder) RefreshRollups(ctx, lag interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockwalletStore)(nil).Deposit), ctx, transaction, userID, rate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshRollups", reflect.TypeOf((*MockwalletStore)(nil).RefreshRollups), ctx, lag)
 }
 
-// DoWithTx mocks base method.
-func (m *MockwalletStore) DoWithTx(ctx context.Context, fn func(context.Context) error) error {
+// RemoveWalletMember mocks base method.
+func (m *MockwalletStore) RemoveWalletMember(ctx context.Context, walletID models.WalletID, userID models.UserID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DoWithTx", ctx, fn)
+	ret := m.ctrl.Call(m, "RemoveWalletMember", ctx, walletID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DoWithTx indicates an expected call of DoWithTx.
-func (mr *MockwalletStoreMockRecorder) DoWithTx(ctx, fn interface{}) *gomock.Call {
+// RemoveWalletMember indicates an expected call of RemoveWalletMember.
+func (mr *MockwalletStoreMockRecorder) RemoveWalletMember(ctx, walletID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoWithTx", reflect.TypeOf((*MockwalletStore)(nil).DoWithTx), ctx, fn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWalletMember", reflect.TypeOf((*MockwalletStore)(nil).RemoveWalletMember), ctx, walletID, userID)
 }
 
-// GetTransactions mocks base method.
-func (m *MockwalletStore) GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.Transaction, error) {
+// RevokeAPIKey mocks base method.
+func (m *MockwalletStore) RevokeAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransactions", ctx, request, walletID, userID)
-	ret0, _ := ret[0].([]models.Transaction)
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, keyID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockwalletStoreMockRecorder) RevokeAPIKey(ctx, keyID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockwalletStore)(nil).RevokeAPIKey), ctx, keyID, userID)
+}
+
+// RotateAPIKey mocks base method.
+func (m *MockwalletStore) RotateAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID, signingKey []byte) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", ctx, keyID, userID, signingKey)
+	ret0, _ := ret[0].(models.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransactions indicates an expected call of GetTransactions.
-func (mr *MockwalletStoreMockRecorder) GetTransactions(ctx, request, walletID, userID interface{}) *gomock.Call {
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockwalletStoreMockRecorder) RotateAPIKey(ctx, keyID, userID, signingKey interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockwalletStore)(nil).GetTransactions), ctx, request, walletID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockwalletStore)(nil).RotateAPIKey), ctx, keyID, userID, signingKey)
 }
 
-// GetWallet mocks base method.
-func (m *MockwalletStore) GetWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) (models.Wallet, error) {
+// SearchUsers mocks base method.
+func (m *MockwalletStore) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserAccount, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWallet", ctx, walletID, userID)
-	ret0, _ := ret[0].(models.Wallet)
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, search)
+	ret0, _ := ret[0].([]models.UserAccount)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetWallet indicates an expected call of GetWallet.
-func (mr *MockwalletStoreMockRecorder) GetWallet(ctx, walletID, userID interface{}) *gomock.Call {
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockwalletStoreMockRecorder) SearchUsers(ctx, search interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockwalletStore)(nil).GetWallet), ctx, walletID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockwalletStore)(nil).SearchUsers), ctx, search)
 }
 
-// GetWallets mocks base method.
-func (m *MockwalletStore) GetWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) ([]models.Wallet, error) {
+// SetComplianceWatermark mocks base method.
+func (m *MockwalletStore) SetComplianceWatermark(ctx context.Context, watermark time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWallets", ctx, request, userID)
-	ret0, _ := ret[0].([]models.Wallet)
+	ret := m.ctrl.Call(m, "SetComplianceWatermark", ctx, watermark)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetComplianceWatermark indicates an expected call of SetComplianceWatermark.
+func (mr *MockwalletStoreMockRecorder) SetComplianceWatermark(ctx, watermark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComplianceWatermark", reflect.TypeOf((*MockwalletStore)(nil).SetComplianceWatermark), ctx, watermark)
+}
+
+// SetTransactionCategory mocks base method.
+func (m *MockwalletStore) SetTransactionCategory(ctx context.Context, txID models.TxID, walletID models.WalletID, categoryID *models.CategoryID, userID models.UserID) (models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransactionCategory", ctx, txID, walletID, categoryID, userID)
+	ret0, _ := ret[0].(models.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetWallets indicates an expected call of GetWallets.
-func (mr *MockwalletStoreMockRecorder) GetWallets(ctx, request, userID interface{}) *gomock.Call {
+// SetTransactionCategory indicates an expected call of SetTransactionCategory.
+func (mr *MockwalletStoreMockRecorder) SetTransactionCategory(ctx, txID, walletID, categoryID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallets", reflect.TypeOf((*MockwalletStore)(nil).GetWallets), ctx, request, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransactionCategory", reflect.TypeOf((*MockwalletStore)(nil).SetTransactionCategory), ctx, txID, walletID, categoryID, userID)
 }
 
 // Transfer mocks base method.
-func (m *MockwalletStore) Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error {
+func (m *MockwalletStore) Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Transfer", ctx, transaction, userID, rate)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Transfer indicates an expected call of Transfer.
@@ -166,6 +1041,34 @@ func (mr *MockwalletStoreMockRecorder) Transfer(ctx, transaction, userID, rate i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockwalletStore)(nil).Transfer), ctx, transaction, userID, rate)
 }
 
+// UpdateComplianceCaseStatus mocks base method.
+func (m *MockwalletStore) UpdateComplianceCaseStatus(ctx context.Context, caseID models.CaseID, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComplianceCaseStatus", ctx, caseID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateComplianceCaseStatus indicates an expected call of UpdateComplianceCaseStatus.
+func (mr *MockwalletStoreMockRecorder) UpdateComplianceCaseStatus(ctx, caseID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComplianceCaseStatus", reflect.TypeOf((*MockwalletStore)(nil).UpdateComplianceCaseStatus), ctx, caseID, from, to)
+}
+
+// UpdateUserPreferences mocks base method.
+func (m *MockwalletStore) UpdateUserPreferences(ctx context.Context, userID models.UserID, preferences models.UserPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPreferences", ctx, userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPreferences indicates an expected call of UpdateUserPreferences.
+func (mr *MockwalletStoreMockRecorder) UpdateUserPreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPreferences", reflect.TypeOf((*MockwalletStore)(nil).UpdateUserPreferences), ctx, userID, preferences)
+}
+
 // UpdateWallet mocks base method.
 func (m *MockwalletStore) UpdateWallet(ctx context.Context, walletID models.WalletID, updatedWallet models.WalletUpdate, rate float64, userID models.UserID) (models.Wallet, error) {
 	m.ctrl.T.Helper()
@@ -181,12 +1084,58 @@ func (mr *MockwalletStoreMockRecorder) UpdateWallet(ctx, walletID, updatedWallet
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWallet", reflect.TypeOf((*MockwalletStore)(nil).UpdateWallet), ctx, walletID, updatedWallet, rate, userID)
 }
 
+// UpdateWalletMember mocks base method.
+func (m *MockwalletStore) UpdateWalletMember(ctx context.Context, member models.WalletMember) (models.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletMember", ctx, member)
+	ret0, _ := ret[0].(models.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWalletMember indicates an expected call of UpdateWalletMember.
+func (mr *MockwalletStoreMockRecorder) UpdateWalletMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletMember", reflect.TypeOf((*MockwalletStore)(nil).UpdateWalletMember), ctx, member)
+}
+
+// UpsertRiskRule mocks base method.
+func (m *MockwalletStore) UpsertRiskRule(ctx context.Context, rule models.RiskRule) (models.RiskRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertRiskRule", ctx, rule)
+	ret0, _ := ret[0].(models.RiskRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertRiskRule indicates an expected call of UpsertRiskRule.
+func (mr *MockwalletStoreMockRecorder) UpsertRiskRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertRiskRule", reflect.TypeOf((*MockwalletStore)(nil).UpsertRiskRule), ctx, rule)
+}
+
+// UserDeleted mocks base method.
+func (m *MockwalletStore) UserDeleted(ctx context.Context, userID models.UserID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserDeleted", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserDeleted indicates an expected call of UserDeleted.
+func (mr *MockwalletStoreMockRecorder) UserDeleted(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDeleted", reflect.TypeOf((*MockwalletStore)(nil).UserDeleted), ctx, userID)
+}
+
 // Withdraw mocks base method.
-func (m *MockwalletStore) Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error {
+func (m *MockwalletStore) Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Withdraw", ctx, transaction, userID, rate)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Withdraw indicates an expected call of Withdraw.
@@ -256,6 +1205,20 @@ func (m *MocktxProducer) EXPECT() *MocktxProducerMockRecorder {
 	return m.recorder
 }
 
+// ProduceBudgetAlert mocks base method.
+func (m *MocktxProducer) ProduceBudgetAlert(alert models.BudgetAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceBudgetAlert", alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceBudgetAlert indicates an expected call of ProduceBudgetAlert.
+func (mr *MocktxProducerMockRecorder) ProduceBudgetAlert(alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceBudgetAlert", reflect.TypeOf((*MocktxProducer)(nil).ProduceBudgetAlert), alert)
+}
+
 // ProduceTxToKafka mocks base method.
 func (m *MocktxProducer) ProduceTxToKafka(transaction models.Transaction) error {
 	m.ctrl.T.Helper()
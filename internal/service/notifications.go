@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	notificationBuffer = 64
+	replayPageSize     = 1000
+	listenRetryDelay   = time.Second
+)
+
+// notifier fans notifications out to the in-process subscribers of each user.
+// A subscriber whose buffer is full is evicted and its channel closed instead of blocking
+// the publisher; stream clients then reconnect and resume from the last event they received.
+type notifier struct {
+	mu          sync.Mutex
+	subscribers map[models.UserID]map[chan models.Notification]struct{}
+}
+
+func newNotifier() *notifier {
+	return &notifier{subscribers: make(map[models.UserID]map[chan models.Notification]struct{})}
+}
+
+func (n *notifier) subscribe(userID models.UserID) (<-chan models.Notification, func()) {
+	ch := make(chan models.Notification, notificationBuffer)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.subscribers[userID] == nil {
+		n.subscribers[userID] = make(map[chan models.Notification]struct{})
+	}
+
+	n.subscribers[userID][ch] = struct{}{}
+
+	return ch, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		n.remove(userID, ch)
+	}
+}
+
+// remove closes the channel unless it has already been evicted. n.mu must be held.
+func (n *notifier) remove(userID models.UserID, ch chan models.Notification) {
+	if _, ok := n.subscribers[userID][ch]; !ok {
+		return
+	}
+
+	delete(n.subscribers[userID], ch)
+
+	if len(n.subscribers[userID]) == 0 {
+		delete(n.subscribers, userID)
+	}
+
+	close(ch)
+}
+
+func (n *notifier) publish(notification models.Notification) {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers[notification.UserID] {
+		select {
+		case ch <- notification:
+		default:
+			log.Warn().Str("type", notification.Type).Msg("slow notification subscriber evicted")
+
+			n.remove(notification.UserID, ch)
+		}
+	}
+}
+
+// Subscribe returns the notifications of the user and a function releasing the subscription.
+// The channel is closed when the subscription is released or the subscriber falls behind.
+func (s *Service) Subscribe(userID models.UserID) (<-chan models.Notification, func()) {
+	return s.notifier.subscribe(userID)
+}
+
+// StreamNotifications subscribes to the notifications of the user and passes the wallet events
+// committed after lastEventID to replay, page by page until none are left. Event IDs follow commit
+// order, so no event committed before the last one a client received is left out on resume. Live
+// notifications of events committed while replaying are in both, so the returned channel carries
+// every live notification except those already replayed.
+//
+//nolint:lll
+func (s *Service) StreamNotifications(ctx context.Context, userID models.UserID, lastEventID int64, replay func(models.Notification) error) (<-chan models.Notification, func(), error) {
+	live, unsubscribe := s.notifier.subscribe(userID)
+
+	if lastEventID <= 0 {
+		return live, unsubscribe, nil
+	}
+
+	replayed := make(map[int64]struct{})
+
+	for {
+		events, err := s.walletStore.GetWalletEvents(ctx, userID, lastEventID, replayPageSize)
+		if err != nil {
+			unsubscribe()
+
+			return nil, nil, fmt.Errorf("failed to get missed wallet events: %w", err)
+		}
+
+		for _, event := range events {
+			if err = replay(event.Notification(userID)); err != nil {
+				unsubscribe()
+
+				return nil, nil, err
+			}
+
+			replayed[event.EventID] = struct{}{}
+			lastEventID = event.EventID
+		}
+
+		if len(events) < replayPageSize {
+			break
+		}
+	}
+
+	if len(replayed) == 0 {
+		return live, unsubscribe, nil
+	}
+
+	filtered := make(chan models.Notification)
+
+	go func() {
+		defer close(filtered)
+
+		for notification := range live {
+			if _, ok := replayed[notification.ID]; ok {
+				continue
+			}
+
+			select {
+			case filtered <- notification:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return filtered, unsubscribe, nil
+}
+
+// RunEventStream relays the wallet events committed by all replicas to the local subscribers
+// of the wallet members until the context is done.
+func (s *Service) RunEventStream(ctx context.Context) error {
+	for {
+		err := s.walletStore.ListenWalletEvents(ctx, func(event models.WalletEvent) {
+			for _, userID := range event.Recipients {
+				s.notifier.publish(event.Notification(userID))
+			}
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("wallet event listener stopped, reconnecting")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (s *Service) purgeWalletEvents(ctx context.Context) error {
+	if s.cfg.EventRetention <= 0 {
+		return nil
+	}
+
+	deleted, err := s.walletStore.PurgeWalletEvents(ctx, s.cfg.EventRetention)
+	if err != nil {
+		return fmt.Errorf("failed to purge wallet events: %w", err)
+	}
+
+	log.Debug().Int64("deleted", deleted).Msg("expired wallet events purged")
+
+	return nil
+}
@@ -0,0 +1,66 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamNotifications(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+
+	events := func(from, to int64) []models.WalletEvent {
+		result := make([]models.WalletEvent, 0, to-from+1)
+
+		for id := from; id <= to; id++ {
+			result = append(result, models.WalletEvent{EventID: id, Type: models.NotificationBalanceUpdated})
+		}
+
+		return result
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWalletStore := mocks.NewMockwalletStore(ctrl)
+	gomock.InOrder(
+		mockWalletStore.EXPECT().GetWalletEvents(ctx, userID, int64(10), replayPageSize).
+			Return(events(12, 11+replayPageSize), nil),
+		mockWalletStore.EXPECT().GetWalletEvents(ctx, userID, int64(11+replayPageSize), replayPageSize).
+			Return(events(12+replayPageSize, 13+replayPageSize), nil),
+	)
+
+	svc := &Service{walletStore: mockWalletStore, notifier: newNotifier()}
+
+	var replayed []int64
+
+	live, unsubscribe, err := svc.StreamNotifications(ctx, userID, 10, func(notification models.Notification) error {
+		replayed = append(replayed, notification.ID)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	defer unsubscribe()
+
+	require.Len(t, replayed, replayPageSize+2)
+	require.Equal(t, int64(13+replayPageSize), replayed[len(replayed)-1])
+
+	// Event 11 committed after the events replayed and is still delivered, while the replayed
+	// event 12 is not sent twice.
+	for _, id := range []int64{12, 11, 14 + replayPageSize} {
+		svc.notifier.publish(models.Notification{ID: id, UserID: userID})
+	}
+
+	require.Equal(t, int64(11), (<-live).ID)
+	require.Equal(t, int64(14+replayPageSize), (<-live).ID)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+// GetPortfolioSummary converts every active wallet of the user into the reporting currency.
+// Wallets whose currency cannot be priced are returned without a converted balance and
+// are left out of the net worth.
+//
+//nolint:lll
+func (s *Service) GetPortfolioSummary(ctx context.Context, currency string, userID models.UserID) (models.PortfolioSummary, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		return models.PortfolioSummary{}, models.ErrWrongCurrency
+	}
+
+	wallets, err := s.walletStore.GetActiveWallets(ctx, userID)
+	if err != nil {
+		return models.PortfolioSummary{}, fmt.Errorf("error getting wallets info: %w", err)
+	}
+
+	currencies := make([]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		currencies = append(currencies, wallet.Currency)
+	}
+
+	quotes, unpriced := s.quoteRates(ctx, currencies, currency)
+
+	summary := models.PortfolioSummary{
+		Currency:           currency,
+		Wallets:            make([]models.PortfolioWallet, 0, len(wallets)),
+		Complete:           len(unpriced) == 0,
+		Rates:              make([]models.RateQuote, 0, len(quotes)),
+		UnpricedCurrencies: unpriced,
+	}
+
+	for _, wallet := range wallets {
+		item := models.PortfolioWallet{
+			WalletID:   wallet.WalletID,
+			WalletName: wallet.WalletName,
+			Currency:   wallet.Currency,
+			Balance:    wallet.Balance,
+		}
+
+		if quote, ok := quotes[strings.ToUpper(wallet.Currency)]; ok {
+			converted := wallet.Balance * quote.Rate
+			item.ConvertedBalance = &converted
+			summary.NetWorth += converted
+		}
+
+		summary.Wallets = append(summary.Wallets, item)
+	}
+
+	for _, quote := range quotes {
+		summary.Rates = append(summary.Rates, quote)
+	}
+
+	sort.Slice(summary.Rates, func(i, j int) bool {
+		return summary.Rates[i].FromCurrency < summary.Rates[j].FromCurrency
+	})
+
+	return summary, nil
+}
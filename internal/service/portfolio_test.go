@@ -0,0 +1,58 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPortfolioSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+
+	wallets := []models.Wallet{
+		{WalletID: models.WalletID(uuid.New()), Currency: "USD", Balance: 100},
+		{WalletID: models.WalletID(uuid.New()), Currency: "USD", Balance: 50},
+		{WalletID: models.WalletID(uuid.New()), Currency: "EUR", Balance: 10},
+		{WalletID: models.WalletID(uuid.New()), Currency: "XYZ", Balance: 7},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWalletStore := mocks.NewMockwalletStore(ctrl)
+	mockXRClient := mocks.NewMockxrClient(ctrl)
+
+	mockWalletStore.EXPECT().GetActiveWallets(ctx, userID).Return(wallets, nil).Times(2)
+	mockXRClient.EXPECT().GetRate(gomock.Any(), "USD", "EUR").Return(0.5, nil).Times(1)
+	mockXRClient.EXPECT().GetRate(gomock.Any(), "XYZ", "EUR").Return(0.0, models.ErrWrongCurrency).Times(2)
+
+	svc := &Service{
+		walletStore: mockWalletStore,
+		xrClient:    mockXRClient,
+		metrics:     getTestMetrics(),
+		rates:       newRateCache(time.Minute),
+	}
+
+	for range 2 {
+		summary, err := svc.GetPortfolioSummary(ctx, "eur", userID)
+		require.NoError(t, err)
+
+		require.Equal(t, "EUR", summary.Currency)
+		require.Len(t, summary.Wallets, len(wallets))
+		require.InDelta(t, 85.0, summary.NetWorth, 1e-9)
+		require.False(t, summary.Complete)
+		require.Equal(t, []string{"XYZ"}, summary.UnpricedCurrencies)
+		require.Len(t, summary.Rates, 2)
+		require.Nil(t, summary.Wallets[3].ConvertedBalance)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"golang.org/x/sync/errgroup"
+)
+
+const maxConcurrentRateLookups = 8
+
+type ratePair struct {
+	from string
+	to   string
+}
+
+// rateCache keeps recently fetched exchange rates for read-only reports.
+// Money-moving operations always ask the xr service directly.
+type rateCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[ratePair]models.RateQuote
+}
+
+func newRateCache(ttl time.Duration) *rateCache {
+	return &rateCache{
+		ttl:     ttl,
+		entries: make(map[ratePair]models.RateQuote),
+	}
+}
+
+func (c *rateCache) get(pair ratePair) (models.RateQuote, bool) {
+	if c == nil || c.ttl <= 0 {
+		return models.RateQuote{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	quote, ok := c.entries[pair]
+	if !ok || time.Since(quote.FetchedAt) > c.ttl {
+		return models.RateQuote{}, false
+	}
+
+	return quote, true
+}
+
+func (c *rateCache) put(pair ratePair, quote models.RateQuote) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[pair] = quote
+}
+
+// quoteRates returns rates from each of the currencies into the target currency.
+// Currencies are deduplicated and looked up concurrently; currencies that cannot be
+// priced are reported separately instead of failing the whole lookup.
+func (s *Service) quoteRates(ctx context.Context, currencies []string, to string) (map[string]models.RateQuote, []string) {
+	to = strings.ToUpper(to)
+
+	var (
+		mu       sync.Mutex
+		quotes   = make(map[string]models.RateQuote)
+		unpriced []string
+		seen     = make(map[string]struct{})
+	)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentRateLookups)
+
+	for _, currency := range currencies {
+		from := strings.ToUpper(currency)
+		if _, ok := seen[from]; ok {
+			continue
+		}
+
+		seen[from] = struct{}{}
+
+		group.Go(func() error {
+			quote, err := s.quoteRate(groupCtx, from, to)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				unpriced = append(unpriced, from)
+
+				return nil
+			}
+
+			quotes[from] = quote
+
+			return nil
+		})
+	}
+
+	_ = group.Wait()
+
+	sort.Strings(unpriced)
+
+	return quotes, unpriced
+}
+
+func (s *Service) quoteRate(ctx context.Context, from, to string) (models.RateQuote, error) {
+	if from == to {
+		return models.RateQuote{FromCurrency: from, ToCurrency: to, Rate: defaultRate, FetchedAt: time.Now()}, nil
+	}
+
+	pair := ratePair{from: from, to: to}
+
+	if quote, ok := s.rates.get(pair); ok {
+		return quote, nil
+	}
+
+	rate, err := s.xrClient.GetRate(ctx, from, to)
+	if err != nil {
+		return models.RateQuote{}, fmt.Errorf("failed to obtain exchange rate %s/%s: %w", from, to, err)
+	}
+
+	quote := models.RateQuote{FromCurrency: from, ToCurrency: to, Rate: rate, FetchedAt: time.Now()}
+	s.rates.put(pair, quote)
+
+	return quote, nil
+}
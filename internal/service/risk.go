@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// riskRuleFunc reports whether a rule with the params triggers on the check, given the user's
+// recent withdrawals and transfers, newest first.
+type riskRuleFunc func(check models.RiskCheck, history []models.RiskTransaction, params models.RiskRuleParams) bool
+
+// riskRules maps rule kinds to their implementation. New kinds plug in here and in
+// models.RiskRule.Validate.
+//
+//nolint:gochecknoglobals
+var riskRules = map[string]riskRuleFunc{
+	models.RiskRuleVelocity:        velocityRule,
+	models.RiskRuleAmountSpike:     amountSpikeRule,
+	models.RiskRuleNewCounterparty: newCounterpartyRule,
+	models.RiskRuleCurrencyCycling: currencyCyclingRule,
+}
+
+// outcomeSeverity orders outcomes, so that the most severe of the triggered rules wins.
+//
+//nolint:gochecknoglobals
+var outcomeSeverity = map[string]int{
+	models.RiskOutcomeAllow:  0,
+	models.RiskOutcomeReview: 1,
+	models.RiskOutcomeBlock:  2,
+}
+
+// screen runs the enabled risk rules against a withdrawal or transfer about to be committed and
+// returns a *models.RiskError when any of them triggers.
+func (s *Service) screen(ctx context.Context, check models.RiskCheck) error {
+	rules, err := s.walletStore.GetRiskRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get risk rules: %w", err)
+	}
+
+	rules = slices.DeleteFunc(rules, func(rule models.RiskRule) bool {
+		return !rule.Enabled || riskRules[rule.Kind] == nil
+	})
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	window := 0
+
+	for _, rule := range rules {
+		window = max(window, rule.Params.WindowMinutes)
+	}
+
+	history, err := s.walletStore.GetRiskHistory(ctx, check.UserID, check.At.Add(-time.Duration(window)*time.Minute))
+	if err != nil {
+		return fmt.Errorf("failed to get risk history: %w", err)
+	}
+
+	assessment := models.RiskAssessment{
+		UserID:      check.UserID,
+		Outcome:     models.RiskOutcomeAllow,
+		Rules:       make([]string, 0),
+		Transaction: check.Transaction,
+	}
+
+	for _, rule := range rules {
+		if !riskRules[rule.Kind](check, within(history, check.At, rule.Params.WindowMinutes), rule.Params) {
+			continue
+		}
+
+		assessment.Rules = append(assessment.Rules, rule.Name)
+
+		if outcomeSeverity[rule.Action] > outcomeSeverity[assessment.Outcome] {
+			assessment.Outcome = rule.Action
+		}
+	}
+
+	if assessment.Outcome == models.RiskOutcomeAllow {
+		return nil
+	}
+
+	return &models.RiskError{Assessment: assessment}
+}
+
+// recordAssessment stores the assessment of a transaction that screening did not allow. Held
+// transactions enter the review queue.
+func (s *Service) recordAssessment(ctx context.Context, riskErr *models.RiskError) error {
+	assessment := riskErr.Assessment
+	assessment.AssessmentID = models.AssessmentID(uuid.New())
+
+	if assessment.Outcome == models.RiskOutcomeReview {
+		assessment.Status = models.ReviewStatusPending
+	}
+
+	created, err := s.walletStore.CreateRiskAssessment(ctx, assessment)
+	if err != nil {
+		return fmt.Errorf("failed to record risk assessment: %w", err)
+	}
+
+	log.Info().
+		Str("assessmentId", uuid.UUID(created.AssessmentID).String()).
+		Str("outcome", created.Outcome).
+		Strs("rules", created.Rules).
+		Msg("transaction not allowed by risk screening")
+
+	return &models.RiskError{Assessment: created}
+}
+
+func within(history []models.RiskTransaction, at time.Time, windowMinutes int) []models.RiskTransaction {
+	since := at.Add(-time.Duration(windowMinutes) * time.Minute)
+
+	for i, t := range history {
+		if t.CommittedAt.Before(since) {
+			return history[:i]
+		}
+	}
+
+	return history
+}
+
+func velocityRule(_ models.RiskCheck, history []models.RiskTransaction, params models.RiskRuleParams) bool {
+	return len(history) >= params.MaxCount
+}
+
+func amountSpikeRule(check models.RiskCheck, history []models.RiskTransaction, params models.RiskRuleParams) bool {
+	var (
+		total float64
+		count int
+	)
+
+	for _, t := range history {
+		if strings.EqualFold(t.Currency, check.Transaction.Currency) {
+			total += t.Amount
+			count++
+		}
+	}
+
+	if count == 0 || count < params.MinHistory {
+		return false
+	}
+
+	return check.Transaction.Amount > params.Multiplier*total/float64(count)
+}
+
+func newCounterpartyRule(check models.RiskCheck, history []models.RiskTransaction, params models.RiskRuleParams) bool {
+	if check.Transaction.ToWalletID == nil || check.Transaction.Amount < params.MinAmount {
+		return false
+	}
+
+	return !slices.ContainsFunc(history, func(t models.RiskTransaction) bool {
+		return t.Type == "transfer" && t.ToWalletID != nil && *t.ToWalletID == *check.Transaction.ToWalletID
+	})
+}
+
+func currencyCyclingRule(check models.RiskCheck, history []models.RiskTransaction, params models.RiskRuleParams) bool {
+	if check.ToCurrency == "" || strings.EqualFold(check.Transaction.Currency, check.ToCurrency) {
+		return false
+	}
+
+	crossCurrency := 0
+
+	for _, t := range history {
+		if t.Type == "transfer" && t.ToCurrency != "" && !strings.EqualFold(t.Currency, t.ToCurrency) {
+			crossCurrency++
+		}
+	}
+
+	return crossCurrency >= params.MaxCount
+}
+
+func (s *Service) GetRiskRules(ctx context.Context) ([]models.RiskRule, error) {
+	rules, err := s.walletStore.GetRiskRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting risk rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// SaveRiskRule creates or replaces a rule. It applies to the next screened transaction.
+func (s *Service) SaveRiskRule(ctx context.Context, rule models.RiskRule) (models.RiskRule, error) {
+	if err := rule.Validate(); err != nil {
+		return models.RiskRule{}, err
+	}
+
+	saved, err := s.walletStore.UpsertRiskRule(ctx, rule)
+	if err != nil {
+		return models.RiskRule{}, fmt.Errorf("failed to save risk rule: %w", err)
+	}
+
+	return saved, nil
+}
+
+func (s *Service) DeleteRiskRule(ctx context.Context, name string) error {
+	if err := s.walletStore.DeleteRiskRule(ctx, name); err != nil {
+		return fmt.Errorf("failed to delete risk rule: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetRiskReviews(ctx context.Context, status string) ([]models.RiskAssessment, error) {
+	assessments, err := s.walletStore.GetRiskAssessments(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting risk reviews: %w", err)
+	}
+
+	return assessments, nil
+}
+
+// ApproveRiskReview commits a held transaction on behalf of the user who made it, without
+// screening it again. The transaction is checked against the current balances and rights, and
+// the review stays pending when it fails.
+func (s *Service) ApproveRiskReview(ctx context.Context, assessmentID models.AssessmentID, reviewerID models.UserID) (models.RiskAssessment, error) {
+	var decided models.RiskAssessment
+
+	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		decided, err = s.walletStore.DecideRiskAssessment(ctx, assessmentID, models.ReviewStatusApproved, reviewerID)
+		if err != nil {
+			return fmt.Errorf("failed to approve risk review: %w", err)
+		}
+
+		switch decided.Transaction.Type {
+		case "withdraw":
+			return s.withdrawTx(ctx, decided.Transaction, decided.UserID, false)
+		case "transfer":
+			return s.transferTx(ctx, decided.Transaction, decided.UserID, false)
+		default:
+			return fmt.Errorf("%w: cannot commit %q transactions", models.ErrInvalidTransaction, decided.Transaction.Type)
+		}
+	}); err != nil {
+		return models.RiskAssessment{}, fmt.Errorf("error in DoWithTX(): %w", err)
+	}
+
+	s.evaluateWalletBudgets(ctx, decided.Transaction.FromWalletID)
+
+	return decided, nil
+}
+
+func (s *Service) DeclineRiskReview(ctx context.Context, assessmentID models.AssessmentID, reviewerID models.UserID) (models.RiskAssessment, error) {
+	decided, err := s.walletStore.DecideRiskAssessment(ctx, assessmentID, models.ReviewStatusDeclined, reviewerID)
+	if err != nil {
+		return models.RiskAssessment{}, fmt.Errorf("failed to decline risk review: %w", err)
+	}
+
+	return decided, nil
+}
+
+// screenedError records the assessment when screening stopped the transaction.
+func (s *Service) screenedError(ctx context.Context, err error) error {
+	var riskErr *models.RiskError
+
+	if errors.As(err, &riskErr) {
+		return s.recordAssessment(ctx, riskErr)
+	}
+
+	return fmt.Errorf("error in DoWithTX(): %w", err)
+}
@@ -0,0 +1,275 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestRiskRules(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	walletID, otherWalletID := models.WalletID(uuid.New()), models.WalletID(uuid.New())
+
+	past := func(minutes int, amount float64, toWalletID *models.WalletID, toCurrency string) models.RiskTransaction {
+		txType := "withdraw"
+		if toWalletID != nil {
+			txType = "transfer"
+		}
+
+		return models.RiskTransaction{
+			Type:        txType,
+			ToWalletID:  toWalletID,
+			Amount:      amount,
+			Currency:    "USD",
+			ToCurrency:  toCurrency,
+			CommittedAt: now.Add(-time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	transfer := models.RiskCheck{
+		Transaction: models.Transaction{Type: "transfer", ToWalletID: &walletID, Amount: 500, Currency: "USD"},
+		ToCurrency:  "EUR",
+		At:          now,
+	}
+
+	tests := []struct {
+		name     string
+		rule     riskRuleFunc
+		check    models.RiskCheck
+		history  []models.RiskTransaction
+		params   models.RiskRuleParams
+		triggers bool
+	}{
+		{
+			name:     "velocity below the limit",
+			rule:     velocityRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, nil, "")},
+			params:   models.RiskRuleParams{MaxCount: 2},
+			triggers: false,
+		},
+		{
+			name:     "velocity at the limit",
+			rule:     velocityRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, nil, ""), past(2, 10, nil, "")},
+			params:   models.RiskRuleParams{MaxCount: 2},
+			triggers: true,
+		},
+		{
+			name:     "amount spike",
+			rule:     amountSpikeRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, nil, ""), past(2, 30, nil, "")},
+			params:   models.RiskRuleParams{Multiplier: 10, MinHistory: 2},
+			triggers: true,
+		},
+		{
+			name:     "amount spike without enough history",
+			rule:     amountSpikeRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, nil, "")},
+			params:   models.RiskRuleParams{Multiplier: 10, MinHistory: 2},
+			triggers: false,
+		},
+		{
+			name:     "large transfer to a new counterparty",
+			rule:     newCounterpartyRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, &otherWalletID, "USD")},
+			params:   models.RiskRuleParams{MinAmount: 100},
+			triggers: true,
+		},
+		{
+			name:     "large transfer to a known counterparty",
+			rule:     newCounterpartyRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, &walletID, "USD")},
+			params:   models.RiskRuleParams{MinAmount: 100},
+			triggers: false,
+		},
+		{
+			name:     "small transfer to a new counterparty",
+			rule:     newCounterpartyRule,
+			check:    transfer,
+			params:   models.RiskRuleParams{MinAmount: 1000},
+			triggers: false,
+		},
+		{
+			name:     "currency cycling",
+			rule:     currencyCyclingRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, &walletID, "EUR"), past(2, 10, &walletID, "RUB")},
+			params:   models.RiskRuleParams{MaxCount: 2},
+			triggers: true,
+		},
+		{
+			name:     "same currency transfers do not cycle",
+			rule:     currencyCyclingRule,
+			check:    transfer,
+			history:  []models.RiskTransaction{past(1, 10, &walletID, "USD"), past(2, 10, &walletID, "EUR")},
+			params:   models.RiskRuleParams{MaxCount: 2},
+			triggers: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.triggers, tt.rule(tt.check, tt.history, tt.params))
+		})
+	}
+}
+
+//nolint:funlen
+func TestScreen(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+	now := time.Now()
+
+	check := models.RiskCheck{
+		UserID:      userID,
+		Transaction: models.Transaction{Type: "withdraw", Amount: 100, Currency: "USD"},
+		At:          now,
+	}
+
+	recent := models.RiskTransaction{Type: "withdraw", Amount: 1, Currency: "USD", CommittedAt: now.Add(-time.Minute)}
+	older := models.RiskTransaction{Type: "withdraw", Amount: 1, Currency: "USD", CommittedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name            string
+		rules           []models.RiskRule
+		history         []models.RiskTransaction
+		expectedErr     error
+		expectedOutcome string
+		expectedRules   []string
+	}{
+		{
+			name: "disabled rules are skipped",
+			rules: []models.RiskRule{
+				{Name: "velocity", Kind: models.RiskRuleVelocity, Action: models.RiskOutcomeBlock, Params: models.RiskRuleParams{WindowMinutes: 10, MaxCount: 1}},
+			},
+		},
+		{
+			name: "rules only see their window",
+			rules: []models.RiskRule{
+				{Name: "velocity", Kind: models.RiskRuleVelocity, Action: models.RiskOutcomeReview, Enabled: true, Params: models.RiskRuleParams{WindowMinutes: 10, MaxCount: 2}},
+				{Name: "spike", Kind: models.RiskRuleAmountSpike, Action: models.RiskOutcomeReview, Enabled: true, Params: models.RiskRuleParams{WindowMinutes: 120, Multiplier: 10, MinHistory: 3}},
+			},
+			history: []models.RiskTransaction{recent, older},
+		},
+		{
+			name: "the most severe outcome wins",
+			rules: []models.RiskRule{
+				{Name: "velocity", Kind: models.RiskRuleVelocity, Action: models.RiskOutcomeReview, Enabled: true, Params: models.RiskRuleParams{WindowMinutes: 10, MaxCount: 1}},
+				{Name: "spike", Kind: models.RiskRuleAmountSpike, Action: models.RiskOutcomeBlock, Enabled: true, Params: models.RiskRuleParams{WindowMinutes: 120, Multiplier: 10, MinHistory: 2}},
+			},
+			history:         []models.RiskTransaction{recent, older},
+			expectedErr:     models.ErrTransactionBlocked,
+			expectedOutcome: models.RiskOutcomeBlock,
+			expectedRules:   []string{"velocity", "spike"},
+		},
+		{
+			name: "held for review",
+			rules: []models.RiskRule{
+				{Name: "velocity", Kind: models.RiskRuleVelocity, Action: models.RiskOutcomeReview, Enabled: true, Params: models.RiskRuleParams{WindowMinutes: 10, MaxCount: 1}},
+			},
+			history:         []models.RiskTransaction{recent},
+			expectedErr:     models.ErrTransactionHeld,
+			expectedOutcome: models.RiskOutcomeReview,
+			expectedRules:   []string{"velocity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWalletStore := mocks.NewMockwalletStore(ctrl)
+			mockWalletStore.EXPECT().GetRiskRules(ctx).Return(tt.rules, nil)
+
+			if tt.history != nil {
+				mockWalletStore.EXPECT().GetRiskHistory(ctx, userID, gomock.Any()).Return(tt.history, nil)
+			}
+
+			svc := &Service{walletStore: mockWalletStore}
+
+			err := svc.screen(ctx, check)
+
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.expectedErr)
+
+			var riskErr *models.RiskError
+
+			require.True(t, errors.As(err, &riskErr))
+			require.Equal(t, tt.expectedOutcome, riskErr.Assessment.Outcome)
+			require.Equal(t, tt.expectedRules, riskErr.Assessment.Rules)
+		})
+	}
+}
+
+func TestWithdrawHeldForReview(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+	walletID := models.WalletID(uuid.New())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ws := mocks.NewMockwalletStore(ctrl)
+
+	ws.EXPECT().UserDeleted(ctx, userID).Return(false, nil)
+	ws.EXPECT().DoWithTx(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+	ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
+		WalletID:   walletID,
+		UserID:     userID,
+		Currency:   "USD",
+		Balance:    500.0,
+		Membership: ownerMembership,
+	}, nil)
+	ws.EXPECT().GetRiskRules(ctx).Return([]models.RiskRule{
+		{Name: "velocity", Kind: models.RiskRuleVelocity, Action: models.RiskOutcomeReview, Enabled: true, Params: models.RiskRuleParams{WindowMinutes: 10, MaxCount: 1}},
+	}, nil)
+	ws.EXPECT().GetRiskHistory(ctx, userID, gomock.Any()).Return([]models.RiskTransaction{{Type: "withdraw", CommittedAt: time.Now()}}, nil)
+	ws.EXPECT().CreateRiskAssessment(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, assessment models.RiskAssessment) (models.RiskAssessment, error) {
+			require.Equal(t, models.ReviewStatusPending, assessment.Status)
+			require.Equal(t, userID, assessment.UserID)
+
+			return assessment, nil
+		},
+	)
+
+	svc := &Service{walletStore: ws, metrics: getTestMetrics()}
+
+	err := svc.Withdraw(ctx, models.Transaction{Type: "withdraw", FromWalletID: &walletID, Amount: 100, Currency: "USD"}, userID)
+	require.ErrorIs(t, err, models.ErrTransactionHeld)
+}
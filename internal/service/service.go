@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -18,13 +19,77 @@ type walletStore interface {
 	GetWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) (models.Wallet, error)
 	UpdateWallet(ctx context.Context, walletID models.WalletID, updatedWallet models.WalletUpdate, rate float64, userID models.UserID) (models.Wallet, error)
 	DeleteWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) error
-	GetWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) ([]models.Wallet, error)
+	GetWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) (models.WalletsPage, error)
 	ArchiveStaleWallets(ctx context.Context, checkPeriod time.Duration) error
 	DoWithTx(ctx context.Context, fn func(ctx context.Context) error) error
-	Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error
-	Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error
-	Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error
-	GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.Transaction, error)
+	Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error)
+	Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error)
+	Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error)
+	GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) (models.TransactionsPage, error)
+	InsertAuditRecord(ctx context.Context, record models.AuditRecord) error
+	GetAuditRecords(ctx context.Context, request models.AuditQuery) ([]models.AuditRecord, error)
+	PurgeAuditRecords(ctx context.Context, retention time.Duration) (int64, error)
+	RefreshRollups(ctx context.Context, lag time.Duration) error
+	GetAnalytics(ctx context.Context, request models.AnalyticsRequest, userID models.UserID) ([]models.AnalyticsBucket, error)
+	GetActiveWallets(ctx context.Context, userID models.UserID) ([]models.Wallet, error)
+	CreateCategory(ctx context.Context, category models.Category, userID models.UserID) (models.Category, error)
+	GetCategories(ctx context.Context, userID models.UserID) ([]models.Category, error)
+	DeleteCategory(ctx context.Context, categoryID models.CategoryID, userID models.UserID) error
+	CreateCategoryRule(ctx context.Context, rule models.CategoryRule, userID models.UserID) (models.CategoryRule, error)
+	GetCategoryRules(ctx context.Context, userID models.UserID) ([]models.CategoryRule, error)
+	DeleteCategoryRule(ctx context.Context, ruleID models.RuleID, userID models.UserID) error
+	SetTransactionCategory(ctx context.Context, txID models.TxID, walletID models.WalletID, categoryID *models.CategoryID, userID models.UserID) (models.Transaction, error)
+	ApplyCategoryRules(ctx context.Context, userID models.UserID, force bool) (int64, error)
+	GetCategoryTotals(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.CategoryTotal, error)
+	CreateBudget(ctx context.Context, budget models.Budget, userID models.UserID) (models.Budget, error)
+	GetBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) (models.Budget, error)
+	GetBudgets(ctx context.Context, userID models.UserID) ([]models.Budget, error)
+	DeleteBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) error
+	GetBudgetSpending(ctx context.Context, budget models.Budget, from, to time.Time) ([]models.CurrencyAmount, error)
+	RecordBudgetAlert(ctx context.Context, alert models.BudgetAlert) (bool, error)
+	GetWalletMembers(ctx context.Context, walletID models.WalletID) ([]models.WalletMember, error)
+	AddWalletMember(ctx context.Context, member models.WalletMember) (models.WalletMember, error)
+	UpdateWalletMember(ctx context.Context, member models.WalletMember) (models.WalletMember, error)
+	RemoveWalletMember(ctx context.Context, walletID models.WalletID, userID models.UserID) error
+	CreateAPIKey(ctx context.Context, key models.APIKey, signingKey []byte) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context, userID models.UserID) ([]models.APIKey, error)
+	GetAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) (models.APIKey, error)
+	GetAPIKeySecret(ctx context.Context, keyID models.APIKeyID) (models.APIKey, []byte, error)
+	RotateAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID, signingKey []byte) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) error
+	RecordAPIKeySignature(ctx context.Context, keyID models.APIKeyID, signature string, expiresAt time.Time) (bool, error)
+	PurgeAPIKeySignatures(ctx context.Context) (int64, error)
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetWebhooks(ctx context.Context, userID models.UserID) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID models.WebhookID, userID models.UserID) error
+	EnqueueWebhookEvent(ctx context.Context, event models.WebhookEvent) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDispatch, error)
+	RecordDeliveryAttempt(ctx context.Context, deliveryID models.DeliveryID, attempt models.DeliveryAttempt, status string, nextAttemptAt *time.Time) error
+	GetWebhookDeliveries(ctx context.Context, webhookID models.WebhookID, userID models.UserID, status string) ([]models.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error)
+	RedeliverWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error)
+	ListenWalletEvents(ctx context.Context, handle func(event models.WalletEvent)) error
+	GetWalletEvents(ctx context.Context, userID models.UserID, afterEventID int64, limit int) ([]models.WalletEvent, error)
+	PurgeWalletEvents(ctx context.Context, retention time.Duration) (int64, error)
+	LockComplianceWatermark(ctx context.Context) (*time.Time, error)
+	SetComplianceWatermark(ctx context.Context, watermark time.Time) error
+	GetComplianceCandidates(ctx context.Context, since time.Time, minAmounts map[string]float64) ([]models.Transaction, error)
+	CreateComplianceCase(ctx context.Context, complianceCase models.ComplianceCase) (models.ComplianceCase, error)
+	GetComplianceCases(ctx context.Context, status string) ([]models.ComplianceCase, error)
+	GetComplianceCase(ctx context.Context, caseID models.CaseID) (models.ComplianceCase, error)
+	UpdateComplianceCaseStatus(ctx context.Context, caseID models.CaseID, from, to string) error
+	AddComplianceCaseNote(ctx context.Context, note models.CaseNote) (models.CaseNote, error)
+	GetRiskRules(ctx context.Context) ([]models.RiskRule, error)
+	UpsertRiskRule(ctx context.Context, rule models.RiskRule) (models.RiskRule, error)
+	DeleteRiskRule(ctx context.Context, name string) error
+	GetRiskHistory(ctx context.Context, userID models.UserID, since time.Time) ([]models.RiskTransaction, error)
+	CreateRiskAssessment(ctx context.Context, assessment models.RiskAssessment) (models.RiskAssessment, error)
+	GetRiskAssessments(ctx context.Context, status string) ([]models.RiskAssessment, error)
+	DecideRiskAssessment(ctx context.Context, assessmentID models.AssessmentID, status string, decidedBy models.UserID) (models.RiskAssessment, error)
+	UserDeleted(ctx context.Context, userID models.UserID) (bool, error)
+	GetUserAccount(ctx context.Context, userID models.UserID) (models.UserAccount, error)
+	UpdateUserPreferences(ctx context.Context, userID models.UserID, preferences models.UserPreferences) error
+	SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserAccount, error)
 }
 
 type xrClient interface {
@@ -34,11 +99,24 @@ type xrClient interface {
 //go:generate mockgen -source=service.go -destination=./mocks/transactions_mock.gen.go -package=mocks txProducer
 type txProducer interface {
 	ProduceTxToKafka(transaction models.Transaction) error
+	ProduceBudgetAlert(alert models.BudgetAlert) error
 }
 
 type Config struct {
-	StaleWalletDuration time.Duration
-	PerformCheckPeriod  time.Duration
+	StaleWalletDuration  time.Duration
+	PerformCheckPeriod   time.Duration
+	AuditRetention       time.Duration
+	RollupPeriod         time.Duration
+	RateCacheTTL         time.Duration
+	SignatureMaxSkew     time.Duration
+	WebhookPollPeriod    time.Duration
+	WebhookMaxAttempts   int
+	WebhookBackoff       time.Duration
+	WebhookTimeout       time.Duration
+	WebhookAllowPrivate  bool
+	EventRetention       time.Duration
+	ComplianceScanPeriod time.Duration
+	ComplianceRules      models.ComplianceRules
 }
 
 type Service struct {
@@ -47,6 +125,9 @@ type Service struct {
 	xrClient    xrClient
 	producer    txProducer
 	metrics     *metrics
+	rates       *rateCache
+	notifier    *notifier
+	webhooks    *http.Client
 }
 
 func New(cfg Config, walletStore walletStore, xrClient xrClient, producer txProducer) *Service {
@@ -56,6 +137,9 @@ func New(cfg Config, walletStore walletStore, xrClient xrClient, producer txProd
 		xrClient:    xrClient,
 		producer:    producer,
 		metrics:     newMetrics(),
+		rates:       newRateCache(cfg.RateCacheTTL),
+		notifier:    newNotifier(),
+		webhooks:    newWebhookClient(cfg.WebhookAllowPrivate),
 	}
 }
 
@@ -71,14 +155,38 @@ func (s *Service) Run(ctx context.Context) error {
 			if err := s.walletStore.ArchiveStaleWallets(ctx, s.cfg.PerformCheckPeriod); err != nil {
 				return fmt.Errorf("error while archiving inactive wallets: %w", err)
 			}
+
+			if err := s.purgeAuditRecords(ctx); err != nil {
+				return fmt.Errorf("error while purging audit records: %w", err)
+			}
+
+			if _, err := s.walletStore.PurgeAPIKeySignatures(ctx); err != nil {
+				return fmt.Errorf("error while purging api key signatures: %w", err)
+			}
+
+			if err := s.purgeWalletEvents(ctx); err != nil {
+				return fmt.Errorf("error while purging wallet events: %w", err)
+			}
 		}
 	}
 }
 
 func (s *Service) CreateWallet(ctx context.Context, wallet models.Wallet, userID models.UserID) (models.Wallet, error) {
-	wallet, err := s.walletStore.CreateWallet(ctx, wallet, userID)
-	if err != nil {
-		return models.Wallet{}, fmt.Errorf("failed to create wallet: %w", err)
+	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureUserActive(ctx, userID); err != nil {
+			return err
+		}
+
+		var err error
+
+		wallet, err = s.walletStore.CreateWallet(ctx, wallet, userID)
+		if err != nil {
+			return fmt.Errorf("failed to create wallet: %w", err)
+		}
+
+		return s.enqueueWebhooks(ctx, models.WebhookEventWalletCreated, userID, wallet, wallet.WalletID)
+	}); err != nil {
+		return models.Wallet{}, fmt.Errorf("error in DoWithTX(): %w", err)
 	}
 
 	dbWallet, err := s.walletStore.GetWallet(ctx, wallet.WalletID, userID)
@@ -116,11 +224,19 @@ func (s *Service) UpdateWallet(ctx context.Context, walletID models.WalletID, ne
 	var updatedWallet models.Wallet
 
 	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureUserActive(ctx, userID); err != nil {
+			return err
+		}
+
 		dbWallet, err := s.walletStore.GetWallet(ctx, walletID, userID)
 		if err != nil {
 			return fmt.Errorf("wallet not found: %w", err)
 		}
 
+		if err = authorize(dbWallet, models.MemberRoleOwner); err != nil {
+			return err
+		}
+
 		if newInfoWallet.WalletName == "" {
 			newInfoWallet.WalletName = dbWallet.WalletName
 		}
@@ -143,7 +259,7 @@ func (s *Service) UpdateWallet(ctx context.Context, walletID models.WalletID, ne
 			return fmt.Errorf("failed to update wallet: %w", err)
 		}
 
-		return nil
+		return s.enqueueWebhooks(ctx, models.WebhookEventWalletUpdated, userID, updatedWallet, walletID)
 	}); err != nil {
 		return models.Wallet{}, fmt.Errorf("error in DoWithTX(): %w", err)
 	}
@@ -152,17 +268,33 @@ func (s *Service) UpdateWallet(ctx context.Context, walletID models.WalletID, ne
 }
 
 func (s *Service) DeleteWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) error {
-	if err := s.walletStore.DeleteWallet(ctx, walletID, userID); err != nil {
+	wallet, err := s.walletStore.GetWallet(ctx, walletID, userID)
+	if err != nil {
 		return fmt.Errorf("failed to delete: %w", err)
 	}
 
+	if err = authorize(wallet, models.MemberRoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
+		if err := s.walletStore.DeleteWallet(ctx, walletID, userID); err != nil {
+			return fmt.Errorf("failed to delete: %w", err)
+		}
+
+		return s.enqueueWebhooks(ctx, models.WebhookEventWalletDeleted, userID, wallet, walletID)
+	}); err != nil {
+		return fmt.Errorf("error in DoWithTX(): %w", err)
+	}
+
 	return nil
 }
 
-func (s *Service) GetAllWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) ([]models.Wallet, error) {
+//nolint:lll
+func (s *Service) GetAllWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) (models.WalletsPage, error) {
 	wallets, err := s.walletStore.GetWallets(ctx, request, userID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting wallets info: %w", err)
+		return models.WalletsPage{}, fmt.Errorf("error getting wallets info: %w", err)
 	}
 
 	return wallets, nil
@@ -182,6 +314,10 @@ func (s *Service) Deposit(ctx context.Context, transaction models.Transaction, u
 	}()
 
 	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureUserActive(ctx, userID); err != nil {
+			return err
+		}
+
 		dbWallet, err := s.walletStore.GetWallet(ctx, *transaction.ToWalletID, userID)
 		if err != nil {
 			return fmt.Errorf("wallet not found: %w", err)
@@ -196,15 +332,20 @@ func (s *Service) Deposit(ctx context.Context, transaction models.Transaction, u
 			}
 		}
 
-		if err := s.walletStore.Deposit(ctx, transaction, userID, rate); err != nil {
+		if err := authorizeSpend(dbWallet, transaction.Amount*rate); err != nil {
+			return err
+		}
+
+		stored, err := s.walletStore.Deposit(ctx, transaction, userID, rate)
+		if err != nil {
 			return fmt.Errorf("failed deposit: %w", err)
 		}
 
-		if err := s.producer.ProduceTxToKafka(transaction); err != nil {
+		if err := s.producer.ProduceTxToKafka(stored); err != nil {
 			return fmt.Errorf("failed to produce deposit transaction: %w", err)
 		}
 
-		return nil
+		return s.enqueueWebhooks(ctx, models.WebhookEventDeposit, userID, stored, stored.WalletIDs()...)
 	}); err != nil {
 		return fmt.Errorf("error in DoWithTX(): %w", err)
 	}
@@ -226,37 +367,13 @@ func (s *Service) Withdraw(ctx context.Context, transaction models.Transaction,
 	}()
 
 	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
-		dbWallet, err := s.walletStore.GetWallet(ctx, *transaction.FromWalletID, userID)
-		if err != nil {
-			return fmt.Errorf("wallet not found: %w", err)
-		}
-
-		rate := defaultRate
-
-		if !strings.EqualFold(dbWallet.Currency, transaction.Currency) {
-			rate, err = s.xrClient.GetRate(ctx, transaction.Currency, dbWallet.Currency)
-			if err != nil {
-				return fmt.Errorf("failed to obtain exchange rate: %w", err)
-			}
-		}
-
-		if dbWallet.Balance < transaction.Amount*rate {
-			return models.ErrInsufficientFunds
-		}
-
-		if err := s.walletStore.Withdraw(ctx, transaction, userID, rate); err != nil {
-			return fmt.Errorf("failed withdrawal: %w", err)
-		}
-
-		if err := s.producer.ProduceTxToKafka(transaction); err != nil {
-			return fmt.Errorf("failed to produce withdrawFunds transaction: %w", err)
-		}
-
-		return nil
+		return s.withdrawTx(ctx, transaction, userID, true)
 	}); err != nil {
-		return fmt.Errorf("error in DoWithTX(): %w", err)
+		return s.screenedError(ctx, err)
 	}
 
+	s.evaluateWalletBudgets(ctx, transaction.FromWalletID)
+
 	return nil
 }
 
@@ -274,56 +391,146 @@ func (s *Service) Transfer(ctx context.Context, transaction models.Transaction,
 	}()
 
 	if err := s.walletStore.DoWithTx(ctx, func(ctx context.Context) error {
-		dbFromTransferWallet, err := s.walletStore.GetWallet(ctx, *transaction.FromWalletID, userID)
-		if err != nil {
-			return fmt.Errorf("wallet not found: %w", err)
-		}
+		return s.transferTx(ctx, transaction, userID, true)
+	}); err != nil {
+		return s.screenedError(ctx, err)
+	}
+
+	s.evaluateWalletBudgets(ctx, transaction.FromWalletID)
+
+	return nil
+}
+
+// withdrawTx withdraws within the caller's transaction. Unless screen is false, the withdrawal
+// is screened for risk right before it is committed.
+func (s *Service) withdrawTx(ctx context.Context, transaction models.Transaction, userID models.UserID, screen bool) error {
+	if err := s.ensureUserActive(ctx, userID); err != nil {
+		return err
+	}
+
+	dbWallet, err := s.walletStore.GetWallet(ctx, *transaction.FromWalletID, userID)
+	if err != nil {
+		return fmt.Errorf("wallet not found: %w", err)
+	}
 
-		dbToTransferWallet, err := s.walletStore.GetWallet(ctx, *transaction.ToWalletID, userID)
+	rate := defaultRate
+
+	if !strings.EqualFold(dbWallet.Currency, transaction.Currency) {
+		rate, err = s.xrClient.GetRate(ctx, transaction.Currency, dbWallet.Currency)
 		if err != nil {
-			return fmt.Errorf("wallet not found: %w", err)
+			return fmt.Errorf("failed to obtain exchange rate: %w", err)
 		}
+	}
 
-		if !strings.EqualFold(transaction.Currency, dbFromTransferWallet.Currency) {
-			return models.ErrWrongCurrency
-		}
+	if err := authorizeSpend(dbWallet, transaction.Amount*rate); err != nil {
+		return err
+	}
 
-		rate := defaultRate
+	if dbWallet.Balance < transaction.Amount*rate {
+		return models.ErrInsufficientFunds
+	}
 
-		if dbFromTransferWallet.Currency != dbToTransferWallet.Currency {
-			rate, err = s.xrClient.GetRate(ctx, dbFromTransferWallet.Currency, dbToTransferWallet.Currency)
-			if err != nil {
-				return fmt.Errorf("failed to obtain exchange rate: %w", err)
-			}
+	if screen {
+		if err := s.screen(ctx, models.RiskCheck{UserID: userID, Transaction: transaction, At: time.Now()}); err != nil {
+			return err
 		}
+	}
 
-		if dbFromTransferWallet.Currency == strings.ToUpper(transaction.Currency) {
-			if dbFromTransferWallet.Balance < transaction.Amount {
-				return models.ErrInsufficientFunds
-			}
+	stored, err := s.walletStore.Withdraw(ctx, transaction, userID, rate)
+	if err != nil {
+		return fmt.Errorf("failed withdrawal: %w", err)
+	}
+
+	if err := s.producer.ProduceTxToKafka(stored); err != nil {
+		return fmt.Errorf("failed to produce withdrawFunds transaction: %w", err)
+	}
+
+	return s.enqueueWebhooks(ctx, models.WebhookEventWithdraw, userID, stored, stored.WalletIDs()...)
+}
+
+// transferTx transfers within the caller's transaction. Unless screen is false, the transfer
+// is screened for risk right before it is committed.
+func (s *Service) transferTx(ctx context.Context, transaction models.Transaction, userID models.UserID, screen bool) error {
+	if err := s.ensureUserActive(ctx, userID); err != nil {
+		return err
+	}
+
+	dbFromTransferWallet, err := s.walletStore.GetWallet(ctx, *transaction.FromWalletID, userID)
+	if err != nil {
+		return fmt.Errorf("wallet not found: %w", err)
+	}
+
+	dbToTransferWallet, err := s.walletStore.GetWallet(ctx, *transaction.ToWalletID, userID)
+	if err != nil {
+		return fmt.Errorf("wallet not found: %w", err)
+	}
+
+	if err := authorizeSpend(dbFromTransferWallet, transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := authorize(dbToTransferWallet, models.MemberRoleOwner, models.MemberRoleSpender); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(transaction.Currency, dbFromTransferWallet.Currency) {
+		return models.ErrWrongCurrency
+	}
+
+	rate := defaultRate
+
+	if dbFromTransferWallet.Currency != dbToTransferWallet.Currency {
+		rate, err = s.xrClient.GetRate(ctx, dbFromTransferWallet.Currency, dbToTransferWallet.Currency)
+		if err != nil {
+			return fmt.Errorf("failed to obtain exchange rate: %w", err)
 		}
+	}
 
-		if err := s.walletStore.Transfer(ctx, transaction, userID, rate); err != nil {
-			return fmt.Errorf("transfer of funds failed: %w", err)
+	if dbFromTransferWallet.Currency == strings.ToUpper(transaction.Currency) {
+		if dbFromTransferWallet.Balance < transaction.Amount {
+			return models.ErrInsufficientFunds
 		}
+	}
+
+	if screen {
+		check := models.RiskCheck{UserID: userID, Transaction: transaction, ToCurrency: dbToTransferWallet.Currency, At: time.Now()}
 
-		if err := s.producer.ProduceTxToKafka(transaction); err != nil {
-			return fmt.Errorf("failed to produce transfer transaction: %w", err)
+		if err := s.screen(ctx, check); err != nil {
+			return err
 		}
+	}
 
-		return nil
-	}); err != nil {
-		return fmt.Errorf("error in DoWithTX(): %w", err)
+	stored, err := s.walletStore.Transfer(ctx, transaction, userID, rate)
+	if err != nil {
+		return fmt.Errorf("transfer of funds failed: %w", err)
+	}
+
+	if err := s.producer.ProduceTxToKafka(stored); err != nil {
+		return fmt.Errorf("failed to produce transfer transaction: %w", err)
+	}
+
+	return s.enqueueWebhooks(ctx, models.WebhookEventTransfer, userID, stored, stored.WalletIDs()...)
+}
+
+// ensureUserActive stops deleted users from creating or changing wallets and moving money.
+func (s *Service) ensureUserActive(ctx context.Context, userID models.UserID) error {
+	deleted, err := s.walletStore.UserDeleted(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+
+	if deleted {
+		return models.ErrUserDeleted
 	}
 
 	return nil
 }
 
 //nolint:lll
-func (s *Service) GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.Transaction, error) {
+func (s *Service) GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) (models.TransactionsPage, error) {
 	transactions, err := s.walletStore.GetTransactions(ctx, request, walletID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting all the transactions info: %w", err)
+		return models.TransactionsPage{}, fmt.Errorf("error getting all the transactions info: %w", err)
 	}
 
 	return transactions, nil
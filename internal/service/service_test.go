@@ -3,6 +3,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
@@ -18,6 +19,8 @@ import (
 var (
 	testMetrics *metrics
 	metricsOnce sync.Once
+
+	ownerMembership = &models.WalletMember{Role: models.MemberRoleOwner}
 )
 
 func getTestMetrics() *metrics {
@@ -34,6 +37,8 @@ func TestDeposit(t *testing.T) {
 	userID := models.UserID(uuid.New())
 	walletID := models.WalletID(uuid.New())
 	now := time.Now()
+	externalReference := "psp-0001"
+	storedID := models.TxID(uuid.New())
 
 	tests := []struct {
 		name        string
@@ -65,13 +70,15 @@ func TestDeposit(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
-				ws.EXPECT().Deposit(ctx, gomock.Any(), userID, 1.0).Return(nil)
+				ws.EXPECT().Deposit(ctx, gomock.Any(), userID, 1.0).Return(models.Transaction{}, nil)
 				tp.EXPECT().ProduceTxToKafka(gomock.Any()).Return(nil)
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).Return(nil)
 			},
 		},
 		{
@@ -96,14 +103,16 @@ func TestDeposit(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "EUR", "USD").Return(1.11, nil)
-				ws.EXPECT().Deposit(ctx, gomock.Any(), userID, 1.11).Return(nil)
+				ws.EXPECT().Deposit(ctx, gomock.Any(), userID, 1.11).Return(models.Transaction{}, nil)
 				tp.EXPECT().ProduceTxToKafka(gomock.Any()).Return(nil)
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).Return(nil)
 			},
 		},
 		{
@@ -128,15 +137,93 @@ func TestDeposit(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "NIO", "USD").Return(0.0, models.ErrWrongCurrency)
 			},
 			expectedErr: models.ErrWrongCurrency,
 		},
+		{
+			name: "details carried to kafka event",
+			transaction: models.Transaction{
+				ToWalletID:        &walletID,
+				Amount:            100.0,
+				Currency:          "USD",
+				CommittedAt:       now,
+				Description:       "invoice payment",
+				ExternalReference: &externalReference,
+				Metadata:          map[string]string{"orderId": "42"},
+			},
+			setupMocks: func(ws *mocks.MockwalletStore, xr *mocks.MockxrClient, tp *mocks.MocktxProducer) {
+				ws.EXPECT().DoWithTx(ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					},
+				)
+				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Membership: ownerMembership,
+				}, nil)
+				ws.EXPECT().Deposit(ctx, gomock.Any(), userID, 1.0).DoAndReturn(
+					func(_ context.Context, transaction models.Transaction, _ models.UserID, _ float64) (models.Transaction, error) {
+						transaction.ID = storedID
+						transaction.PerformedBy = &userID
+
+						return transaction, nil
+					},
+				)
+				tp.EXPECT().ProduceTxToKafka(gomock.Any()).DoAndReturn(func(transaction models.Transaction) error {
+					require.Equal(t, storedID, transaction.ID)
+					require.Equal(t, userID, *transaction.PerformedBy)
+					require.Equal(t, "invoice payment", transaction.Description)
+					require.Equal(t, externalReference, *transaction.ExternalReference)
+					require.Equal(t, "42", transaction.Metadata["orderId"])
+
+					return nil
+				})
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event models.WebhookEvent) error {
+					var transaction models.Transaction
+
+					require.NoError(t, json.Unmarshal(event.Data, &transaction))
+					require.Equal(t, storedID, transaction.ID)
+					require.Equal(t, userID, *transaction.PerformedBy)
+					require.Equal(t, []models.WalletID{walletID}, event.WalletIDs)
+
+					return nil
+				})
+			},
+		},
+		{
+			name: "duplicate external reference",
+			transaction: models.Transaction{
+				ToWalletID:        &walletID,
+				Amount:            100.0,
+				Currency:          "USD",
+				CommittedAt:       now,
+				ExternalReference: &externalReference,
+			},
+			setupMocks: func(ws *mocks.MockwalletStore, xr *mocks.MockxrClient, tp *mocks.MocktxProducer) {
+				ws.EXPECT().DoWithTx(ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					},
+				)
+				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Membership: ownerMembership,
+				}, nil)
+				ws.EXPECT().Deposit(ctx, gomock.Any(), userID, 1.0).Return(models.Transaction{}, models.ErrDuplicateExternalReference)
+			},
+			expectedErr: models.ErrDuplicateExternalReference,
+		},
 	}
 
 	for _, tt := range tests {
@@ -148,6 +235,7 @@ func TestDeposit(t *testing.T) {
 			mockXRClient := mocks.NewMockxrClient(ctrl)
 			mockTxProducer := mocks.NewMocktxProducer(ctrl)
 
+			mockWalletStore.EXPECT().UserDeleted(ctx, userID).Return(false, nil).AnyTimes()
 			tt.setupMocks(mockWalletStore, mockXRClient, mockTxProducer)
 
 			svc := &Service{
@@ -208,13 +296,18 @@ func TestWithdraw(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
-				ws.EXPECT().Withdraw(ctx, gomock.Any(), userID, 1.0).Return(nil)
+				ws.EXPECT().GetRiskRules(ctx).Return(nil, nil)
+				ws.EXPECT().Withdraw(ctx, gomock.Any(), userID, 1.0).Return(models.Transaction{}, nil)
 				tp.EXPECT().ProduceTxToKafka(gomock.Any()).Return(nil)
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).Return(nil)
+				ws.EXPECT().GetWalletMembers(ctx, walletID).Return([]models.WalletMember{{WalletID: walletID, UserID: userID, Role: models.MemberRoleOwner}}, nil)
+				ws.EXPECT().GetBudgets(ctx, userID).Return(nil, nil)
 			},
 		},
 		{
@@ -239,16 +332,70 @@ func TestWithdraw(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "EUR", "USD").Return(1.11, nil)
-				ws.EXPECT().Withdraw(ctx, gomock.Any(), userID, 1.11).Return(nil)
+				ws.EXPECT().GetRiskRules(ctx).Return(nil, nil)
+				ws.EXPECT().Withdraw(ctx, gomock.Any(), userID, 1.11).Return(models.Transaction{}, nil)
 				tp.EXPECT().ProduceTxToKafka(gomock.Any()).Return(nil)
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).Return(nil)
+				ws.EXPECT().GetWalletMembers(ctx, walletID).Return([]models.WalletMember{{WalletID: walletID, UserID: userID, Role: models.MemberRoleOwner}}, nil)
+				ws.EXPECT().GetBudgets(ctx, userID).Return(nil, nil)
 			},
 		},
+		{
+			name: "viewer cannot withdraw",
+			transaction: models.Transaction{
+				FromWalletID: &walletID,
+				Amount:       100.0,
+				Currency:     "USD",
+				CommittedAt:  now,
+			},
+			setupMocks: func(ws *mocks.MockwalletStore, xr *mocks.MockxrClient, tp *mocks.MocktxProducer) {
+				ws.EXPECT().DoWithTx(ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					},
+				)
+				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
+					WalletID:   walletID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: &models.WalletMember{Role: models.MemberRoleViewer},
+				}, nil)
+			},
+			expectedErr: models.ErrInsufficientRights,
+		},
+		{
+			name: "spender above spend limit",
+			transaction: models.Transaction{
+				FromWalletID: &walletID,
+				Amount:       100.0,
+				Currency:     "EUR",
+				CommittedAt:  now,
+			},
+			setupMocks: func(ws *mocks.MockwalletStore, xr *mocks.MockxrClient, tp *mocks.MocktxProducer) {
+				limit := 100.0
+
+				ws.EXPECT().DoWithTx(ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					},
+				)
+				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
+					WalletID:   walletID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: &models.WalletMember{Role: models.MemberRoleSpender, SpendLimit: &limit},
+				}, nil)
+				xr.EXPECT().GetRate(ctx, "EUR", "USD").Return(1.11, nil)
+			},
+			expectedErr: models.ErrSpendLimitExceeded,
+		},
 		{
 			name: "insufficient funds with same currency",
 			transaction: models.Transaction{
@@ -270,10 +417,11 @@ func TestWithdraw(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 			},
 			expectedErr: models.ErrInsufficientFunds,
@@ -299,10 +447,11 @@ func TestWithdraw(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "RUB",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "RUB",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "USD", "RUB").Return(90.0, nil)
 			},
@@ -330,10 +479,11 @@ func TestWithdraw(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(models.Wallet{
-					WalletID: walletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  500.0,
+					WalletID:   walletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "RUS", "USD").Return(0.0, models.ErrWrongCurrency)
 			},
@@ -350,6 +500,7 @@ func TestWithdraw(t *testing.T) {
 			mockXRClient := mocks.NewMockxrClient(ctrl)
 			mockTxProducer := mocks.NewMocktxProducer(ctrl)
 
+			mockWalletStore.EXPECT().UserDeleted(ctx, userID).Return(false, nil).AnyTimes()
 			tt.setupMocks(mockWalletStore, mockXRClient, mockTxProducer)
 
 			svc := &Service{
@@ -417,19 +568,25 @@ func TestTransfer(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, fromWalletID, userID).Return(models.Wallet{
-					WalletID: fromWalletID,
-					UserID:   userID,
-					Currency: "CHF",
-					Balance:  500.0,
+					WalletID:   fromWalletID,
+					UserID:     userID,
+					Currency:   "CHF",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				ws.EXPECT().GetWallet(ctx, toWalletID, userID).Return(models.Wallet{
-					WalletID: toWalletID,
-					UserID:   userID,
-					Currency: "CHF",
-					Balance:  200.0,
+					WalletID:   toWalletID,
+					UserID:     userID,
+					Currency:   "CHF",
+					Balance:    200.0,
+					Membership: ownerMembership,
 				}, nil)
-				ws.EXPECT().Transfer(ctx, gomock.Any(), userID, 1.0).Return(nil)
+				ws.EXPECT().GetRiskRules(ctx).Return(nil, nil)
+				ws.EXPECT().Transfer(ctx, gomock.Any(), userID, 1.0).Return(models.Transaction{}, nil)
 				tp.EXPECT().ProduceTxToKafka(gomock.Any()).Return(nil)
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).Return(nil)
+				ws.EXPECT().GetWalletMembers(ctx, fromWalletID).Return([]models.WalletMember{{WalletID: fromWalletID, UserID: userID, Role: models.MemberRoleOwner}}, nil)
+				ws.EXPECT().GetBudgets(ctx, userID).Return(nil, nil)
 			},
 		},
 		{
@@ -461,20 +618,26 @@ func TestTransfer(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, fromWalletID, userID).Return(models.Wallet{
-					WalletID: fromWalletID,
-					UserID:   userID,
-					Currency: "USD",
-					Balance:  60.0,
+					WalletID:   fromWalletID,
+					UserID:     userID,
+					Currency:   "USD",
+					Balance:    60.0,
+					Membership: ownerMembership,
 				}, nil)
 				ws.EXPECT().GetWallet(ctx, toWalletID, userID).Return(models.Wallet{
-					WalletID: toWalletID,
-					UserID:   userID,
-					Currency: "RUB",
-					Balance:  200.0,
+					WalletID:   toWalletID,
+					UserID:     userID,
+					Currency:   "RUB",
+					Balance:    200.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "USD", "RUB").Return(90.0, nil)
-				ws.EXPECT().Transfer(ctx, gomock.Any(), userID, 90.0).Return(nil)
+				ws.EXPECT().GetRiskRules(ctx).Return(nil, nil)
+				ws.EXPECT().Transfer(ctx, gomock.Any(), userID, 90.0).Return(models.Transaction{}, nil)
 				tp.EXPECT().ProduceTxToKafka(gomock.Any()).Return(nil)
+				ws.EXPECT().EnqueueWebhookEvent(ctx, gomock.Any()).Return(nil)
+				ws.EXPECT().GetWalletMembers(ctx, fromWalletID).Return([]models.WalletMember{{WalletID: fromWalletID, UserID: userID, Role: models.MemberRoleOwner}}, nil)
+				ws.EXPECT().GetBudgets(ctx, userID).Return(nil, nil)
 			},
 		},
 		{
@@ -505,16 +668,18 @@ func TestTransfer(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, fromWalletID, userID).Return(models.Wallet{
-					WalletID: fromWalletID,
-					UserID:   userID,
-					Currency: "CNY",
-					Balance:  500.0,
+					WalletID:   fromWalletID,
+					UserID:     userID,
+					Currency:   "CNY",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				ws.EXPECT().GetWallet(ctx, toWalletID, userID).Return(models.Wallet{
-					WalletID: toWalletID,
-					UserID:   userID,
-					Currency: "CNY",
-					Balance:  200.0,
+					WalletID:   toWalletID,
+					UserID:     userID,
+					Currency:   "CNY",
+					Balance:    200.0,
+					Membership: ownerMembership,
 				}, nil)
 			},
 			expectedErr: models.ErrInsufficientFunds,
@@ -547,16 +712,18 @@ func TestTransfer(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, fromWalletID, userID).Return(models.Wallet{
-					WalletID: fromWalletID,
-					UserID:   userID,
-					Currency: "CNY",
-					Balance:  500.0,
+					WalletID:   fromWalletID,
+					UserID:     userID,
+					Currency:   "CNY",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				ws.EXPECT().GetWallet(ctx, toWalletID, userID).Return(models.Wallet{
-					WalletID: toWalletID,
-					UserID:   userID,
-					Currency: "CNY",
-					Balance:  200.0,
+					WalletID:   toWalletID,
+					UserID:     userID,
+					Currency:   "CNY",
+					Balance:    200.0,
+					Membership: ownerMembership,
 				}, nil)
 			},
 			expectedErr: models.ErrInsufficientFunds,
@@ -590,16 +757,18 @@ func TestTransfer(t *testing.T) {
 					},
 				)
 				ws.EXPECT().GetWallet(ctx, fromWalletID, userID).Return(models.Wallet{
-					WalletID: fromWalletID,
-					UserID:   userID,
-					Currency: "RSD",
-					Balance:  500.0,
+					WalletID:   fromWalletID,
+					UserID:     userID,
+					Currency:   "RSD",
+					Balance:    500.0,
+					Membership: ownerMembership,
 				}, nil)
 				ws.EXPECT().GetWallet(ctx, toWalletID, userID).Return(models.Wallet{
-					WalletID: toWalletID,
-					UserID:   userID,
-					Currency: "JPY",
-					Balance:  200.0,
+					WalletID:   toWalletID,
+					UserID:     userID,
+					Currency:   "JPY",
+					Balance:    200.0,
+					Membership: ownerMembership,
 				}, nil)
 				xr.EXPECT().GetRate(ctx, "RSD", "JPY").Return(0.0, models.ErrWrongCurrency)
 			},
@@ -616,6 +785,7 @@ func TestTransfer(t *testing.T) {
 			mockXRClient := mocks.NewMockxrClient(ctrl)
 			mockTxProducer := mocks.NewMocktxProducer(ctrl)
 
+			mockWalletStore.EXPECT().UserDeleted(ctx, userID).Return(false, nil).AnyTimes()
 			tt.setupMocks(mockWalletStore, mockXRClient, mockTxProducer)
 
 			svc := &Service{
@@ -636,3 +806,53 @@ func TestTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestUserLifecycle(t *testing.T) {
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+	walletID := models.WalletID(uuid.New())
+	frozenAt := time.Now()
+
+	tests := []struct {
+		name        string
+		deleted     bool
+		wallet      models.Wallet
+		expectedErr error
+	}{
+		{
+			name:        "deleted user cannot deposit",
+			deleted:     true,
+			expectedErr: models.ErrUserDeleted,
+		},
+		{
+			name:        "frozen wallet does not accept deposits",
+			wallet:      models.Wallet{WalletID: walletID, Currency: "USD", Membership: ownerMembership, FrozenAt: &frozenAt},
+			expectedErr: models.ErrWalletFrozen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ws := mocks.NewMockwalletStore(ctrl)
+
+			ws.EXPECT().DoWithTx(ctx, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				},
+			)
+			ws.EXPECT().UserDeleted(ctx, userID).Return(tt.deleted, nil)
+
+			if !tt.deleted {
+				ws.EXPECT().GetWallet(ctx, walletID, userID).Return(tt.wallet, nil)
+			}
+
+			svc := &Service{walletStore: ws, metrics: getTestMetrics()}
+
+			err := svc.Deposit(ctx, models.Transaction{ToWalletID: &walletID, Amount: 100, Currency: "USD"}, userID)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (s *Service) GetUserAccount(ctx context.Context, userID models.UserID) (models.UserAccount, error) {
+	account, err := s.walletStore.GetUserAccount(ctx, userID)
+	if err != nil {
+		return models.UserAccount{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return account, nil
+}
+
+func (s *Service) UpdateUserPreferences(
+	ctx context.Context,
+	userID models.UserID,
+	preferences models.UserPreferences,
+) (models.UserAccount, error) {
+	if err := preferences.Validate(); err != nil {
+		return models.UserAccount{}, err //nolint:wrapcheck
+	}
+
+	if err := s.walletStore.UpdateUserPreferences(ctx, userID, preferences); err != nil {
+		return models.UserAccount{}, fmt.Errorf("failed to update user preferences: %w", err)
+	}
+
+	return s.GetUserAccount(ctx, userID)
+}
+
+func (s *Service) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserAccount, error) {
+	accounts, err := s.walletStore.SearchUsers(ctx, search)
+	if err != nil {
+		return nil, fmt.Errorf("error searching users: %w", err)
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,65 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUserPreferences(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := models.UserID(uuid.New())
+
+	tests := []struct {
+		name        string
+		preferences models.UserPreferences
+		expected    models.UserPreferences
+		expectedErr error
+	}{
+		{name: "empty", preferences: models.UserPreferences{}},
+		{
+			name:        "currency is upper cased",
+			preferences: models.UserPreferences{Currency: " eur ", Timezone: "Asia/Tokyo"},
+			expected:    models.UserPreferences{Currency: "EUR", Timezone: "Asia/Tokyo"},
+		},
+		{name: "invalid currency", preferences: models.UserPreferences{Currency: "euro"}, expectedErr: models.ErrInvalidPreferences},
+		{name: "unknown timezone", preferences: models.UserPreferences{Timezone: "Mars/Olympus"}, expectedErr: models.ErrInvalidPreferences},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ws := mocks.NewMockwalletStore(ctrl)
+
+			if tt.expectedErr == nil {
+				ws.EXPECT().UpdateUserPreferences(ctx, userID, tt.expected).Return(nil)
+				ws.EXPECT().GetUserAccount(ctx, userID).Return(models.UserAccount{UserID: userID, Preferences: tt.expected}, nil)
+			}
+
+			svc := &Service{walletStore: ws}
+
+			account, err := svc.UpdateUserPreferences(ctx, userID, tt.preferences)
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, account.Preferences)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 24
+	webhookBatchSize    = 50
+	webhookErrorLength  = 500
+	maxWebhookBackoff   = 12 * time.Hour
+
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBackoff     = 30 * time.Second
+	defaultWebhookTimeout     = 10 * time.Second
+
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+func (s *Service) CreateWebhook(ctx context.Context, webhook models.Webhook, userID models.UserID) (models.Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return models.Webhook{}, err //nolint:wrapcheck
+	}
+
+	if !s.cfg.WebhookAllowPrivate && !webhook.PublicHost() {
+		return models.Webhook{}, models.ErrInvalidWebhook
+	}
+
+	buf := make([]byte, webhookSecretBytes)
+
+	if _, err := rand.Read(buf); err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	webhook.UserID = userID
+	webhook.Secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	created, err := s.walletStore.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	created.Secret = webhook.Secret
+
+	return created, nil
+}
+
+func (s *Service) GetWebhooks(ctx context.Context, userID models.UserID) ([]models.Webhook, error) {
+	webhooks, err := s.walletStore.GetWebhooks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, webhookID models.WebhookID, userID models.UserID) error {
+	if err := s.walletStore.DeleteWebhook(ctx, webhookID, userID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (s *Service) GetWebhookDeliveries(ctx context.Context, webhookID models.WebhookID, userID models.UserID, status string) ([]models.WebhookDelivery, error) {
+	deliveries, err := s.walletStore.GetWebhookDeliveries(ctx, webhookID, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+//nolint:lll
+func (s *Service) GetWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error) {
+	delivery, err := s.walletStore.GetWebhookDelivery(ctx, webhookID, deliveryID, userID)
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("error getting webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+//nolint:lll
+func (s *Service) RedeliverWebhook(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error) {
+	delivery, err := s.walletStore.RedeliverWebhookDelivery(ctx, webhookID, deliveryID, userID)
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// enqueueWebhooks stores deliveries of the event for the subscribed webhooks of the user and of
+// the members of the wallets.
+//
+//nolint:lll
+func (s *Service) enqueueWebhooks(ctx context.Context, eventType string, userID models.UserID, data any, walletIDs ...models.WalletID) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event data: %w", err)
+	}
+
+	if err = s.walletStore.EnqueueWebhookEvent(ctx, models.WebhookEvent{
+		EventID:   uuid.New(),
+		Type:      eventType,
+		UserID:    userID,
+		Data:      payload,
+		CreatedAt: time.Now().UTC(),
+		WalletIDs: walletIDs,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+
+	return nil
+}
+
+// RunWebhooks sends due webhook deliveries until the context is done.
+func (s *Service) RunWebhooks(ctx context.Context) error {
+	if s.cfg.WebhookPollPeriod <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.cfg.WebhookPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := s.deliverWebhooks(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to deliver webhooks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverWebhooks sends the claimed deliveries concurrently, so all of them finish within the
+// lease of twice the webhook timeout.
+func (s *Service) deliverWebhooks(ctx context.Context) error {
+	dispatches, err := s.walletStore.ClaimWebhookDeliveries(ctx, webhookBatchSize, s.webhookTimeout()*2) //nolint:mnd
+	if err != nil {
+		return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	var group errgroup.Group
+
+	for _, dispatch := range dispatches {
+		group.Go(func() error {
+			attempt := s.sendWebhook(ctx, dispatch)
+
+			status, nextAttemptAt := s.nextDeliveryState(attempt)
+
+			if err := s.walletStore.RecordDeliveryAttempt(ctx, dispatch.DeliveryID, attempt, status, nextAttemptAt); err != nil {
+				return fmt.Errorf("failed to record delivery attempt: %w", err)
+			}
+
+			if status == models.DeliveryStatusDead {
+				log.Warn().Str("deliveryId", uuid.UUID(dispatch.DeliveryID).String()).Msg("webhook delivery moved to dead letter")
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait() //nolint:wrapcheck
+}
+
+// sendWebhook posts the payload once. The signature header carries the unix timestamp and
+// the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" keyed with the webhook secret.
+func (s *Service) sendWebhook(ctx context.Context, dispatch models.WebhookDispatch) models.DeliveryAttempt {
+	start := time.Now()
+	attempt := models.DeliveryAttempt{Attempt: dispatch.Attempts + 1, AttemptedAt: start}
+
+	fail := func(err error) models.DeliveryAttempt {
+		message := err.Error()
+		if len(message) > webhookErrorLength {
+			message = message[:webhookErrorLength]
+		}
+
+		attempt.Error = &message
+		attempt.DurationMs = time.Since(start).Milliseconds()
+
+		return attempt
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.webhookTimeout())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(dispatch.Payload))
+	if err != nil {
+		return fail(err)
+	}
+
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, dispatch.EventType)
+	request.Header.Set(WebhookDeliveryHeader, uuid.UUID(dispatch.DeliveryID).String())
+	request.Header.Set(WebhookSignatureHeader, "t="+timestamp+",v1="+SignWebhook(dispatch.Secret, timestamp, dispatch.Payload))
+
+	response, err := s.webhooks.Do(request)
+	if err != nil {
+		return fail(err)
+	}
+
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	attempt.StatusCode = &response.StatusCode
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fail(fmt.Errorf("unexpected status code %d", response.StatusCode)) //nolint:err113
+	}
+
+	attempt.DurationMs = time.Since(start).Milliseconds()
+
+	return attempt
+}
+
+// nextDeliveryState retries failed attempts with exponential backoff until the attempts are
+// exhausted, after which the delivery is dead until it is redelivered manually.
+func (s *Service) nextDeliveryState(attempt models.DeliveryAttempt) (string, *time.Time) {
+	if attempt.Error == nil {
+		return models.DeliveryStatusDelivered, nil
+	}
+
+	maxAttempts := s.cfg.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+
+	if attempt.Attempt >= maxAttempts {
+		return models.DeliveryStatusDead, nil
+	}
+
+	backoff := s.cfg.WebhookBackoff
+	if backoff <= 0 {
+		backoff = defaultWebhookBackoff
+	}
+
+	delay := backoff
+	for i := 1; i < attempt.Attempt && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+
+	next := attempt.AttemptedAt.Add(min(delay, maxWebhookBackoff))
+
+	return models.DeliveryStatusPending, &next
+}
+
+// newWebhookClient neither follows redirects nor, unless private addresses are allowed, connects
+// to addresses that are not public, so a webhook URL that resolves or redirects to an internal
+// host cannot reach it.
+func newWebhookClient(privateAddresses bool) *http.Client {
+	dialer := &net.Dialer{}
+
+	if !privateAddresses {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("failed to parse webhook address: %w", err)
+			}
+
+			if !models.PublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s is not a public address", models.ErrInvalidWebhook, addrPort.Addr())
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *Service) webhookTimeout() time.Duration {
+	if s.cfg.WebhookTimeout <= 0 {
+		return defaultWebhookTimeout
+	}
+
+	return s.cfg.WebhookTimeout
+}
+
+// SignWebhook returns the signature receivers compare against the v1 part of the signature header.
+func SignWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
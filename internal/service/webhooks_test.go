@@ -0,0 +1,165 @@
+//nolint:testpackage
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliverWebhooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	secret := "whsec_test"
+	payload := []byte(`{"type":"transaction.deposit"}`)
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		attempts       int
+		expectedStatus string
+		expectedDelay  time.Duration
+	}{
+		{
+			name:           "delivered",
+			responseStatus: http.StatusNoContent,
+			expectedStatus: models.DeliveryStatusDelivered,
+		},
+		{
+			name:           "retried with backoff",
+			responseStatus: http.StatusInternalServerError,
+			attempts:       2,
+			expectedStatus: models.DeliveryStatusPending,
+			expectedDelay:  4 * time.Second,
+		},
+		{
+			name:           "dead after the last attempt",
+			responseStatus: http.StatusBadGateway,
+			attempts:       3,
+			expectedStatus: models.DeliveryStatusDead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, payload, body)
+				require.Equal(t, models.WebhookEventDeposit, r.Header.Get(WebhookEventHeader))
+
+				timestamp, signature, ok := strings.Cut(r.Header.Get(WebhookSignatureHeader), ",v1=")
+				require.True(t, ok)
+				require.Equal(t, SignWebhook(secret, strings.TrimPrefix(timestamp, "t="), body), signature)
+
+				w.WriteHeader(tt.responseStatus)
+			}))
+			defer receiver.Close()
+
+			dispatch := models.WebhookDispatch{
+				WebhookDelivery: models.WebhookDelivery{
+					DeliveryID: models.DeliveryID(uuid.New()),
+					EventType:  models.WebhookEventDeposit,
+					Payload:    payload,
+					Attempts:   tt.attempts,
+				},
+				URL:    receiver.URL,
+				Secret: secret,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWalletStore := mocks.NewMockwalletStore(ctrl)
+			mockWalletStore.EXPECT().ClaimWebhookDeliveries(ctx, webhookBatchSize, gomock.Any()).
+				Return([]models.WebhookDispatch{dispatch}, nil)
+			mockWalletStore.EXPECT().RecordDeliveryAttempt(ctx, dispatch.DeliveryID, gomock.Any(), tt.expectedStatus, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ models.DeliveryID, attempt models.DeliveryAttempt, _ string, next *time.Time) error {
+					require.Equal(t, tt.attempts+1, attempt.Attempt)
+					require.Equal(t, tt.responseStatus, *attempt.StatusCode)
+
+					if tt.expectedDelay == 0 {
+						require.Nil(t, next)
+					} else {
+						require.Equal(t, attempt.AttemptedAt.Add(tt.expectedDelay), *next)
+					}
+
+					return nil
+				})
+
+			svc := &Service{
+				cfg: Config{
+					WebhookMaxAttempts: 4,
+					WebhookBackoff:     time.Second,
+				},
+				walletStore: mockWalletStore,
+				metrics:     getTestMetrics(),
+				webhooks:    receiver.Client(),
+			}
+
+			require.NoError(t, svc.deliverWebhooks(ctx))
+		})
+	}
+}
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	t.Parallel()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	svc := &Service{webhooks: newWebhookClient(false)}
+
+	attempt := svc.sendWebhook(context.Background(), models.WebhookDispatch{
+		WebhookDelivery: models.WebhookDelivery{
+			DeliveryID: models.DeliveryID(uuid.New()),
+			EventType:  models.WebhookEventDeposit,
+		},
+		URL:    receiver.URL,
+		Secret: "whsec_test",
+	})
+
+	require.NotNil(t, attempt.Error)
+	require.Contains(t, *attempt.Error, "not a public address")
+	require.Nil(t, attempt.StatusCode)
+}
+
+func TestCreateWebhookRejectsInternalURLs(t *testing.T) {
+	t.Parallel()
+
+	for _, url := range []string{
+		"http://localhost:8080/hook",
+		"http://127.0.0.1/hook",
+		"https://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+	} {
+		t.Run(url, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{}
+
+			_, err := svc.CreateWebhook(context.Background(), models.Webhook{
+				URL:        url,
+				EventTypes: []string{models.WebhookEventDeposit},
+			}, models.UserID(uuid.New()))
+			require.ErrorIs(t, err, models.ErrInvalidWebhook)
+		})
+	}
+}
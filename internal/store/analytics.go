@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const (
+	rollupName = "transactions"
+	dayLength  = 24 * time.Hour
+)
+
+// RefreshRollups recomputes the daily transaction rollups starting from the day of the
+// previous watermark minus lag, so that late commits within the lag are still counted.
+// The state row is locked to keep replicas from rebuilding the same range concurrently.
+func (d *DataStore) RefreshRollups(ctx context.Context, lag time.Duration) error {
+	if err := d.DoWithTx(ctx, func(ctx context.Context) error {
+		tx := d.getTXFromCtx(ctx)
+
+		var watermark *time.Time
+
+		if err := tx.QueryRow(ctx, `SELECT watermark FROM rollup_state WHERE name = $1 FOR UPDATE`, rollupName).
+			Scan(&watermark); err != nil {
+			return fmt.Errorf("failed to read rollup watermark: %w", err)
+		}
+
+		now := time.Now()
+
+		var since time.Time
+		if watermark != nil {
+			since = watermark.Add(-lag).UTC().Truncate(dayLength)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM transaction_rollups WHERE bucket >= $1::date`, since); err != nil {
+			return fmt.Errorf("failed to clear rollups: %w", err)
+		}
+
+		query := `
+INSERT INTO transaction_rollups (wallet_id, user_id, bucket, transaction_type, currency, inflow, outflow, tx_count)
+SELECT e.wallet_id, w.user_id, (e.committed_at AT TIME ZONE 'UTC')::date, e.transaction_type, e.currency,
+	SUM(e.inflow), SUM(e.outflow), COUNT(*)
+FROM (
+	SELECT to_wallet_id AS wallet_id, committed_at, transaction_type, currency, amount AS inflow, 0 AS outflow
+	FROM transactions
+	WHERE to_wallet_id IS NOT NULL AND committed_at >= $1
+	UNION ALL
+	SELECT from_wallet_id, committed_at, transaction_type, currency, 0, amount
+	FROM transactions
+	WHERE from_wallet_id IS NOT NULL AND committed_at >= $1
+) e
+JOIN wallets w ON w.wallet_id = e.wallet_id
+GROUP BY 1, 2, 3, 4, 5`
+
+		if _, err := tx.Exec(ctx, query, since); err != nil {
+			return fmt.Errorf("failed to build rollups: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE rollup_state SET watermark = $2 WHERE name = $1`, rollupName, now); err != nil {
+			return fmt.Errorf("failed to move rollup watermark: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error refreshing rollups: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (d *DataStore) GetAnalytics(ctx context.Context, request models.AnalyticsRequest, userID models.UserID) ([]models.AnalyticsBucket, error) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	args = append(args, request.Granularity)
+	sb.WriteString(`SELECT date_trunc($1, bucket)::date, transaction_type, currency, SUM(inflow), SUM(outflow), SUM(tx_count)::bigint
+FROM transaction_rollups
+WHERE TRUE`)
+
+	// Access to a single wallet is checked by the caller. Across wallets, the buckets of every
+	// wallet the user is a member of are counted, as the rollups only record the owner.
+	if request.WalletID != nil {
+		args = append(args, request.WalletID)
+		sb.WriteString(fmt.Sprintf(` AND wallet_id = $%d`, len(args)))
+	} else {
+		args = append(args, userID)
+		sb.WriteString(fmt.Sprintf(` AND wallet_id IN (SELECT wallet_id FROM wallet_members WHERE user_id = $%d)`, len(args)))
+	}
+
+	// The bounds were aligned to periods in UTC by Validate. They are passed as dates, as casting
+	// a timestamp to a date would depend on the time zone of the session.
+	if request.From != nil {
+		args = append(args, request.From.UTC().Format(time.DateOnly))
+		sb.WriteString(fmt.Sprintf(` AND bucket >= $%d::date`, len(args)))
+	}
+
+	if request.To != nil {
+		args = append(args, request.To.UTC().Format(time.DateOnly))
+		sb.WriteString(fmt.Sprintf(` AND bucket < $%d::date`, len(args)))
+	}
+
+	sb.WriteString(` GROUP BY 1, 2, 3 ORDER BY 1, 2, 3`)
+
+	rows, err := d.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting analytics: %w", err)
+	}
+
+	defer rows.Close()
+
+	buckets := make([]models.AnalyticsBucket, 0)
+
+	for rows.Next() {
+		var bucket models.AnalyticsBucket
+
+		if err = rows.Scan(
+			&bucket.PeriodStart,
+			&bucket.Type,
+			&bucket.Currency,
+			&bucket.Inflow,
+			&bucket.Outflow,
+			&bucket.Count,
+		); err != nil {
+			return nil, fmt.Errorf("error when scanning analytics: %w", err)
+		}
+
+		bucket.Net = bucket.Inflow - bucket.Outflow
+		buckets = append(buckets, bucket)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return buckets, nil
+}
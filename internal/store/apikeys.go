@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const apiKeyColumns = `key_id, user_id, name, scopes, created_at, rotated_at, last_used_at, revoked_at`
+
+func (d *DataStore) CreateAPIKey(ctx context.Context, key models.APIKey, signingKey []byte) (models.APIKey, error) {
+	encrypted, err := d.pii.Encrypt(string(signingKey))
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("failed to encrypt api key signing key: %w", err)
+	}
+
+	query := `
+INSERT INTO api_keys (key_id, user_id, name, scopes, encrypted_signing_key)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING ` + apiKeyColumns
+
+	created, err := scanAPIKey(d.pool.QueryRow(ctx, query, key.KeyID, key.UserID, key.Name, key.Scopes, encrypted))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return models.APIKey{}, models.ErrUserNotFound
+		}
+
+		return models.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return created, nil
+}
+
+func (d *DataStore) GetAPIKeys(ctx context.Context, userID models.UserID) ([]models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at, key_id`
+
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api keys: %w", err)
+	}
+
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning api keys: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeySecret returns an active key together with its decrypted signing key for request verification.
+func (d *DataStore) GetAPIKeySecret(ctx context.Context, keyID models.APIKeyID) (models.APIKey, []byte, error) {
+	query := `
+SELECT ` + apiKeyColumns + `, encrypted_signing_key FROM api_keys
+WHERE key_id = $1 AND revoked_at IS NULL`
+
+	var (
+		key       models.APIKey
+		encrypted []byte
+	)
+
+	if err := d.pool.QueryRow(ctx, query, keyID).Scan(
+		&key.KeyID,
+		&key.UserID,
+		&key.Name,
+		&key.Scopes,
+		&key.CreatedAt,
+		&key.RotatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&encrypted,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIKey{}, nil, models.ErrAPIKeyNotFound
+		}
+
+		return models.APIKey{}, nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	signingKey, err := d.pii.Decrypt(encrypted)
+	if err != nil {
+		return models.APIKey{}, nil, fmt.Errorf("failed to decrypt api key signing key: %w", err)
+	}
+
+	return key, []byte(signingKey), nil
+}
+
+// GetAPIKey returns an active key of the user.
+func (d *DataStore) GetAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	key, err := scanAPIKey(d.pool.QueryRow(ctx, query, keyID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIKey{}, models.ErrAPIKeyNotFound
+		}
+
+		return models.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+//nolint:lll
+func (d *DataStore) RotateAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID, signingKey []byte) (models.APIKey, error) {
+	encrypted, err := d.pii.Encrypt(string(signingKey))
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("failed to encrypt api key signing key: %w", err)
+	}
+
+	query := `
+UPDATE api_keys
+SET encrypted_signing_key = $3, plaintext_signing_key = NULL, rotated_at = NOW()
+WHERE key_id = $1 AND user_id = $2 AND revoked_at IS NULL
+RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(d.pool.QueryRow(ctx, query, keyID, userID, encrypted))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIKey{}, models.ErrAPIKeyNotFound
+		}
+
+		return models.APIKey{}, fmt.Errorf("failed to rotate api key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (d *DataStore) RevokeAPIKey(ctx context.Context, keyID models.APIKeyID, userID models.UserID) error {
+	result, err := d.pool.Exec(ctx, `
+UPDATE api_keys SET revoked_at = NOW()
+WHERE key_id = $1 AND user_id = $2 AND revoked_at IS NULL`, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// RecordAPIKeySignature remembers a verified signature until it expires and marks the key as used.
+// It reports false if the signature has been seen before, i.e. the request is a replay.
+//
+//nolint:lll
+func (d *DataStore) RecordAPIKeySignature(ctx context.Context, keyID models.APIKeyID, signature string, expiresAt time.Time) (bool, error) {
+	query := `
+WITH recorded AS (
+	INSERT INTO api_key_signatures (key_id, signature, expires_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING
+	RETURNING key_id
+)
+UPDATE api_keys SET last_used_at = NOW()
+WHERE key_id IN (SELECT key_id FROM recorded)`
+
+	result, err := d.pool.Exec(ctx, query, keyID, signature, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record api key signature: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// encryptPlaintextSigningKeys encrypts the signing keys of API keys created before they were
+// stored encrypted.
+func (d *DataStore) encryptPlaintextSigningKeys(ctx context.Context) error {
+	rows, err := d.pool.Query(ctx, `
+SELECT key_id, plaintext_signing_key FROM api_keys WHERE plaintext_signing_key IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to get plaintext api key signing keys: %w", err)
+	}
+
+	defer rows.Close()
+
+	plaintext := make(map[models.APIKeyID][]byte)
+
+	for rows.Next() {
+		var (
+			keyID      models.APIKeyID
+			signingKey []byte
+		)
+
+		if err := rows.Scan(&keyID, &signingKey); err != nil {
+			return fmt.Errorf("error when scanning plaintext api key signing keys: %w", err)
+		}
+
+		plaintext[keyID] = signingKey
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	rows.Close()
+
+	for keyID, signingKey := range plaintext {
+		encrypted, err := d.pii.Encrypt(string(signingKey))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt api key signing key: %w", err)
+		}
+
+		if _, err := d.pool.Exec(ctx, `
+UPDATE api_keys SET encrypted_signing_key = $2, plaintext_signing_key = NULL
+WHERE key_id = $1`, keyID, encrypted); err != nil {
+			return fmt.Errorf("failed to store encrypted api key signing key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DataStore) PurgeAPIKeySignatures(ctx context.Context) (int64, error) {
+	result, err := d.pool.Exec(ctx, `DELETE FROM api_key_signatures WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired api key signatures: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func scanAPIKey(row pgx.Row) (models.APIKey, error) {
+	var key models.APIKey
+
+	if err := row.Scan(
+		&key.KeyID,
+		&key.UserID,
+		&key.Name,
+		&key.Scopes,
+		&key.CreatedAt,
+		&key.RotatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	); err != nil {
+		return models.APIKey{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return key, nil
+}
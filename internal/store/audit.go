@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (d *DataStore) InsertAuditRecord(ctx context.Context, record models.AuditRecord) error {
+	query := `
+INSERT INTO audit_log (request_id, user_id, role, method, route, wallet_ids, status, latency_ms, request_body)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	walletIDs := make([]uuid.UUID, 0, len(record.WalletIDs))
+	for _, id := range record.WalletIDs {
+		walletIDs = append(walletIDs, uuid.UUID(id))
+	}
+
+	var body []byte
+	if len(record.RequestBody) > 0 {
+		body = record.RequestBody
+	}
+
+	if _, err := d.pool.Exec(ctx, query,
+		record.RequestID,
+		uuid.UUID(record.UserID),
+		record.Role,
+		record.Method,
+		record.Route,
+		walletIDs,
+		record.Status,
+		record.LatencyMs,
+		body,
+	); err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DataStore) GetAuditRecords(ctx context.Context, request models.AuditQuery) ([]models.AuditRecord, error) {
+	query, args := d.GetAuditRecordsQuery(request)
+
+	rows, err := d.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit records: %w", err)
+	}
+
+	defer rows.Close()
+
+	records := make([]models.AuditRecord, 0)
+
+	for rows.Next() {
+		record, err := scanAuditRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return records, nil
+}
+
+func (d *DataStore) GetAuditRecordsQuery(request models.AuditQuery) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	sb.WriteString(`SELECT id, request_id, user_id, role, method, route, wallet_ids, status, latency_ms, request_body, created_at
+					FROM audit_log
+					WHERE TRUE`)
+
+	if request.UserID != nil {
+		args = append(args, uuid.UUID(*request.UserID))
+		sb.WriteString(fmt.Sprintf(` AND user_id = $%d`, len(args)))
+	}
+
+	if request.WalletID != nil {
+		args = append(args, uuid.UUID(*request.WalletID))
+		sb.WriteString(fmt.Sprintf(` AND $%d = ANY(wallet_ids)`, len(args)))
+	}
+
+	if request.Route != "" {
+		args = append(args, request.Route)
+		sb.WriteString(fmt.Sprintf(` AND route = $%d`, len(args)))
+	}
+
+	if request.Method != "" {
+		args = append(args, strings.ToUpper(request.Method))
+		sb.WriteString(fmt.Sprintf(` AND method = $%d`, len(args)))
+	}
+
+	if request.From != nil {
+		args = append(args, *request.From)
+		sb.WriteString(fmt.Sprintf(` AND created_at >= $%d`, len(args)))
+	}
+
+	if request.To != nil {
+		args = append(args, *request.To)
+		sb.WriteString(fmt.Sprintf(` AND created_at < $%d`, len(args)))
+	}
+
+	sb.WriteString(" ORDER BY created_at DESC, id DESC")
+
+	if request.Limit > 0 {
+		args = append(args, request.Limit)
+		sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	}
+
+	if request.Offset > 0 {
+		args = append(args, request.Offset)
+		sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	}
+
+	return sb.String(), args
+}
+
+func scanAuditRecord(rows pgx.Rows) (models.AuditRecord, error) {
+	var (
+		record    models.AuditRecord
+		userID    uuid.UUID
+		walletIDs []uuid.UUID
+		body      []byte
+	)
+
+	if err := rows.Scan(
+		&record.ID,
+		&record.RequestID,
+		&userID,
+		&record.Role,
+		&record.Method,
+		&record.Route,
+		&walletIDs,
+		&record.Status,
+		&record.LatencyMs,
+		&body,
+		&record.CreatedAt,
+	); err != nil {
+		return models.AuditRecord{}, fmt.Errorf("error when scanning audit record: %w", err)
+	}
+
+	record.UserID = models.UserID(userID)
+	record.RequestBody = body
+	record.WalletIDs = make([]models.WalletID, 0, len(walletIDs))
+
+	for _, id := range walletIDs {
+		record.WalletIDs = append(record.WalletIDs, models.WalletID(id))
+	}
+
+	return record, nil
+}
+
+// PurgeAuditRecords removes audit records older than the retention period.
+// The audit_log table rejects deletes unless audit.retention_purge is set for the transaction.
+func (d *DataStore) PurgeAuditRecords(ctx context.Context, retention time.Duration) (int64, error) {
+	var deleted int64
+
+	if err := d.DoWithTx(ctx, func(ctx context.Context) error {
+		tx := d.getTXFromCtx(ctx)
+
+		if _, err := tx.Exec(ctx, `SELECT set_config('audit.retention_purge', 'on', true)`); err != nil {
+			return fmt.Errorf("failed to enable audit purge: %w", err)
+		}
+
+		result, err := tx.Exec(ctx, `DELETE FROM audit_log WHERE created_at < $1`, time.Now().Add(-retention))
+		if err != nil {
+			return fmt.Errorf("failed to delete expired audit records: %w", err)
+		}
+
+		deleted = result.RowsAffected()
+
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("error purging audit records: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const budgetColumns = `budget_id, user_id, name, wallet_id, category_id, period, amount::float8, currency, thresholds, created_at`
+
+func (d *DataStore) CreateBudget(ctx context.Context, budget models.Budget, userID models.UserID) (models.Budget, error) {
+	if budget.WalletID != nil {
+		if _, err := d.GetWallet(ctx, *budget.WalletID, userID); err != nil {
+			return models.Budget{}, fmt.Errorf("failed to extract wallet: %w", err)
+		}
+	}
+
+	if budget.CategoryID != nil {
+		var exists bool
+
+		if err := d.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE category_id = $1 AND user_id = $2)`,
+			budget.CategoryID, userID).Scan(&exists); err != nil {
+			return models.Budget{}, fmt.Errorf("failed to check category: %w", err)
+		}
+
+		if !exists {
+			return models.Budget{}, models.ErrCategoryNotFound
+		}
+	}
+
+	query := `
+INSERT INTO budgets (budget_id, user_id, name, wallet_id, category_id, period, amount, currency, thresholds)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING ` + budgetColumns
+
+	created, err := scanBudget(d.pool.QueryRow(ctx, query,
+		budget.BudgetID,
+		userID,
+		budget.Name,
+		budget.WalletID,
+		budget.CategoryID,
+		budget.Period,
+		budget.Amount,
+		budget.Currency,
+		budget.Thresholds,
+	))
+	if err != nil {
+		return models.Budget{}, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return created, nil
+}
+
+func (d *DataStore) GetBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) (models.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE budget_id = $1 AND user_id = $2`
+
+	budget, err := scanBudget(d.pool.QueryRow(ctx, query, budgetID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Budget{}, models.ErrBudgetNotFound
+		}
+
+		return models.Budget{}, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	return budget, nil
+}
+
+func (d *DataStore) GetBudgets(ctx context.Context, userID models.UserID) ([]models.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 ORDER BY created_at, budget_id`
+
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting budgets: %w", err)
+	}
+
+	defer rows.Close()
+
+	budgets := make([]models.Budget, 0)
+
+	for rows.Next() {
+		budget, err := scanBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning budgets: %w", err)
+		}
+
+		budgets = append(budgets, budget)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return budgets, nil
+}
+
+func (d *DataStore) DeleteBudget(ctx context.Context, budgetID models.BudgetID, userID models.UserID) error {
+	result, err := d.pool.Exec(ctx, `DELETE FROM budgets WHERE budget_id = $1 AND user_id = $2`, budgetID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting budget: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrBudgetNotFound
+	}
+
+	return nil
+}
+
+func scanBudget(row pgx.Row) (models.Budget, error) {
+	var budget models.Budget
+
+	if err := row.Scan(
+		&budget.BudgetID,
+		&budget.UserID,
+		&budget.Name,
+		&budget.WalletID,
+		&budget.CategoryID,
+		&budget.Period,
+		&budget.Amount,
+		&budget.Currency,
+		&budget.Thresholds,
+		&budget.CreatedAt,
+	); err != nil {
+		return models.Budget{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return budget, nil
+}
+
+// GetBudgetSpending sums the outgoing transactions counted by the budget within [from, to)
+// per transaction currency. Transfers to another wallet the budget owner is a member of are not
+// spending, as no money left their wallets.
+//
+//nolint:lll
+func (d *DataStore) GetBudgetSpending(ctx context.Context, budget models.Budget, from, to time.Time) ([]models.CurrencyAmount, error) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	args = append(args, budget.UserID, from, to)
+	sb.WriteString(`
+SELECT t.currency, SUM(t.amount)::float8
+FROM transactions t
+JOIN wallet_members m ON m.wallet_id = t.from_wallet_id AND m.user_id = $1
+WHERE TRUE
+	AND t.committed_at >= $2
+	AND t.committed_at < $3
+	AND NOT EXISTS (SELECT 1 FROM wallet_members tm WHERE tm.wallet_id = t.to_wallet_id AND tm.user_id = $1)`)
+
+	if budget.WalletID != nil {
+		args = append(args, budget.WalletID)
+		sb.WriteString(fmt.Sprintf(` AND t.from_wallet_id = $%d`, len(args)))
+	}
+
+	if budget.CategoryID != nil {
+		args = append(args, budget.CategoryID)
+		sb.WriteString(fmt.Sprintf(` AND %s = $%d`, transactionCategory("t.id", "$1"), len(args)))
+	}
+
+	sb.WriteString(` GROUP BY t.currency ORDER BY t.currency`)
+
+	rows, err := d.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting budget spending: %w", err)
+	}
+
+	defer rows.Close()
+
+	amounts := make([]models.CurrencyAmount, 0)
+
+	for rows.Next() {
+		var amount models.CurrencyAmount
+
+		if err = rows.Scan(&amount.Currency, &amount.Amount); err != nil {
+			return nil, fmt.Errorf("error when scanning budget spending: %w", err)
+		}
+
+		amounts = append(amounts, amount)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return amounts, nil
+}
+
+// RecordBudgetAlert stores the alert unless one was already raised for the same budget,
+// period and threshold, and records a new one as an event of the budget owner, which is relayed
+// to the streams of the owner on every replica. It reports whether the alert is new and should be sent.
+func (d *DataStore) RecordBudgetAlert(ctx context.Context, alert models.BudgetAlert) (bool, error) {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal budget alert: %w", err)
+	}
+
+	query := `
+WITH inserted AS (
+	INSERT INTO budget_alerts (budget_id, period_start, threshold, spent, created_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT DO NOTHING
+	RETURNING budget_id
+)
+SELECT emit_user_event(ARRAY[$6::UUID], $7, $8::JSONB) FROM inserted`
+
+	result, err := d.pool.Exec(ctx, query, alert.BudgetID, alert.PeriodStart, alert.Threshold, alert.Spent, alert.CreatedAt,
+		alert.UserID, models.NotificationBudgetAlert, payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to record budget alert: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,409 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+// matchCategoryRule returns a scalar subquery selecting the category of the first rule of the
+// user that matches a transaction described by the given SQL expressions. Conditions left
+// empty on a rule always match; ties in priority are broken by the rule creation time.
+func matchCategoryRule(userID, txType, amount, toWalletID, fromWalletID, description string) string {
+	return fmt.Sprintf(`(
+SELECT r.category_id
+FROM category_rules r
+WHERE TRUE
+	AND r.user_id = %[1]s
+	AND (r.transaction_type IS NULL OR r.transaction_type = %[2]s)
+	AND (r.min_amount IS NULL OR %[3]s >= r.min_amount)
+	AND (r.max_amount IS NULL OR %[3]s <= r.max_amount)
+	AND (r.counterparty_wallet_id IS NULL OR r.counterparty_wallet_id IN (%[4]s, %[5]s))
+	AND (r.description_contains IS NULL OR strpos(lower(%[6]s), lower(r.description_contains)) > 0)
+ORDER BY r.priority, r.created_at
+LIMIT 1)`, userID, txType, amount, toWalletID, fromWalletID, description)
+}
+
+// transactionCategory returns a scalar subquery selecting the category the user assigned to the
+// transaction, as each user categorizes the transactions they see with their own categories.
+func transactionCategory(txID, userID string) string {
+	return fmt.Sprintf(`(SELECT tc.category_id FROM transaction_categories tc WHERE tc.transaction_id = %s AND tc.user_id = %s)`,
+		txID, userID)
+}
+
+//nolint:lll
+func writeCategoryFilter(sb *strings.Builder, args []any, request models.GetWalletsRequest, userID models.UserID) []any {
+	if len(request.Categories) == 0 && !request.Uncategorized {
+		return args
+	}
+
+	args = append(args, userID)
+	category := transactionCategory("transactions.id", fmt.Sprintf("$%d", len(args)))
+
+	conditions := make([]string, 0, 2) //nolint:mnd
+
+	if len(request.Categories) > 0 {
+		ids := make([]uuid.UUID, 0, len(request.Categories))
+		for _, id := range request.Categories {
+			ids = append(ids, uuid.UUID(id))
+		}
+
+		args = append(args, ids)
+		conditions = append(conditions, fmt.Sprintf(`%s = ANY($%d)`, category, len(args)))
+	}
+
+	if request.Uncategorized {
+		conditions = append(conditions, category+` IS NULL`)
+	}
+
+	sb.WriteString(` AND (` + strings.Join(conditions, " OR ") + `)`)
+
+	return args
+}
+
+func (d *DataStore) CreateCategory(ctx context.Context, category models.Category, userID models.UserID) (models.Category, error) {
+	query := `
+INSERT INTO categories (category_id, user_id, name)
+VALUES ($1, $2, $3)
+RETURNING category_id, user_id, name, created_at`
+
+	var created models.Category
+
+	if err := d.pool.QueryRow(ctx, query, category.CategoryID, userID, category.Name).Scan(
+		&created.CategoryID,
+		&created.UserID,
+		&created.Name,
+		&created.CreatedAt,
+	); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return models.Category{}, models.ErrCategoryExists
+		}
+
+		return models.Category{}, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return created, nil
+}
+
+func (d *DataStore) GetCategories(ctx context.Context, userID models.UserID) ([]models.Category, error) {
+	query := `
+SELECT category_id, user_id, name, created_at
+FROM categories
+WHERE user_id = $1
+ORDER BY name`
+
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting categories: %w", err)
+	}
+
+	defer rows.Close()
+
+	categories := make([]models.Category, 0)
+
+	for rows.Next() {
+		var category models.Category
+
+		if err = rows.Scan(
+			&category.CategoryID,
+			&category.UserID,
+			&category.Name,
+			&category.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error when scanning categories: %w", err)
+		}
+
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return categories, nil
+}
+
+// DeleteCategory removes the category together with its rules.
+// Transactions assigned to it become uncategorized.
+func (d *DataStore) DeleteCategory(ctx context.Context, categoryID models.CategoryID, userID models.UserID) error {
+	result, err := d.pool.Exec(ctx, `DELETE FROM categories WHERE category_id = $1 AND user_id = $2`, categoryID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting category: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (d *DataStore) CreateCategoryRule(ctx context.Context, rule models.CategoryRule, userID models.UserID) (models.CategoryRule, error) {
+	query := `
+INSERT INTO category_rules (rule_id, user_id, category_id, priority, transaction_type, min_amount, max_amount, counterparty_wallet_id, description_contains)
+SELECT $1::uuid, c.user_id, c.category_id, $4::integer, $5::varchar, $6::numeric, $7::numeric, $8::uuid, $9::varchar
+FROM categories c
+WHERE c.category_id = $2 AND c.user_id = $3
+RETURNING ` + categoryRuleColumns
+
+	created, err := scanCategoryRule(d.pool.QueryRow(ctx, query,
+		rule.RuleID,
+		rule.CategoryID,
+		userID,
+		rule.Priority,
+		rule.Type,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.CounterpartyWalletID,
+		rule.DescriptionContains,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.CategoryRule{}, models.ErrCategoryNotFound
+		}
+
+		return models.CategoryRule{}, fmt.Errorf("failed to create category rule: %w", err)
+	}
+
+	return created, nil
+}
+
+func (d *DataStore) GetCategoryRules(ctx context.Context, userID models.UserID) ([]models.CategoryRule, error) {
+	query := `
+SELECT ` + categoryRuleColumns + `
+FROM category_rules
+WHERE user_id = $1
+ORDER BY priority, created_at`
+
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting category rules: %w", err)
+	}
+
+	defer rows.Close()
+
+	rules := make([]models.CategoryRule, 0)
+
+	for rows.Next() {
+		rule, err := scanCategoryRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning category rules: %w", err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return rules, nil
+}
+
+func (d *DataStore) DeleteCategoryRule(ctx context.Context, ruleID models.RuleID, userID models.UserID) error {
+	result, err := d.pool.Exec(ctx, `DELETE FROM category_rules WHERE rule_id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting category rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrRuleNotFound
+	}
+
+	return nil
+}
+
+const categoryRuleColumns = `rule_id, category_id, priority, transaction_type, min_amount::float8, max_amount::float8,
+	counterparty_wallet_id, description_contains, created_at`
+
+func scanCategoryRule(row pgx.Row) (models.CategoryRule, error) {
+	var rule models.CategoryRule
+
+	if err := row.Scan(
+		&rule.RuleID,
+		&rule.CategoryID,
+		&rule.Priority,
+		&rule.Type,
+		&rule.MinAmount,
+		&rule.MaxAmount,
+		&rule.CounterpartyWalletID,
+		&rule.DescriptionContains,
+		&rule.CreatedAt,
+	); err != nil {
+		return models.CategoryRule{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return rule, nil
+}
+
+// SetTransactionCategory assigns the category to a transaction of the wallet by hand for the
+// user. Manual assignments are kept when the rules are re-applied unless forced; a nil
+// category clears the assignment.
+//
+//nolint:lll
+func (d *DataStore) SetTransactionCategory(ctx context.Context, txID models.TxID, walletID models.WalletID, categoryID *models.CategoryID, userID models.UserID) (models.Transaction, error) {
+	if categoryID != nil {
+		var exists bool
+
+		if err := d.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE category_id = $1 AND user_id = $2)`,
+			categoryID, userID).Scan(&exists); err != nil {
+			return models.Transaction{}, fmt.Errorf("failed to check category: %w", err)
+		}
+
+		if !exists {
+			return models.Transaction{}, models.ErrCategoryNotFound
+		}
+	}
+
+	query := `
+WITH target AS (
+	SELECT t.id
+	FROM transactions t
+	JOIN wallet_members m ON m.wallet_id = $3 AND m.user_id = $4 AND m.role IN ('owner', 'spender')
+	WHERE t.id = $2 AND (t.to_wallet_id = m.wallet_id OR t.from_wallet_id = m.wallet_id)
+), cleared AS (
+	DELETE FROM transaction_categories tc
+	USING target
+	WHERE $1::uuid IS NULL AND tc.transaction_id = target.id AND tc.user_id = $4
+), assigned AS (
+	INSERT INTO transaction_categories (transaction_id, user_id, category_id, manual)
+	SELECT target.id, $4, $1::uuid, TRUE
+	FROM target
+	WHERE $1::uuid IS NOT NULL
+	ON CONFLICT (transaction_id, user_id) DO UPDATE SET category_id = EXCLUDED.category_id, manual = TRUE
+)
+SELECT t.id, t.transaction_type, t.to_wallet_id, t.from_wallet_id, t.amount, t.currency, t.committed_at, t.description,
+	$1::uuid, t.external_reference, t.metadata, t.user_id
+FROM transactions t
+JOIN target ON target.id = t.id`
+
+	var transaction models.Transaction
+
+	if err := d.pool.QueryRow(ctx, query, categoryID, txID, walletID, userID).Scan(
+		&transaction.ID,
+		&transaction.Type,
+		&transaction.ToWalletID,
+		&transaction.FromWalletID,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.CommittedAt,
+		&transaction.Description,
+		&transaction.CategoryID,
+		&transaction.ExternalReference,
+		&transaction.Metadata,
+		&transaction.PerformedBy,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Transaction{}, models.ErrTransactionNotFound
+		}
+
+		return models.Transaction{}, fmt.Errorf("failed to set transaction category: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// ApplyCategoryRules re-evaluates the rules of the user against the transactions of all the
+// wallets they are a member of and returns how many were evaluated. Only the user's own
+// assignments change; manual ones are only touched when forced.
+func (d *DataStore) ApplyCategoryRules(ctx context.Context, userID models.UserID, force bool) (int64, error) {
+	query := `
+WITH evaluated AS (
+	SELECT t.id, ` + matchCategoryRule("$1", "t.transaction_type", "t.amount", "t.to_wallet_id", "t.from_wallet_id", "t.description") + ` AS category_id
+	FROM transactions t
+	WHERE TRUE
+		AND EXISTS (
+			SELECT 1 FROM wallet_members m
+			WHERE m.user_id = $1 AND (m.wallet_id = t.to_wallet_id OR m.wallet_id = t.from_wallet_id)
+		)
+		AND ($2 OR NOT EXISTS (
+			SELECT 1 FROM transaction_categories tc
+			WHERE tc.transaction_id = t.id AND tc.user_id = $1 AND tc.manual
+		))
+), cleared AS (
+	DELETE FROM transaction_categories tc
+	USING evaluated e
+	WHERE tc.transaction_id = e.id AND tc.user_id = $1 AND e.category_id IS NULL
+), assigned AS (
+	INSERT INTO transaction_categories (transaction_id, user_id, category_id)
+	SELECT e.id, $1, e.category_id
+	FROM evaluated e
+	WHERE e.category_id IS NOT NULL
+	ON CONFLICT (transaction_id, user_id) DO UPDATE SET category_id = EXCLUDED.category_id, manual = FALSE
+)
+SELECT COUNT(*) FROM evaluated`
+
+	var evaluated int64
+
+	if err := d.pool.QueryRow(ctx, query, userID, force).Scan(&evaluated); err != nil {
+		return 0, fmt.Errorf("failed to apply category rules: %w", err)
+	}
+
+	return evaluated, nil
+}
+
+// GetCategoryTotals sums the transactions of the wallet matching the request per category and currency.
+//
+//nolint:lll
+func (d *DataStore) GetCategoryTotals(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.CategoryTotal, error) {
+	if _, err := d.GetWallet(ctx, walletID, userID); err != nil {
+		return nil, fmt.Errorf("failed to extract wallet: %w", err)
+	}
+
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	args = append(args, userID)
+	sb.WriteString(`SELECT t.category_id, COALESCE(c.name, ''), t.currency, COUNT(*), SUM(t.amount)::float8
+FROM (SELECT ` + transactionCategory("transactions.id", "$1") + ` AS category_id, currency, amount FROM transactions WHERE`)
+
+	args = writeTransactionFilters(&sb, args, request, walletID, userID)
+
+	sb.WriteString(`) t
+LEFT JOIN categories c ON c.category_id = t.category_id
+GROUP BY 1, 2, 3
+ORDER BY 2, 3`)
+
+	rows, err := d.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting category totals: %w", err)
+	}
+
+	defer rows.Close()
+
+	totals := make([]models.CategoryTotal, 0)
+
+	for rows.Next() {
+		var total models.CategoryTotal
+
+		if err = rows.Scan(
+			&total.CategoryID,
+			&total.CategoryName,
+			&total.Currency,
+			&total.Count,
+			&total.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("error when scanning category totals: %w", err)
+		}
+
+		totals = append(totals, total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,317 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const (
+	complianceWatermarkName = "compliance"
+	caseColumns             = `case_id, user_id, kind, status, currency, amount::float8, created_at, updated_at`
+)
+
+// LockComplianceWatermark returns the commit time up to which transactions were scanned for
+// compliance cases, nil before the first scan. It locks the state row until the transaction in
+// the context ends, so that replicas do not scan concurrently.
+func (d *DataStore) LockComplianceWatermark(ctx context.Context) (*time.Time, error) {
+	var watermark *time.Time
+
+	if err := d.getTXFromCtx(ctx).QueryRow(ctx, `SELECT watermark FROM rollup_state WHERE name = $1 FOR UPDATE`,
+		complianceWatermarkName).Scan(&watermark); err != nil {
+		return nil, fmt.Errorf("failed to read compliance watermark: %w", err)
+	}
+
+	return watermark, nil
+}
+
+func (d *DataStore) SetComplianceWatermark(ctx context.Context, watermark time.Time) error {
+	if _, err := d.getTXFromCtx(ctx).Exec(ctx, `UPDATE rollup_state SET watermark = $2 WHERE name = $1`,
+		complianceWatermarkName, watermark); err != nil {
+		return fmt.Errorf("failed to move compliance watermark: %w", err)
+	}
+
+	return nil
+}
+
+// GetComplianceCandidates returns the transactions committed since the time that are not attached
+// to a case yet and whose amount reaches the minimum of their currency, oldest first.
+func (d *DataStore) GetComplianceCandidates(ctx context.Context, since time.Time, minAmounts map[string]float64) ([]models.Transaction, error) {
+	currencies := make([]string, 0, len(minAmounts))
+	amounts := make([]float64, 0, len(minAmounts))
+
+	for currency, amount := range minAmounts {
+		currencies = append(currencies, currency)
+		amounts = append(amounts, amount)
+	}
+
+	query := `
+SELECT t.id, t.transaction_type, t.to_wallet_id, t.from_wallet_id, t.amount::float8, t.currency, t.committed_at, t.user_id
+FROM transactions t
+JOIN UNNEST($2::varchar[], $3::float8[]) AS m(currency, min_amount) ON m.currency = UPPER(t.currency)
+WHERE TRUE
+	AND t.committed_at >= $1
+	AND t.user_id IS NOT NULL
+	AND t.amount >= m.min_amount
+	AND NOT EXISTS (SELECT 1 FROM compliance_case_transactions ct WHERE ct.transaction_id = t.id)
+ORDER BY t.committed_at, t.id`
+
+	rows, err := d.getTXFromCtx(ctx).Query(ctx, query, since, currencies, amounts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting compliance candidates: %w", err)
+	}
+
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+
+	for rows.Next() {
+		var t models.Transaction
+
+		if err = rows.Scan(&t.ID, &t.Type, &t.ToWalletID, &t.FromWalletID, &t.Amount, &t.Currency, &t.CommittedAt,
+			&t.PerformedBy); err != nil {
+			return nil, fmt.Errorf("error when scanning compliance candidates: %w", err)
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return transactions, nil
+}
+
+// CreateComplianceCase stores the case and attaches its transactions.
+func (d *DataStore) CreateComplianceCase(ctx context.Context, complianceCase models.ComplianceCase) (models.ComplianceCase, error) {
+	tx := d.getTXFromCtx(ctx)
+
+	query := `
+INSERT INTO compliance_cases (case_id, user_id, kind, status, currency, amount)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING ` + caseColumns
+
+	created, err := scanCase(tx.QueryRow(ctx, query,
+		complianceCase.CaseID,
+		complianceCase.UserID,
+		complianceCase.Kind,
+		complianceCase.Status,
+		complianceCase.Currency,
+		complianceCase.Amount,
+	))
+	if err != nil {
+		return models.ComplianceCase{}, fmt.Errorf("failed to create compliance case: %w", err)
+	}
+
+	txIDs := make([]uuid.UUID, 0, len(complianceCase.Transactions))
+
+	for _, t := range complianceCase.Transactions {
+		txIDs = append(txIDs, uuid.UUID(t.ID))
+	}
+
+	if _, err = tx.Exec(ctx, `
+INSERT INTO compliance_case_transactions (case_id, transaction_id)
+SELECT $1, UNNEST($2::uuid[])`, created.CaseID, txIDs); err != nil {
+		return models.ComplianceCase{}, fmt.Errorf("failed to attach transactions to compliance case: %w", err)
+	}
+
+	created.Transactions = complianceCase.Transactions
+	created.Notes = make([]models.CaseNote, 0)
+
+	return created, nil
+}
+
+// GetComplianceCases returns the cases with the status, or all cases when it is empty, oldest
+// first with their transactions and notes.
+func (d *DataStore) GetComplianceCases(ctx context.Context, status string) ([]models.ComplianceCase, error) {
+	query := `SELECT ` + caseColumns + ` FROM compliance_cases WHERE $1 = '' OR status = $1 ORDER BY created_at, case_id`
+
+	rows, err := d.pool.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting compliance cases: %w", err)
+	}
+
+	defer rows.Close()
+
+	cases := make([]models.ComplianceCase, 0)
+
+	for rows.Next() {
+		complianceCase, err := scanCase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning compliance cases: %w", err)
+		}
+
+		cases = append(cases, complianceCase)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	if err = d.loadCaseDetails(ctx, cases); err != nil {
+		return nil, err
+	}
+
+	return cases, nil
+}
+
+func (d *DataStore) GetComplianceCase(ctx context.Context, caseID models.CaseID) (models.ComplianceCase, error) {
+	complianceCase, err := scanCase(d.pool.QueryRow(ctx, `SELECT `+caseColumns+` FROM compliance_cases WHERE case_id = $1`, caseID))
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.ComplianceCase{}, models.ErrCaseNotFound
+	case err != nil:
+		return models.ComplianceCase{}, fmt.Errorf("failed to get compliance case: %w", err)
+	}
+
+	cases := []models.ComplianceCase{complianceCase}
+
+	if err = d.loadCaseDetails(ctx, cases); err != nil {
+		return models.ComplianceCase{}, err
+	}
+
+	return cases[0], nil
+}
+
+// UpdateComplianceCaseStatus moves the case from the status to another one. It fails with
+// ErrInvalidCaseTransition when the case is no longer in the from status.
+func (d *DataStore) UpdateComplianceCaseStatus(ctx context.Context, caseID models.CaseID, from, to string) error {
+	result, err := d.pool.Exec(ctx, `
+UPDATE compliance_cases
+SET status = $3, updated_at = NOW()
+WHERE case_id = $1 AND status = $2`, caseID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to update compliance case: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrInvalidCaseTransition
+	}
+
+	return nil
+}
+
+func (d *DataStore) AddComplianceCaseNote(ctx context.Context, note models.CaseNote) (models.CaseNote, error) {
+	query := `
+WITH touched AS (
+	UPDATE compliance_cases SET updated_at = NOW() WHERE case_id = $1 RETURNING case_id
+)
+INSERT INTO compliance_case_notes (case_id, author_id, text)
+SELECT case_id, $2, $3 FROM touched
+RETURNING case_id, author_id, text, created_at`
+
+	var created models.CaseNote
+
+	err := d.pool.QueryRow(ctx, query, note.CaseID, note.AuthorID, note.Text).
+		Scan(&created.CaseID, &created.AuthorID, &created.Text, &created.CreatedAt)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.CaseNote{}, models.ErrCaseNotFound
+	case err != nil:
+		return models.CaseNote{}, fmt.Errorf("failed to add compliance case note: %w", err)
+	}
+
+	return created, nil
+}
+
+// loadCaseDetails fills in the transactions and notes of the cases.
+func (d *DataStore) loadCaseDetails(ctx context.Context, cases []models.ComplianceCase) error {
+	if len(cases) == 0 {
+		return nil
+	}
+
+	index := make(map[models.CaseID]int, len(cases))
+	caseIDs := make([]uuid.UUID, 0, len(cases))
+
+	for i := range cases {
+		index[cases[i].CaseID] = i
+		caseIDs = append(caseIDs, uuid.UUID(cases[i].CaseID))
+		cases[i].Transactions = make([]models.Transaction, 0)
+		cases[i].Notes = make([]models.CaseNote, 0)
+	}
+
+	rows, err := d.pool.Query(ctx, `
+SELECT ct.case_id, t.id, t.transaction_type, t.to_wallet_id, t.from_wallet_id, t.amount::float8, t.currency, t.committed_at,
+	t.user_id
+FROM compliance_case_transactions ct
+JOIN transactions t ON t.id = ct.transaction_id
+WHERE ct.case_id = ANY($1)
+ORDER BY t.committed_at, t.id`, caseIDs)
+	if err != nil {
+		return fmt.Errorf("error getting compliance case transactions: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			caseID models.CaseID
+			t      models.Transaction
+		)
+
+		if err = rows.Scan(&caseID, &t.ID, &t.Type, &t.ToWalletID, &t.FromWalletID, &t.Amount, &t.Currency, &t.CommittedAt,
+			&t.PerformedBy); err != nil {
+			return fmt.Errorf("error when scanning compliance case transactions: %w", err)
+		}
+
+		cases[index[caseID]].Transactions = append(cases[index[caseID]].Transactions, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	noteRows, err := d.pool.Query(ctx, `
+SELECT case_id, author_id, text, created_at
+FROM compliance_case_notes
+WHERE case_id = ANY($1)
+ORDER BY note_id`, caseIDs)
+	if err != nil {
+		return fmt.Errorf("error getting compliance case notes: %w", err)
+	}
+
+	defer noteRows.Close()
+
+	for noteRows.Next() {
+		var note models.CaseNote
+
+		if err = noteRows.Scan(&note.CaseID, &note.AuthorID, &note.Text, &note.CreatedAt); err != nil {
+			return fmt.Errorf("error when scanning compliance case notes: %w", err)
+		}
+
+		cases[index[note.CaseID]].Notes = append(cases[index[note.CaseID]].Notes, note)
+	}
+
+	if err = noteRows.Err(); err != nil {
+		return fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return nil
+}
+
+func scanCase(row pgx.Row) (models.ComplianceCase, error) {
+	var complianceCase models.ComplianceCase
+
+	if err := row.Scan(
+		&complianceCase.CaseID,
+		&complianceCase.UserID,
+		&complianceCase.Kind,
+		&complianceCase.Status,
+		&complianceCase.Currency,
+		&complianceCase.Amount,
+		&complianceCase.CreatedAt,
+		&complianceCase.UpdatedAt,
+	); err != nil {
+		return models.ComplianceCase{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return complianceCase, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/pii"
 	"github.com/rs/zerolog/log"
 	migrate "github.com/rubenv/sql-migrate"
 )
@@ -22,13 +23,22 @@ var migrations embed.FS
 type DataStore struct {
 	pool *pgxpool.Pool
 	dsn  string
+	pii  *pii.Cipher
 }
 
 type Config struct {
 	Dsn string
+	// EncryptionKey encrypts the profile fields of users, the signing keys of API keys and the secrets
+	// of webhooks, see pii.New.
+	EncryptionKey string
 }
 
 func New(ctx context.Context, cfg Config) (*DataStore, error) {
+	cipher, err := pii.New(cfg.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile cipher: %w", err)
+	}
+
 	pool, err := pgxpool.New(ctx, cfg.Dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -43,6 +53,7 @@ func New(ctx context.Context, cfg Config) (*DataStore, error) {
 	return &DataStore{
 		pool: pool,
 		dsn:  cfg.Dsn,
+		pii:  cipher,
 	}, nil
 }
 
@@ -95,6 +106,16 @@ func (d *DataStore) Migrate(direction migrate.MigrationDirection) error {
 		return fmt.Errorf("failed to count the number of migrations: %w", err)
 	}
 
+	if direction == migrate.Up {
+		if err := d.encryptPlaintextSigningKeys(context.Background()); err != nil {
+			return err
+		}
+
+		if err := d.encryptPlaintextWebhookSecrets(context.Background()); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const walletEventsChannel = "wallet_events"
+
+// ListenWalletEvents calls handle with every wallet event committed by any replica, together
+// with the members of its wallets and the users it is addressed to. It blocks until the context is done or the connection fails.
+func (d *DataStore) ListenWalletEvents(ctx context.Context, handle func(event models.WalletEvent)) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	defer func() {
+		//nolint:contextcheck
+		if _, err := conn.Exec(context.Background(), `UNLISTEN *`); err != nil {
+			conn.Conn().Close(context.Background()) //nolint:errcheck
+		}
+
+		conn.Release()
+	}()
+
+	if _, err = conn.Exec(ctx, `LISTEN `+walletEventsChannel); err != nil {
+		return fmt.Errorf("failed to listen for wallet events: %w", err)
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("failed to wait for wallet events: %w", err)
+		}
+
+		eventID, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			log.Warn().Str("payload", notification.Payload).Msg("unexpected wallet event notification")
+
+			continue
+		}
+
+		event, err := d.getWalletEvent(ctx, eventID)
+		if err != nil {
+			log.Warn().Err(err).Int64("eventId", eventID).Msg("failed to load wallet event")
+
+			continue
+		}
+
+		handle(event)
+	}
+}
+
+func (d *DataStore) getWalletEvent(ctx context.Context, eventID int64) (models.WalletEvent, error) {
+	event, err := scanWalletEvent(d.pool.QueryRow(ctx, `
+SELECT sequence_id, event_type, payload, created_at FROM wallet_events WHERE sequence_id = $1`, eventID))
+	if err != nil {
+		return models.WalletEvent{}, fmt.Errorf("failed to get wallet event: %w", err)
+	}
+
+	rows, err := d.pool.Query(ctx, `
+SELECT m.user_id
+FROM wallet_members m
+JOIN wallet_events e ON m.wallet_id = ANY(e.wallet_ids)
+WHERE e.sequence_id = $1
+UNION
+SELECT UNNEST(user_ids) FROM wallet_events WHERE sequence_id = $1`, eventID)
+	if err != nil {
+		return models.WalletEvent{}, fmt.Errorf("failed to get wallet event recipients: %w", err)
+	}
+
+	event.Recipients, err = pgx.CollectRows(rows, pgx.RowTo[models.UserID])
+	if err != nil {
+		return models.WalletEvent{}, fmt.Errorf("failed to read wallet event recipients: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetWalletEvents returns up to limit events of the wallets of the user, and of the user, committed
+// after the event ID. Events are identified by their sequence_id, which follows commit order.
+//
+//nolint:lll
+func (d *DataStore) GetWalletEvents(ctx context.Context, userID models.UserID, afterEventID int64, limit int) ([]models.WalletEvent, error) {
+	query := `
+SELECT e.sequence_id, e.event_type, e.payload, e.created_at
+FROM wallet_events e
+WHERE e.sequence_id > $2
+	AND ($1 = ANY(e.user_ids) OR EXISTS (SELECT 1 FROM wallet_members m WHERE m.user_id = $1 AND m.wallet_id = ANY(e.wallet_ids)))
+ORDER BY e.sequence_id
+LIMIT $3`
+
+	rows, err := d.pool.Query(ctx, query, userID, afterEventID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting wallet events: %w", err)
+	}
+
+	defer rows.Close()
+
+	events := make([]models.WalletEvent, 0)
+
+	for rows.Next() {
+		event, err := scanWalletEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning wallet events: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return events, nil
+}
+
+func (d *DataStore) PurgeWalletEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	result, err := d.pool.Exec(ctx, `DELETE FROM wallet_events WHERE created_at < $1`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired wallet events: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func scanWalletEvent(row pgx.Row) (models.WalletEvent, error) {
+	var event models.WalletEvent
+
+	if err := row.Scan(
+		&event.EventID,
+		&event.Type,
+		&event.Payload,
+		&event.CreatedAt,
+	); err != nil {
+		return models.WalletEvent{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return event, nil
+}
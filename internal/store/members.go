@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+// memberHasRole returns a condition on the wallets table requiring the user given by the
+// SQL expression to be a member of the wallet with one of the roles.
+func memberHasRole(userID string, roles ...string) string {
+	quoted := make([]string, 0, len(roles))
+	for _, role := range roles {
+		quoted = append(quoted, "'"+role+"'")
+	}
+
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM wallet_members m
+		WHERE m.wallet_id = wallets.wallet_id AND m.user_id = %s AND m.role IN (%s))`,
+		userID, strings.Join(quoted, ", "))
+}
+
+const memberColumns = `wallet_id, user_id, role, spend_limit::float8, created_at, updated_at`
+
+func (d *DataStore) GetWalletMembers(ctx context.Context, walletID models.WalletID) ([]models.WalletMember, error) {
+	query := `SELECT ` + memberColumns + ` FROM wallet_members WHERE wallet_id = $1 ORDER BY created_at, user_id`
+
+	rows, err := d.pool.Query(ctx, query, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting wallet members: %w", err)
+	}
+
+	defer rows.Close()
+
+	members := make([]models.WalletMember, 0)
+
+	for rows.Next() {
+		member, err := scanWalletMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning wallet members: %w", err)
+		}
+
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return members, nil
+}
+
+func (d *DataStore) AddWalletMember(ctx context.Context, member models.WalletMember) (models.WalletMember, error) {
+	query := `
+INSERT INTO wallet_members (wallet_id, user_id, role, spend_limit)
+VALUES ($1, $2, $3, $4)
+RETURNING ` + memberColumns
+
+	created, err := scanWalletMember(d.pool.QueryRow(ctx, query, member.WalletID, member.UserID, member.Role, member.SpendLimit))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.UniqueViolation:
+				return models.WalletMember{}, models.ErrMemberExists
+			case pgerrcode.ForeignKeyViolation:
+				return models.WalletMember{}, models.ErrUserNotFound
+			}
+		}
+
+		return models.WalletMember{}, fmt.Errorf("failed to add wallet member: %w", err)
+	}
+
+	return created, nil
+}
+
+// UpdateWalletMember changes the role and spend limit of a member. Demoting the last owner
+// is refused; owner rows are locked so that concurrent demotions cannot both succeed.
+func (d *DataStore) UpdateWalletMember(ctx context.Context, member models.WalletMember) (models.WalletMember, error) {
+	var updated models.WalletMember
+
+	if err := d.DoWithTx(ctx, func(ctx context.Context) error {
+		tx := d.getTXFromCtx(ctx)
+
+		if member.Role != models.MemberRoleOwner {
+			if err := d.checkNotLastOwner(ctx, tx, member.WalletID, member.UserID); err != nil {
+				return err
+			}
+		}
+
+		query := `
+UPDATE wallet_members
+SET role = $3, spend_limit = $4, updated_at = NOW()
+WHERE wallet_id = $1 AND user_id = $2
+RETURNING ` + memberColumns
+
+		var err error
+
+		updated, err = scanWalletMember(tx.QueryRow(ctx, query, member.WalletID, member.UserID, member.Role, member.SpendLimit))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrMemberNotFound
+			}
+
+			return fmt.Errorf("failed to update wallet member: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return models.WalletMember{}, fmt.Errorf("error updating wallet member: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (d *DataStore) RemoveWalletMember(ctx context.Context, walletID models.WalletID, userID models.UserID) error {
+	if err := d.DoWithTx(ctx, func(ctx context.Context) error {
+		tx := d.getTXFromCtx(ctx)
+
+		if err := d.checkNotLastOwner(ctx, tx, walletID, userID); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(ctx, `DELETE FROM wallet_members WHERE wallet_id = $1 AND user_id = $2`, walletID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove wallet member: %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return models.ErrMemberNotFound
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error removing wallet member: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (d *DataStore) checkNotLastOwner(ctx context.Context, tx transaction, walletID models.WalletID, userID models.UserID) error {
+	rows, err := tx.Query(ctx, `SELECT user_id FROM wallet_members WHERE wallet_id = $1 AND role = 'owner' FOR UPDATE`, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to lock wallet owners: %w", err)
+	}
+
+	owners, err := pgx.CollectRows(rows, pgx.RowTo[models.UserID])
+	if err != nil {
+		return fmt.Errorf("failed to read wallet owners: %w", err)
+	}
+
+	if len(owners) == 1 && slices.Contains(owners, userID) {
+		return models.ErrLastOwner
+	}
+
+	return nil
+}
+
+func scanWalletMember(row pgx.Row) (models.WalletMember, error) {
+	var member models.WalletMember
+
+	if err := row.Scan(
+		&member.WalletID,
+		&member.UserID,
+		&member.Role,
+		&member.SpendLimit,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	); err != nil {
+		return models.WalletMember{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return member, nil
+}
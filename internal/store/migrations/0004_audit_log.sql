@@ -0,0 +1,45 @@
+-- +migrate Up
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    request_id VARCHAR NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR NOT NULL,
+    wallet_ids UUID[] NOT NULL DEFAULT '{}',
+    status INTEGER NOT NULL,
+    latency_ms BIGINT NOT NULL,
+    request_body JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_user_id ON audit_log(user_id, created_at);
+CREATE INDEX idx_audit_log_wallet_ids ON audit_log USING GIN (wallet_ids);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('audit.retention_purge', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE OR REPLACE TRIGGER audit_log_before_update
+BEFORE UPDATE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE OR REPLACE TRIGGER audit_log_before_delete
+BEFORE DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_log CASCADE;
+DROP FUNCTION IF EXISTS audit_log_append_only;
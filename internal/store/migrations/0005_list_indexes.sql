@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_wallets_user_currency ON wallets(user_id, currency, wallet_id) WHERE deleted_at IS NULL AND active = true;
+CREATE INDEX idx_wallets_user_name ON wallets(user_id, wallet_name, wallet_id) WHERE deleted_at IS NULL AND active = true;
+CREATE INDEX idx_wallets_user_created_at ON wallets(user_id, created_at, wallet_id) WHERE deleted_at IS NULL AND active = true;
+CREATE INDEX idx_wallets_user_balance ON wallets(user_id, balance, wallet_id) WHERE deleted_at IS NULL AND active = true;
+CREATE INDEX idx_wallets_name_trgm ON wallets USING GIN (wallet_name gin_trgm_ops);
+
+CREATE INDEX idx_transactions_to_committed_at ON transactions(to_wallet_id, committed_at, id);
+CREATE INDEX idx_transactions_from_committed_at ON transactions(from_wallet_id, committed_at, id);
+CREATE INDEX idx_transactions_to_amount ON transactions(to_wallet_id, amount, id);
+CREATE INDEX idx_transactions_from_amount ON transactions(from_wallet_id, amount, id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_transactions_from_amount;
+DROP INDEX IF EXISTS idx_transactions_to_amount;
+DROP INDEX IF EXISTS idx_transactions_from_committed_at;
+DROP INDEX IF EXISTS idx_transactions_to_committed_at;
+DROP INDEX IF EXISTS idx_wallets_name_trgm;
+DROP INDEX IF EXISTS idx_wallets_user_balance;
+DROP INDEX IF EXISTS idx_wallets_user_created_at;
+DROP INDEX IF EXISTS idx_wallets_user_name;
+DROP INDEX IF EXISTS idx_wallets_user_currency;
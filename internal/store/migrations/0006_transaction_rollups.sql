@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE transaction_rollups (
+    wallet_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    bucket DATE NOT NULL,
+    transaction_type VARCHAR NOT NULL,
+    currency VARCHAR NOT NULL,
+    inflow NUMERIC NOT NULL DEFAULT 0,
+    outflow NUMERIC NOT NULL DEFAULT 0,
+    tx_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (wallet_id, bucket, transaction_type, currency)
+);
+
+CREATE INDEX idx_transaction_rollups_user_bucket ON transaction_rollups(user_id, bucket);
+
+CREATE TABLE rollup_state (
+    name VARCHAR PRIMARY KEY,
+    watermark TIMESTAMP WITH TIME ZONE
+);
+
+INSERT INTO rollup_state (name, watermark) VALUES ('transactions', NULL);
+
+-- +migrate Down
+DROP TABLE IF EXISTS rollup_state;
+DROP TABLE IF EXISTS transaction_rollups;
@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE categories (
+    category_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    name VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE category_rules (
+    rule_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    category_id UUID NOT NULL REFERENCES categories (category_id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    transaction_type VARCHAR,
+    min_amount NUMERIC,
+    max_amount NUMERIC,
+    counterparty_wallet_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_category_rules_user_priority ON category_rules(user_id, priority, created_at);
+
+ALTER TABLE transactions
+    ADD COLUMN category_id UUID REFERENCES categories (category_id) ON DELETE SET NULL,
+    ADD COLUMN category_manual BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_transactions_category_id ON transactions(category_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_transactions_category_id;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS category_manual,
+    DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS category_rules;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,26 @@
+-- +migrate Up
+ALTER TABLE transactions
+    ADD COLUMN description VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN user_id UUID,
+    ADD COLUMN external_reference VARCHAR,
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE UNIQUE INDEX idx_transactions_user_external_reference ON transactions(user_id, external_reference)
+    WHERE external_reference IS NOT NULL;
+CREATE INDEX idx_transactions_metadata ON transactions USING GIN (metadata jsonb_path_ops);
+CREATE INDEX idx_transactions_description_trgm ON transactions USING GIN (description gin_trgm_ops);
+
+ALTER TABLE category_rules
+    ADD COLUMN description_contains VARCHAR;
+
+-- +migrate Down
+ALTER TABLE category_rules
+    DROP COLUMN IF EXISTS description_contains;
+DROP INDEX IF EXISTS idx_transactions_description_trgm;
+DROP INDEX IF EXISTS idx_transactions_metadata;
+DROP INDEX IF EXISTS idx_transactions_user_external_reference;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS external_reference,
+    DROP COLUMN IF EXISTS user_id,
+    DROP COLUMN IF EXISTS description;
@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE budgets (
+    budget_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    name VARCHAR NOT NULL,
+    wallet_id UUID REFERENCES wallets (wallet_id),
+    category_id UUID REFERENCES categories (category_id) ON DELETE CASCADE,
+    period VARCHAR NOT NULL,
+    amount NUMERIC NOT NULL,
+    currency VARCHAR NOT NULL,
+    thresholds INTEGER[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (wallet_id IS NOT NULL OR category_id IS NOT NULL)
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets(user_id);
+
+CREATE TABLE budget_alerts (
+    budget_id UUID NOT NULL REFERENCES budgets (budget_id) ON DELETE CASCADE,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    threshold INTEGER NOT NULL,
+    spent NUMERIC NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (budget_id, period_start, threshold)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS budget_alerts;
+DROP TABLE IF EXISTS budgets;
@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE wallet_members (
+    wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    role VARCHAR NOT NULL CHECK (role IN ('owner', 'spender', 'viewer')),
+    spend_limit NUMERIC,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wallet_id, user_id)
+);
+
+CREATE INDEX idx_wallet_members_user_id ON wallet_members(user_id);
+
+INSERT INTO wallet_members (wallet_id, user_id, role)
+SELECT wallet_id, user_id, 'owner' FROM wallets;
+
+-- +migrate Down
+DROP TABLE IF EXISTS wallet_members;
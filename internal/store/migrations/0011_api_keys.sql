@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE api_keys (
+    key_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    name VARCHAR NOT NULL,
+    scopes TEXT[] NOT NULL,
+    secret_hash BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rotated_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+
+CREATE TABLE api_key_signatures (
+    key_id UUID NOT NULL REFERENCES api_keys (key_id) ON DELETE CASCADE,
+    signature VARCHAR NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (key_id, signature)
+);
+
+CREATE INDEX idx_api_key_signatures_expires_at ON api_key_signatures(expires_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS api_key_signatures;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,86 @@
+-- +migrate Up
+CREATE TABLE wallet_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    wallet_ids UUID[] NOT NULL,
+    event_type VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wallet_events_wallet_ids ON wallet_events USING GIN (wallet_ids);
+CREATE INDEX idx_wallet_events_created_at ON wallet_events(created_at);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_wallet_event(wallet_ids UUID[], event_type VARCHAR, payload JSONB)
+RETURNS VOID AS $$
+DECLARE
+    new_event_id BIGINT;
+BEGIN
+    INSERT INTO wallet_events (wallet_ids, event_type, payload)
+    VALUES (wallet_ids, event_type, payload)
+    RETURNING event_id INTO new_event_id;
+
+    PERFORM pg_notify('wallet_events', new_event_id::text);
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_balance_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.balance IS DISTINCT FROM OLD.balance OR NEW.currency IS DISTINCT FROM OLD.currency THEN
+        PERFORM emit_wallet_event(ARRAY[NEW.wallet_id], 'balance.updated', jsonb_build_object(
+            'walletId', NEW.wallet_id,
+            'balance', NEW.balance,
+            'currency', NEW.currency,
+            'updatedAt', NEW.updated_at
+        ));
+    END IF;
+    RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_transaction_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM emit_wallet_event(
+        array_remove(ARRAY[NEW.to_wallet_id, NEW.from_wallet_id], NULL),
+        'transaction.created',
+        jsonb_strip_nulls(jsonb_build_object(
+            'transactionId', NEW.id,
+            'type', NEW.transaction_type,
+            'toWalletId', NEW.to_wallet_id,
+            'fromWalletId', NEW.from_wallet_id,
+            'amount', NEW.amount,
+            'currency', NEW.currency,
+            'committedAt', NEW.committed_at,
+            'description', NEW.description,
+            'performedBy', NEW.user_id
+        ))
+    );
+    RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER wallet_balance_event
+AFTER UPDATE ON wallets
+FOR EACH ROW EXECUTE FUNCTION emit_balance_event();
+
+CREATE TRIGGER transaction_created_event
+AFTER INSERT ON transactions
+FOR EACH ROW EXECUTE FUNCTION emit_transaction_event();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS transaction_created_event ON transactions;
+DROP TRIGGER IF EXISTS wallet_balance_event ON wallets;
+DROP FUNCTION IF EXISTS emit_transaction_event();
+DROP FUNCTION IF EXISTS emit_balance_event();
+DROP FUNCTION IF EXISTS emit_wallet_event(UUID[], VARCHAR, JSONB);
+DROP TABLE IF EXISTS wallet_events;
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    allowed BOOLEAN NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
+
+CREATE TABLE rate_limit_overrides (
+    user_id UUID NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    budget VARCHAR NOT NULL,
+    requests INTEGER NOT NULL CHECK (requests >= 0),
+    PRIMARY KEY (user_id, budget)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS rate_limit_overrides;
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE risk_rules (
+    name VARCHAR PRIMARY KEY,
+    kind VARCHAR NOT NULL,
+    action VARCHAR NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    params JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO risk_rules (name, kind, action, params) VALUES
+    ('velocity', 'velocity', 'review', '{"windowMinutes": 10, "maxCount": 10}'),
+    ('amount-spike', 'amount_spike', 'review', '{"windowMinutes": 43200, "multiplier": 10, "minHistory": 5}'),
+    ('new-counterparty', 'new_counterparty', 'review', '{"windowMinutes": 129600, "minAmount": 10000}'),
+    ('currency-cycling', 'currency_cycling', 'block', '{"windowMinutes": 60, "maxCount": 3}');
+
+CREATE TABLE risk_assessments (
+    assessment_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    outcome VARCHAR NOT NULL,
+    rules TEXT[] NOT NULL,
+    transaction JSONB NOT NULL,
+    status VARCHAR,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    decided_by UUID
+);
+
+CREATE INDEX idx_risk_assessments_status ON risk_assessments(status, created_at) WHERE status IS NOT NULL;
+CREATE INDEX idx_risk_assessments_user_id ON risk_assessments(user_id);
+CREATE INDEX idx_transactions_user_committed_at ON transactions(user_id, committed_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_transactions_user_committed_at;
+DROP TABLE IF EXISTS risk_assessments;
+DROP TABLE IF EXISTS risk_rules;
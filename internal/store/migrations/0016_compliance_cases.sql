@@ -0,0 +1,39 @@
+-- +migrate Up
+CREATE TABLE compliance_cases (
+    case_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    kind VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'open',
+    currency VARCHAR NOT NULL,
+    amount NUMERIC NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_compliance_cases_status ON compliance_cases(status, created_at);
+
+CREATE TABLE compliance_case_transactions (
+    case_id UUID NOT NULL REFERENCES compliance_cases (case_id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions (id),
+    PRIMARY KEY (case_id, transaction_id)
+);
+
+CREATE UNIQUE INDEX idx_compliance_case_transactions_transaction_id ON compliance_case_transactions(transaction_id);
+
+CREATE TABLE compliance_case_notes (
+    note_id BIGSERIAL PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES compliance_cases (case_id) ON DELETE CASCADE,
+    author_id UUID NOT NULL,
+    text VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_compliance_case_notes_case_id ON compliance_case_notes(case_id, note_id);
+
+INSERT INTO rollup_state (name, watermark) VALUES ('compliance', NULL);
+
+-- +migrate Down
+DELETE FROM rollup_state WHERE name = 'compliance';
+DROP TABLE IF EXISTS compliance_case_notes;
+DROP TABLE IF EXISTS compliance_case_transactions;
+DROP TABLE IF EXISTS compliance_cases;
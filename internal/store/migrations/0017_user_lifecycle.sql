@@ -0,0 +1,37 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN lifecycle_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE wallets ADD COLUMN frozen_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_wallets_frozen_at ON wallets(user_id) WHERE frozen_at IS NOT NULL;
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_freeze_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.frozen_at IS DISTINCT FROM OLD.frozen_at THEN
+        PERFORM emit_wallet_event(
+            ARRAY[NEW.wallet_id],
+            CASE WHEN NEW.frozen_at IS NULL THEN 'wallet.unfrozen' ELSE 'wallet.frozen' END,
+            jsonb_strip_nulls(jsonb_build_object(
+                'walletId', NEW.wallet_id,
+                'userId', NEW.user_id,
+                'frozenAt', NEW.frozen_at
+            ))
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER wallet_freeze_event
+AFTER UPDATE OF frozen_at ON wallets
+FOR EACH ROW EXECUTE FUNCTION emit_freeze_event();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS wallet_freeze_event ON wallets;
+DROP FUNCTION IF EXISTS emit_freeze_event();
+DROP INDEX IF EXISTS idx_wallets_frozen_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS frozen_at;
+ALTER TABLE users DROP COLUMN IF EXISTS lifecycle_at;
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- Profile fields are encrypted by the service. Names are searched by their blind index, a keyed
+-- hash of the normalized value.
+ALTER TABLE users
+    ADD COLUMN first_name BYTEA,
+    ADD COLUMN last_name BYTEA,
+    ADD COLUMN gender BYTEA,
+    ADD COLUMN age BYTEA,
+    ADD COLUMN first_name_index BYTEA,
+    ADD COLUMN last_name_index BYTEA,
+    ADD COLUMN profile_updated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN preferences JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_users_first_name_index ON users(first_name_index);
+CREATE INDEX idx_users_last_name_index ON users(last_name_index);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_last_name_index;
+DROP INDEX IF EXISTS idx_users_first_name_index;
+
+ALTER TABLE users
+    DROP COLUMN preferences,
+    DROP COLUMN profile_updated_at,
+    DROP COLUMN last_name_index,
+    DROP COLUMN first_name_index,
+    DROP COLUMN age,
+    DROP COLUMN gender,
+    DROP COLUMN last_name,
+    DROP COLUMN first_name;
@@ -0,0 +1,40 @@
+-- +migrate Up
+-- Categories and rules belong to users, and a transaction of a shared wallet or between wallets of
+-- different users is seen by several of them, so each user categorizes it for themselves.
+CREATE TABLE transaction_categories (
+    transaction_id UUID NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (user_id),
+    category_id UUID NOT NULL REFERENCES categories (category_id) ON DELETE CASCADE,
+    manual BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (transaction_id, user_id)
+);
+
+CREATE INDEX idx_transaction_categories_category_id ON transaction_categories(category_id);
+
+INSERT INTO transaction_categories (transaction_id, user_id, category_id, manual)
+SELECT t.id, c.user_id, t.category_id, t.category_manual
+FROM transactions t
+JOIN categories c ON c.category_id = t.category_id;
+
+DROP INDEX IF EXISTS idx_transactions_category_id;
+ALTER TABLE transactions
+    DROP COLUMN category_manual,
+    DROP COLUMN category_id;
+
+-- +migrate Down
+ALTER TABLE transactions
+    ADD COLUMN category_id UUID REFERENCES categories (category_id) ON DELETE SET NULL,
+    ADD COLUMN category_manual BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_transactions_category_id ON transactions(category_id);
+
+UPDATE transactions t
+SET category_id = tc.category_id, category_manual = tc.manual
+FROM (
+    SELECT DISTINCT ON (transaction_id) transaction_id, category_id, manual
+    FROM transaction_categories
+    ORDER BY transaction_id, manual DESC
+) tc
+WHERE tc.transaction_id = t.id;
+
+DROP TABLE IF EXISTS transaction_categories;
@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Signing keys are encrypted by the service with the same key as profile fields, so read access to
+-- the database is not enough to sign requests. Migrate encrypts the keys stored before this and
+-- clears plaintext_signing_key.
+ALTER TABLE api_keys RENAME COLUMN secret_hash TO plaintext_signing_key;
+
+ALTER TABLE api_keys
+    ALTER COLUMN plaintext_signing_key DROP NOT NULL,
+    ADD COLUMN encrypted_signing_key BYTEA,
+    ADD CONSTRAINT api_keys_signing_key_check
+        CHECK (encrypted_signing_key IS NOT NULL OR plaintext_signing_key IS NOT NULL);
+
+-- +migrate Down
+-- Encrypted signing keys cannot be restored here, so those keys are revoked.
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, NOW()), plaintext_signing_key = ''::BYTEA
+WHERE plaintext_signing_key IS NULL;
+
+ALTER TABLE api_keys
+    DROP CONSTRAINT api_keys_signing_key_check,
+    DROP COLUMN encrypted_signing_key,
+    ALTER COLUMN plaintext_signing_key SET NOT NULL;
+
+ALTER TABLE api_keys RENAME COLUMN plaintext_signing_key TO secret_hash;
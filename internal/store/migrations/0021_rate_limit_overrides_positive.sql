@@ -0,0 +1,13 @@
+-- +migrate Up
+-- An override of zero requests used to lift the limit of the user. Those overrides are dropped, so
+-- the configured limits apply again, and an override must now allow at least one request.
+DELETE FROM rate_limit_overrides WHERE requests = 0;
+
+ALTER TABLE rate_limit_overrides
+    DROP CONSTRAINT rate_limit_overrides_requests_check,
+    ADD CONSTRAINT rate_limit_overrides_requests_check CHECK (requests > 0);
+
+-- +migrate Down
+ALTER TABLE rate_limit_overrides
+    DROP CONSTRAINT rate_limit_overrides_requests_check,
+    ADD CONSTRAINT rate_limit_overrides_requests_check CHECK (requests >= 0);
@@ -0,0 +1,74 @@
+-- +migrate Up
+-- event_id is assigned when an event is recorded, so a transaction committing after another one may
+-- have recorded its events with lower IDs, and a client resuming after the later ID would miss them.
+-- Events are therefore numbered by sequence_id when their transaction commits: a deferred trigger
+-- assigns it under a transaction advisory lock held until the commit, so the transactions
+-- recording events commit in sequence_id order. Events are notified and resumed by sequence_id.
+CREATE SEQUENCE wallet_event_sequence;
+
+ALTER TABLE wallet_events ADD COLUMN sequence_id BIGINT;
+
+UPDATE wallet_events SET sequence_id = event_id;
+
+SELECT setval('wallet_event_sequence', COALESCE((SELECT MAX(sequence_id) FROM wallet_events), 0) + 1, false);
+
+CREATE UNIQUE INDEX idx_wallet_events_sequence_id ON wallet_events(sequence_id);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_wallet_event(wallet_ids UUID[], event_type VARCHAR, payload JSONB)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO wallet_events (wallet_ids, event_type, payload)
+    VALUES (wallet_ids, event_type, payload);
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION sequence_wallet_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    new_sequence_id BIGINT;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('wallet_event_sequence'));
+
+    new_sequence_id := nextval('wallet_event_sequence');
+
+    UPDATE wallet_events SET sequence_id = new_sequence_id WHERE event_id = NEW.event_id;
+
+    PERFORM pg_notify('wallet_events', new_sequence_id::text);
+    RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE CONSTRAINT TRIGGER wallet_event_sequence
+AFTER INSERT ON wallet_events
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION sequence_wallet_event();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS wallet_event_sequence ON wallet_events;
+DROP FUNCTION IF EXISTS sequence_wallet_event();
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_wallet_event(wallet_ids UUID[], event_type VARCHAR, payload JSONB)
+RETURNS VOID AS $$
+DECLARE
+    new_event_id BIGINT;
+BEGIN
+    INSERT INTO wallet_events (wallet_ids, event_type, payload)
+    VALUES (wallet_ids, event_type, payload)
+    RETURNING event_id INTO new_event_id;
+
+    PERFORM pg_notify('wallet_events', new_event_id::text);
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+DROP INDEX IF EXISTS idx_wallet_events_sequence_id;
+ALTER TABLE wallet_events DROP COLUMN IF EXISTS sequence_id;
+DROP SEQUENCE IF EXISTS wallet_event_sequence;
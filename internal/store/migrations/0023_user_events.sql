@@ -0,0 +1,25 @@
+-- +migrate Up
+-- Events of a user rather than of wallets, like budget alerts, are recorded as wallet events
+-- addressed to their users, so they are relayed to every replica and resumed like the others.
+ALTER TABLE wallet_events ADD COLUMN user_ids UUID[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_wallet_events_user_ids ON wallet_events USING GIN (user_ids);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION emit_user_event(user_ids UUID[], event_type VARCHAR, payload JSONB)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO wallet_events (wallet_ids, user_ids, event_type, payload)
+    VALUES ('{}', user_ids, event_type, payload);
+END;
+$$
+LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP FUNCTION IF EXISTS emit_user_event(UUID[], VARCHAR, JSONB);
+
+DELETE FROM wallet_events WHERE wallet_ids = '{}';
+
+DROP INDEX IF EXISTS idx_wallet_events_user_ids;
+ALTER TABLE wallet_events DROP COLUMN IF EXISTS user_ids;
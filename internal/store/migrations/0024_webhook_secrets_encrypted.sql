@@ -0,0 +1,22 @@
+-- +migrate Up
+-- Webhook signing secrets are encrypted by the service like API key signing keys, so read access
+-- to the database is not enough to forge deliveries. Migrate encrypts the secrets stored before
+-- this and clears plaintext_secret.
+ALTER TABLE webhooks RENAME COLUMN secret TO plaintext_secret;
+
+ALTER TABLE webhooks
+    ALTER COLUMN plaintext_secret DROP NOT NULL,
+    ADD COLUMN encrypted_secret BYTEA,
+    ADD CONSTRAINT webhooks_secret_check
+        CHECK (encrypted_secret IS NOT NULL OR plaintext_secret IS NOT NULL);
+
+-- +migrate Down
+-- Encrypted secrets cannot be restored here, so those webhooks are deleted.
+DELETE FROM webhooks WHERE plaintext_secret IS NULL;
+
+ALTER TABLE webhooks
+    DROP CONSTRAINT webhooks_secret_check,
+    DROP COLUMN encrypted_secret,
+    ALTER COLUMN plaintext_secret SET NOT NULL;
+
+ALTER TABLE webhooks RENAME COLUMN plaintext_secret TO secret;
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+type sortColumn struct {
+	column string
+	pgType string
+}
+
+// writeKeyset appends the keyset condition, ordering and limit for the requested page.
+// One extra row is requested so that the caller can tell whether another page exists.
+//
+//nolint:lll
+func writeKeyset(sb *strings.Builder, args []any, request models.GetWalletsRequest, sort sortColumn, idColumn string) []any {
+	backward := request.Cursor != nil && request.Cursor.Backward
+	descending := request.Descending != backward
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if request.Cursor != nil {
+		args = append(args, request.Cursor.Value, request.Cursor.ID)
+		sb.WriteString(fmt.Sprintf(` AND (%s, %s) %s ($%d::text::%s, $%d::uuid)`,
+			sort.column, idColumn, comparison, len(args)-1, sort.pgType, len(args)))
+	}
+
+	sb.WriteString(fmt.Sprintf(" ORDER BY %s %s, %s %s", sort.column, direction, idColumn, direction))
+
+	args = append(args, request.Limit+1)
+	sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+
+	if request.Cursor == nil && request.Offset > 0 {
+		args = append(args, request.Offset)
+		sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	}
+
+	return args
+}
+
+type keysetRow[T any] struct {
+	item    T
+	id      uuid.UUID
+	sortKey string
+}
+
+// newPage trims the extra row fetched by writeKeyset, restores the requested order
+// for backward pages and builds cursors pointing at both ends of the page.
+func newPage[T any](rows []keysetRow[T], request models.GetWalletsRequest, sorting string) models.Page[T] {
+	backward := request.Cursor != nil && request.Cursor.Backward
+	hasMore := len(rows) > request.Limit
+
+	if hasMore {
+		rows = rows[:request.Limit]
+	}
+
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	page := models.Page[T]{Items: make([]T, 0, len(rows))}
+
+	for _, row := range rows {
+		page.Items = append(page.Items, row.item)
+	}
+
+	if len(rows) == 0 {
+		return page
+	}
+
+	cursorAt := func(row keysetRow[T], backward bool) string {
+		return models.Cursor{
+			Sorting:    sorting,
+			Descending: request.Descending,
+			Value:      row.sortKey,
+			ID:         row.id,
+			Backward:   backward,
+		}.Encode()
+	}
+
+	if (!backward && hasMore) || (backward && request.Cursor != nil) {
+		page.NextCursor = cursorAt(rows[len(rows)-1], false)
+	}
+
+	if (backward && hasMore) || (!backward && (request.Cursor != nil || request.Offset > 0)) {
+		page.PrevCursor = cursorAt(rows[0], true)
+	}
+
+	return page
+}
+
+// applyCursor makes the sorting encoded in the cursor take precedence over query parameters.
+//
+//nolint:lll
+func applyCursor(request models.GetWalletsRequest, validSortParams map[string]sortColumn, defaultSort string) (models.GetWalletsRequest, sortColumn, error) {
+	if request.Cursor != nil {
+		if _, ok := validSortParams[request.Cursor.Sorting]; !ok {
+			return request, sortColumn{}, models.ErrInvalidCursor
+		}
+
+		request.Sorting = request.Cursor.Sorting
+		request.Descending = request.Cursor.Descending
+	}
+
+	if _, ok := validSortParams[request.Sorting]; !ok {
+		request.Sorting = defaultSort
+	}
+
+	return request, validSortParams[request.Sorting], nil
+}
+
+// writeRangeFilters appends an inclusive lower bound and an upper bound on the column.
+// Time ranges are half-open, amount ranges include their upper bound.
+func writeRangeFilters[T any](sb *strings.Builder, args []any, column string, from, to *T, inclusiveUpper bool) []any {
+	if from != nil {
+		args = append(args, *from)
+		sb.WriteString(fmt.Sprintf(` AND %s >= $%d`, column, len(args)))
+	}
+
+	if to != nil {
+		upper := "<"
+		if inclusiveUpper {
+			upper = "<="
+		}
+
+		args = append(args, *to)
+		sb.WriteString(fmt.Sprintf(` AND %s %s $%d`, column, upper, len(args)))
+	}
+
+	return args
+}
+
+func writeSetFilter(sb *strings.Builder, args []any, column string, values []string) []any {
+	if len(values) == 0 {
+		return args
+	}
+
+	args = append(args, values)
+	sb.WriteString(fmt.Sprintf(` AND %s = ANY($%d)`, column, len(args)))
+
+	return args
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+// refilledTokens is the content of an existing bucket after refilling it for the time passed
+// since it was last updated.
+const refilledTokens = `LEAST($2::float8, b.tokens +
+	GREATEST(EXTRACT(EPOCH FROM EXCLUDED.updated_at - b.updated_at)::float8, 0) * $3::float8)`
+
+// TakeRateLimitToken takes a token from the bucket in a single statement, so that replicas
+// sharing the database share the bucket. Missing buckets start full.
+func (d *DataStore) TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitBucket, error) {
+	query := `
+INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, allowed, updated_at)
+VALUES ($1, $2::float8 - 1, TRUE, NOW())
+ON CONFLICT (bucket_key) DO UPDATE
+SET tokens = ` + refilledTokens + ` - CASE WHEN ` + refilledTokens + ` >= 1 THEN 1 ELSE 0 END,
+	allowed = ` + refilledTokens + ` >= 1,
+	updated_at = GREATEST(b.updated_at, EXCLUDED.updated_at)
+RETURNING tokens, allowed`
+
+	perSecond := float64(limit.Requests) / limit.Period.Seconds()
+
+	var bucket models.RateLimitBucket
+
+	if err := d.pool.QueryRow(ctx, query, key, limit.Requests, perSecond).Scan(&bucket.Tokens, &bucket.Allowed); err != nil {
+		return models.RateLimitBucket{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	return bucket, nil
+}
+
+// PurgeRateLimitBuckets deletes buckets unused for longer than idle. They would be full by
+// now, which is how missing buckets start.
+func (d *DataStore) PurgeRateLimitBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	result, err := d.pool.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, time.Now().Add(-idle))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idle rate limit buckets: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (d *DataStore) GetRateLimitOverrides(ctx context.Context) ([]models.RateLimitOverride, error) {
+	rows, err := d.pool.Query(ctx, `SELECT user_id, budget, requests FROM rate_limit_overrides`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting rate limit overrides: %w", err)
+	}
+
+	defer rows.Close()
+
+	overrides := make([]models.RateLimitOverride, 0)
+
+	for rows.Next() {
+		var override models.RateLimitOverride
+
+		if err = rows.Scan(&override.UserID, &override.Budget, &override.Requests); err != nil {
+			return nil, fmt.Errorf("error when scanning rate limit overrides: %w", err)
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return overrides, nil
+}
+
+func (d *DataStore) UpsertRateLimitOverride(ctx context.Context, override models.RateLimitOverride) error {
+	query := `
+INSERT INTO rate_limit_overrides (user_id, budget, requests)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, budget)
+DO UPDATE
+SET requests = excluded.requests`
+
+	if _, err := d.pool.Exec(ctx, query, override.UserID, override.Budget, override.Requests); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.ForeignKeyViolation:
+				return models.ErrUserNotFound
+			case pgerrcode.CheckViolation:
+				return models.ErrInvalidRateLimit
+			}
+		}
+
+		return fmt.Errorf("failed to upsert rate limit override: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRateLimitOverride removes the override, so the configured limit of the budget applies to the user again.
+func (d *DataStore) DeleteRateLimitOverride(ctx context.Context, userID models.UserID, budget string) error {
+	if _, err := d.pool.Exec(ctx, `DELETE FROM rate_limit_overrides WHERE user_id = $1 AND budget = $2`, userID, budget); err != nil {
+		return fmt.Errorf("failed to delete rate limit override: %w", err)
+	}
+
+	return nil
+}
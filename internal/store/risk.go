@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const (
+	riskRuleColumns   = `name, kind, action, enabled, params, updated_at`
+	assessmentColumns = `assessment_id, user_id, outcome, rules, transaction, status, created_at, decided_at, decided_by`
+
+	maxRiskHistory = 1000
+)
+
+func (d *DataStore) GetRiskRules(ctx context.Context) ([]models.RiskRule, error) {
+	rows, err := d.getTXFromCtx(ctx).Query(ctx, `SELECT `+riskRuleColumns+` FROM risk_rules ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting risk rules: %w", err)
+	}
+
+	defer rows.Close()
+
+	rules := make([]models.RiskRule, 0)
+
+	for rows.Next() {
+		rule, err := scanRiskRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning risk rules: %w", err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return rules, nil
+}
+
+func (d *DataStore) UpsertRiskRule(ctx context.Context, rule models.RiskRule) (models.RiskRule, error) {
+	query := `
+INSERT INTO risk_rules (name, kind, action, enabled, params)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name)
+DO UPDATE
+SET kind = excluded.kind,
+	action = excluded.action,
+	enabled = excluded.enabled,
+	params = excluded.params,
+	updated_at = NOW()
+RETURNING ` + riskRuleColumns
+
+	saved, err := scanRiskRule(d.pool.QueryRow(ctx, query, rule.Name, rule.Kind, rule.Action, rule.Enabled, rule.Params))
+	if err != nil {
+		return models.RiskRule{}, fmt.Errorf("failed to save risk rule: %w", err)
+	}
+
+	return saved, nil
+}
+
+func (d *DataStore) DeleteRiskRule(ctx context.Context, name string) error {
+	result, err := d.pool.Exec(ctx, `DELETE FROM risk_rules WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete risk rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrRiskRuleNotFound
+	}
+
+	return nil
+}
+
+// GetRiskHistory returns the latest withdrawals and transfers the user committed since the time.
+func (d *DataStore) GetRiskHistory(ctx context.Context, userID models.UserID, since time.Time) ([]models.RiskTransaction, error) {
+	query := `
+SELECT t.transaction_type, t.from_wallet_id, t.to_wallet_id, t.amount::float8, t.currency, COALESCE(w.currency, ''), t.committed_at
+FROM transactions t
+LEFT JOIN wallets w ON w.wallet_id = t.to_wallet_id
+WHERE TRUE
+	AND t.user_id = $1
+	AND t.committed_at >= $2
+	AND t.transaction_type IN ('withdraw', 'transfer')
+ORDER BY t.committed_at DESC
+LIMIT $3`
+
+	rows, err := d.getTXFromCtx(ctx).Query(ctx, query, userID, since, maxRiskHistory)
+	if err != nil {
+		return nil, fmt.Errorf("error getting risk history: %w", err)
+	}
+
+	defer rows.Close()
+
+	history := make([]models.RiskTransaction, 0)
+
+	for rows.Next() {
+		var t models.RiskTransaction
+
+		if err = rows.Scan(&t.Type, &t.FromWalletID, &t.ToWalletID, &t.Amount, &t.Currency, &t.ToCurrency, &t.CommittedAt); err != nil {
+			return nil, fmt.Errorf("error when scanning risk history: %w", err)
+		}
+
+		history = append(history, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return history, nil
+}
+
+func (d *DataStore) CreateRiskAssessment(ctx context.Context, assessment models.RiskAssessment) (models.RiskAssessment, error) {
+	query := `
+INSERT INTO risk_assessments (assessment_id, user_id, outcome, rules, transaction, status)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+RETURNING ` + assessmentColumns
+
+	created, err := scanAssessment(d.pool.QueryRow(ctx, query,
+		assessment.AssessmentID,
+		assessment.UserID,
+		assessment.Outcome,
+		assessment.Rules,
+		assessment.Transaction,
+		assessment.Status,
+	))
+	if err != nil {
+		return models.RiskAssessment{}, fmt.Errorf("failed to create risk assessment: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetRiskAssessments returns the held transactions with the status, oldest first.
+func (d *DataStore) GetRiskAssessments(ctx context.Context, status string) ([]models.RiskAssessment, error) {
+	query := `SELECT ` + assessmentColumns + ` FROM risk_assessments WHERE status = $1 ORDER BY created_at, assessment_id`
+
+	rows, err := d.pool.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting risk assessments: %w", err)
+	}
+
+	defer rows.Close()
+
+	assessments := make([]models.RiskAssessment, 0)
+
+	for rows.Next() {
+		assessment, err := scanAssessment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning risk assessments: %w", err)
+		}
+
+		assessments = append(assessments, assessment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return assessments, nil
+}
+
+// DecideRiskAssessment moves a pending assessment to the status. Run within the transaction that
+// commits an approved transaction, so that a transaction is committed at most once.
+func (d *DataStore) DecideRiskAssessment(ctx context.Context, assessmentID models.AssessmentID, status string,
+	decidedBy models.UserID,
+) (models.RiskAssessment, error) {
+	tx := d.getTXFromCtx(ctx)
+
+	query := `
+UPDATE risk_assessments
+SET status = $2, decided_at = NOW(), decided_by = $3
+WHERE assessment_id = $1 AND status = '` + models.ReviewStatusPending + `'
+RETURNING ` + assessmentColumns
+
+	decided, err := scanAssessment(tx.QueryRow(ctx, query, assessmentID, status, decidedBy))
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		var exists bool
+
+		if err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM risk_assessments WHERE assessment_id = $1 AND status IS NOT NULL)`,
+			assessmentID).Scan(&exists); err != nil {
+			return models.RiskAssessment{}, fmt.Errorf("failed to check risk assessment: %w", err)
+		}
+
+		if exists {
+			return models.RiskAssessment{}, models.ErrAssessmentDecided
+		}
+
+		return models.RiskAssessment{}, models.ErrAssessmentNotFound
+	case err != nil:
+		return models.RiskAssessment{}, fmt.Errorf("failed to decide risk assessment: %w", err)
+	}
+
+	return decided, nil
+}
+
+func scanRiskRule(row pgx.Row) (models.RiskRule, error) {
+	var rule models.RiskRule
+
+	if err := row.Scan(&rule.Name, &rule.Kind, &rule.Action, &rule.Enabled, &rule.Params, &rule.UpdatedAt); err != nil {
+		return models.RiskRule{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return rule, nil
+}
+
+func scanAssessment(row pgx.Row) (models.RiskAssessment, error) {
+	var (
+		assessment models.RiskAssessment
+		status     *string
+	)
+
+	if err := row.Scan(
+		&assessment.AssessmentID,
+		&assessment.UserID,
+		&assessment.Outcome,
+		&assessment.Rules,
+		&assessment.Transaction,
+		&status,
+		&assessment.CreatedAt,
+		&assessment.DecidedAt,
+		&assessment.DecidedBy,
+	); err != nil {
+		return models.RiskAssessment{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	if status != nil {
+		assessment.Status = *status
+	}
+
+	return assessment, nil
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func (d *DataStore) Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error {
+func (d *DataStore) Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error) {
 	tx := d.getTXFromCtx(ctx)
 
 	query := `
@@ -23,28 +23,30 @@ UPDATE wallets
 SET balance = balance + $3::numeric * $4::numeric, updated_at = NOW() 
 WHERE TRUE
 	AND wallet_id = $1 
-	AND user_id = $2 
-	AND active = true`
+	AND ` + memberHasRole("$2", models.MemberRoleOwner, models.MemberRoleSpender) + `
+	AND active = true
+	AND frozen_at IS NULL`
 
 	result, err := tx.Exec(ctx, query, transaction.ToWalletID, userID, transaction.Amount, rate)
 	if err != nil {
-		return fmt.Errorf("failed to update wallet balance info: %w", err)
+		return models.Transaction{}, fmt.Errorf("failed to update wallet balance info: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return models.ErrWalletNotFound
+		return models.Transaction{}, balanceNotUpdated(ctx, tx, transaction.ToWalletID)
 	}
 
 	transaction.Type = "deposit"
 
-	if err := d.storeTxIntoTable(ctx, transaction, tx); err != nil {
-		return fmt.Errorf("failed to store transaction into database: %w", err)
+	stored, err := d.storeTxIntoTable(ctx, transaction, userID, tx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to store transaction into database: %w", err)
 	}
 
-	return nil
+	return stored, nil
 }
 
-func (d *DataStore) Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error {
+func (d *DataStore) Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error) {
 	tx := d.getTXFromCtx(ctx)
 
 	query := `
@@ -52,28 +54,30 @@ UPDATE wallets
 SET balance = balance - $3::numeric * $4::numeric, updated_at = NOW()
 WHERE TRUE 
 	AND wallet_id = $1 
-	AND user_id = $2 
-	AND active = true`
+	AND ` + memberHasRole("$2", models.MemberRoleOwner, models.MemberRoleSpender) + `
+	AND active = true
+	AND frozen_at IS NULL`
 
 	result, err := tx.Exec(ctx, query, transaction.FromWalletID, userID, transaction.Amount, rate)
 	if err != nil {
-		return fmt.Errorf("failed to update wallet balance info: %w", err)
+		return models.Transaction{}, fmt.Errorf("failed to update wallet balance info: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return models.ErrWalletNotFound
+		return models.Transaction{}, balanceNotUpdated(ctx, tx, transaction.FromWalletID)
 	}
 
 	transaction.Type = "withdraw"
 
-	if err := d.storeTxIntoTable(ctx, transaction, tx); err != nil {
-		return fmt.Errorf("failed to store transaction into database: %w", err)
+	stored, err := d.storeTxIntoTable(ctx, transaction, userID, tx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to store transaction into database: %w", err)
 	}
 
-	return nil
+	return stored, nil
 }
 
-func (d *DataStore) Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) error {
+func (d *DataStore) Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID, rate float64) (models.Transaction, error) {
 	tx := d.getTXFromCtx(ctx)
 
 	queryFrom := `
@@ -81,16 +85,17 @@ UPDATE wallets
 SET balance = balance - $3::numeric, updated_at = NOW()
 WHERE TRUE 
 	AND wallet_id = $1 
-	AND user_id = $2 
-	AND active = true`
+	AND ` + memberHasRole("$2", models.MemberRoleOwner, models.MemberRoleSpender) + `
+	AND active = true
+	AND frozen_at IS NULL`
 
 	resultFrom, err := tx.Exec(ctx, queryFrom, transaction.FromWalletID, userID, transaction.Amount)
 	if err != nil {
-		return fmt.Errorf("failed to update wallet balance info: %w", err)
+		return models.Transaction{}, fmt.Errorf("failed to update wallet balance info: %w", err)
 	}
 
 	if resultFrom.RowsAffected() == 0 {
-		return models.ErrWalletNotFound
+		return models.Transaction{}, balanceNotUpdated(ctx, tx, transaction.FromWalletID)
 	}
 
 	queryTo := `
@@ -98,138 +103,241 @@ UPDATE wallets
 SET balance = balance + $3::numeric * $4::numeric, updated_at = NOW()
 WHERE TRUE 
 	AND wallet_id = $1 
-	AND user_id = $2 
-	AND active = true`
+	AND ` + memberHasRole("$2", models.MemberRoleOwner, models.MemberRoleSpender) + `
+	AND active = true
+	AND frozen_at IS NULL`
 
 	resultTo, err := tx.Exec(ctx, queryTo, transaction.ToWalletID, userID, transaction.Amount, rate)
 	if err != nil {
-		return fmt.Errorf("failed to update wallet balance info: %w", err)
+		return models.Transaction{}, fmt.Errorf("failed to update wallet balance info: %w", err)
 	}
 
 	if resultTo.RowsAffected() == 0 {
-		return models.ErrWalletNotFound
+		return models.Transaction{}, balanceNotUpdated(ctx, tx, transaction.ToWalletID)
 	}
 
 	transaction.Type = "transfer"
 
-	if err := d.storeTxIntoTable(ctx, transaction, tx); err != nil {
-		return fmt.Errorf("failed to store transaction into database: %w", err)
+	stored, err := d.storeTxIntoTable(ctx, transaction, userID, tx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to store transaction into database: %w", err)
 	}
 
-	return nil
+	return stored, nil
+}
+
+// balanceNotUpdated explains why a balance update matched no wallet. The service checks the
+// wallet before, but it may have been frozen since.
+func balanceNotUpdated(ctx context.Context, tx transaction, walletID *models.WalletID) error {
+	var frozen bool
+
+	err := tx.QueryRow(ctx, `SELECT frozen_at IS NOT NULL FROM wallets WHERE wallet_id = $1`, walletID).Scan(&frozen)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to check whether the wallet is frozen: %w", err)
+	}
+
+	if frozen {
+		return models.ErrWalletFrozen
+	}
+
+	return models.ErrWalletNotFound
+}
+
+//nolint:gochecknoglobals
+var transactionSortParams = map[string]sortColumn{
+	"transaction_type": {column: "transaction_type", pgType: "varchar"},
+	"currency":         {column: "currency", pgType: "varchar"},
+	"committed_at":     {column: "committed_at", pgType: "timestamptz"},
+	"amount":           {column: "amount", pgType: "numeric"},
 }
 
 //nolint:lll
-func (d *DataStore) GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) ([]models.Transaction, error) {
+func (d *DataStore) GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) (models.TransactionsPage, error) {
 	_, err := d.GetWallet(ctx, walletID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract wallet: %w", err)
+		return models.TransactionsPage{}, fmt.Errorf("failed to extract wallet: %w", err)
+	}
+
+	request, sort, err := applyCursor(request, transactionSortParams, "committed_at")
+	if err != nil {
+		return models.TransactionsPage{}, err
 	}
 
 	var (
-		transactionsAll []models.Transaction
+		transactionsAll []keysetRow[models.Transaction]
 		rows            pgx.Rows
 	)
 
-	query, args := d.GetTransactionsQuery(request, walletID)
+	query, args := d.GetTransactionsQuery(request, sort, walletID, userID)
 
 	if rows, err = d.pool.Query(ctx, query, args...); err != nil {
-		return nil, fmt.Errorf("error getting all the transactions: %w", err)
+		return models.TransactionsPage{}, fmt.Errorf("error getting all the transactions: %w", err)
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
-		var transaction models.Transaction
+		var row keysetRow[models.Transaction]
 
 		err = rows.Scan(
-			&transaction.ID,
-			&transaction.Type,
-			&transaction.ToWalletID,
-			&transaction.FromWalletID,
-			&transaction.Amount,
-			&transaction.Currency,
-			&transaction.CommittedAt,
+			&row.item.ID,
+			&row.item.Type,
+			&row.item.ToWalletID,
+			&row.item.FromWalletID,
+			&row.item.Amount,
+			&row.item.Currency,
+			&row.item.CommittedAt,
+			&row.item.Description,
+			&row.item.CategoryID,
+			&row.item.ExternalReference,
+			&row.item.Metadata,
+			&row.item.PerformedBy,
+			&row.sortKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("error when scanning transactions: %w", err)
+			return models.TransactionsPage{}, fmt.Errorf("error when scanning transactions: %w", err)
 		}
 
-		transactionsAll = append(transactionsAll, transaction)
+		row.id = uuid.UUID(row.item.ID)
+		transactionsAll = append(transactionsAll, row)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows.Err(): %w", err)
+		return models.TransactionsPage{}, fmt.Errorf("rows.Err(): %w", err)
 	}
 
-	if len(transactionsAll) == 0 {
-		return []models.Transaction{}, nil
+	if err = d.attachCounterparties(ctx, transactionsAll, walletID); err != nil {
+		return models.TransactionsPage{}, err
 	}
 
-	return transactionsAll, nil
+	return newPage(transactionsAll, request, request.Sorting), nil
 }
 
-func (d *DataStore) GetTransactionsQuery(request models.GetWalletsRequest, walletID models.WalletID) (string, []any) {
-	var (
-		sb              strings.Builder
-		args            []any
-		validSortParams = map[string]string{
-			"transaction_type": "transaction_type",
-			"currency":         "currency",
+// attachCounterparties sets the owner of the other wallet on the transfers of the wallet.
+func (d *DataStore) attachCounterparties(ctx context.Context, rows []keysetRow[models.Transaction], walletID models.WalletID) error {
+	others := make([]*models.WalletID, len(rows))
+	walletIDs := make([]models.WalletID, 0, len(rows))
+
+	for i, row := range rows {
+		if row.item.ToWalletID == nil || row.item.FromWalletID == nil {
+			continue
+		}
+
+		others[i] = row.item.ToWalletID
+		if *others[i] == walletID {
+			others[i] = row.item.FromWalletID
+		}
+
+		walletIDs = append(walletIDs, *others[i])
+	}
+
+	counterparties, err := d.counterparties(ctx, walletIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, other := range others {
+		if other == nil {
+			continue
+		}
+
+		if counterparty, ok := counterparties[*other]; ok {
+			rows[i].item.Counterparty = &counterparty
 		}
+	}
+
+	return nil
+}
+
+// GetTransactionsQuery lists the transactions of the wallet with the categories the user assigned.
+//
+//nolint:lll
+func (d *DataStore) GetTransactionsQuery(request models.GetWalletsRequest, sort sortColumn, walletID models.WalletID, userID models.UserID) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
 	)
 
-	sb.WriteString(`SELECT id, transaction_type, to_wallet_id, from_wallet_id, amount, currency, committed_at
+	args = append(args, userID)
+	sb.WriteString(fmt.Sprintf(`SELECT id, transaction_type, to_wallet_id, from_wallet_id, amount, currency, committed_at, description, %s,
+							external_reference, metadata, user_id, %s::text
 						FROM transactions
-						WHERE`)
+						WHERE`, transactionCategory("transactions.id", "$1"), sort.column))
 
+	args = writeTransactionFilters(&sb, args, request, walletID, userID)
+	args = writeKeyset(&sb, args, request, sort, "id")
+
+	return sb.String(), args
+}
+
+// writeTransactionFilters appends the conditions shared by the transaction listing and the
+// category totals: the wallet itself and every filter of the request. Categories are those the
+// user assigned.
+//
+//nolint:lll
+func writeTransactionFilters(sb *strings.Builder, args []any, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) []any {
 	args = append(args, walletID)
-	sb.WriteString(fmt.Sprintf(` to_wallet_id = $%d`, len(args)))
+	sb.WriteString(fmt.Sprintf(` (to_wallet_id = $%d`, len(args)))
 	args = append(args, walletID)
-	sb.WriteString(fmt.Sprintf(` OR from_wallet_id = $%d`, len(args)))
+	sb.WriteString(fmt.Sprintf(` OR from_wallet_id = $%d)`, len(args)))
 
 	if request.Filter != "" {
 		args = append(args, "%"+request.Filter+"%")
-		sb.WriteString(fmt.Sprintf(` AND concat_ws('', id, transaction_type, amount, currency, committed_at) ILIKE $%d`, len(args)))
+		sb.WriteString(fmt.Sprintf(` AND (transaction_type ILIKE $%[1]d OR currency ILIKE $%[1]d
+			OR description ILIKE $%[1]d OR external_reference ILIKE $%[1]d)`, len(args)))
 	}
 
-	sorting, ok := validSortParams[request.Sorting]
-	if !ok {
-		sorting = "transaction_type"
+	if request.ExternalReference != nil {
+		args = append(args, *request.ExternalReference)
+		sb.WriteString(fmt.Sprintf(` AND external_reference = $%d`, len(args)))
 	}
 
-	sb.WriteString(" ORDER BY " + sorting)
-
-	if request.Descending {
-		sb.WriteString(" DESC")
+	if len(request.Metadata) > 0 {
+		args = append(args, request.Metadata)
+		sb.WriteString(fmt.Sprintf(` AND metadata @> $%d::jsonb`, len(args)))
 	}
 
-	args = append(args, request.Limit)
-	sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
-
-	if request.Offset > 0 {
-		args = append(args, request.Offset)
-		sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	if request.Counterparty != nil {
+		args = append(args, request.Counterparty)
+		sb.WriteString(fmt.Sprintf(` AND (to_wallet_id = $%d OR from_wallet_id = $%d)`, len(args), len(args)))
 	}
 
-	return sb.String(), args
+	args = writeRangeFilters(sb, args, "committed_at", request.From, request.To, false)
+	args = writeRangeFilters(sb, args, "amount", request.MinAmount, request.MaxAmount, true)
+	args = writeSetFilter(sb, args, "transaction_type", request.Types)
+	args = writeSetFilter(sb, args, "currency", request.Currencies)
+	args = writeCategoryFilter(sb, args, request, userID)
+
+	return args
 }
 
-func (d *DataStore) storeTxIntoTable(ctx context.Context, transaction models.Transaction, tx transaction) error {
+// storeTxIntoTable records the transaction and returns it as stored, with the category the acting
+// user's rules assigned.
+//
+//nolint:lll,funlen
+func (d *DataStore) storeTxIntoTable(ctx context.Context, transaction models.Transaction, userID models.UserID, tx transaction) (models.Transaction, error) {
+	transaction.ID = models.TxID(uuid.New())
 	transaction.CommittedAt = time.Now()
+	transaction.PerformedBy = &userID
+	transaction.CategoryID = nil
 
 	query := `
-INSERT INTO transactions (id, transaction_type, to_wallet_id, from_wallet_id, amount, currency, committed_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)`
+INSERT INTO transactions (id, transaction_type, to_wallet_id, from_wallet_id, amount, currency, committed_at, description,
+	user_id, external_reference, metadata)
+VALUES ($1, $2, $3::uuid, $4::uuid, $5::numeric, $6, $7, $8::varchar, $9, $10, COALESCE($11::jsonb, '{}'))`
 
 	args := []any{
-		uuid.New(),
+		transaction.ID,
 		transaction.Type,
 		nil,
 		nil,
 		transaction.Amount,
 		transaction.Currency,
 		transaction.CommittedAt,
+		transaction.Description,
+		userID,
+		transaction.ExternalReference,
+		transaction.Metadata,
 	}
 
 	if transaction.ToWalletID != nil {
@@ -246,11 +354,35 @@ VALUES ($1, $2, $3, $4, $5, $6, $7)`
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
 			log.Error().Err(err).Msg("wallet not found: foreign key violation")
 
-			return models.ErrWalletNotFound
+			return models.Transaction{}, models.ErrWalletNotFound
 		}
 
-		return fmt.Errorf("failed to save transaction history in database: %w", err)
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return models.Transaction{}, models.ErrDuplicateExternalReference
+		}
+
+		return models.Transaction{}, fmt.Errorf("failed to save transaction history in database: %w", err)
 	}
 
-	return nil
+	// Every member of the wallets involved gets the transaction categorized by their own rules.
+	categorize := `
+WITH categorized AS (
+	INSERT INTO transaction_categories (transaction_id, user_id, category_id)
+	SELECT $1, m.user_id, m.category_id
+	FROM (
+		SELECT u.user_id, ` + matchCategoryRule("u.user_id", "$2", "$5::numeric", "$3::uuid", "$4::uuid", "$6::varchar") + ` AS category_id
+		FROM (SELECT DISTINCT user_id FROM wallet_members WHERE wallet_id IN ($3, $4)) u
+	) m
+	WHERE m.category_id IS NOT NULL
+	RETURNING user_id, category_id
+)
+SELECT category_id FROM categorized WHERE user_id = $7`
+
+	err := tx.QueryRow(ctx, categorize, transaction.ID, args[1], args[2], args[3], args[4], transaction.Description, userID).
+		Scan(&transaction.CategoryID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.Transaction{}, fmt.Errorf("failed to categorize transaction: %w", err)
+	}
+
+	return transaction, nil
 }
@@ -0,0 +1,354 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// ApplyUserEvent records a lifecycle event of a user and the profile it carries. Deleting a user
+// freezes the wallets the user owns and restoring the user unfreezes them again. Events older
+// than the last one applied to the user are ignored, so that a redelivered event cannot undo a
+// later one.
+func (d *DataStore) ApplyUserEvent(ctx context.Context, event models.UserEvent) error {
+	if err := d.DoWithTx(ctx, func(ctx context.Context) error {
+		tx := d.getTXFromCtx(ctx)
+
+		if event.Profile != nil {
+			if err := d.saveUserProfile(ctx, event); err != nil {
+				return err
+			}
+		}
+
+		if event.Type == models.UserEventCreated {
+			if _, err := tx.Exec(ctx, `
+INSERT INTO users (user_id) VALUES ($1)
+ON CONFLICT (user_id) DO NOTHING`, event.UserID); err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+
+			return nil
+		}
+
+		deleted := event.Type == models.UserEventDeleted
+
+		tag, err := tx.Exec(ctx, `
+INSERT INTO users (user_id, deleted_at, lifecycle_at)
+VALUES ($1, CASE WHEN $2 THEN $3::timestamptz END, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET deleted_at = CASE WHEN $2 THEN COALESCE(users.deleted_at, excluded.deleted_at) END,
+	lifecycle_at = excluded.lifecycle_at
+WHERE users.lifecycle_at IS NULL OR users.lifecycle_at <= excluded.lifecycle_at`,
+			event.UserID, deleted, event.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			log.Debug().Str("type", event.Type).Msg("ignoring user event older than the last one applied")
+
+			return nil
+		}
+
+		query, args := `
+UPDATE wallets
+SET frozen_at = NULL
+WHERE user_id = $1 AND frozen_at IS NOT NULL`, []any{event.UserID}
+
+		if deleted {
+			query, args = `
+UPDATE wallets
+SET frozen_at = $2
+WHERE user_id = $1 AND deleted_at IS NULL AND frozen_at IS NULL`, []any{event.UserID, event.OccurredAt}
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to change frozen wallets: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error in DoWithTx(): %w", err)
+	}
+
+	return nil
+}
+
+// UserDeleted reports whether the user is deleted. Users the service has not heard of yet are not.
+func (d *DataStore) UserDeleted(ctx context.Context, userID models.UserID) (bool, error) {
+	var deleted bool
+
+	if err := d.getTXFromCtx(ctx).QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1 AND deleted_at IS NOT NULL)`, userID).Scan(&deleted); err != nil {
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// saveUserProfile stores the profile of the event encrypted, unless a later profile is stored.
+func (d *DataStore) saveUserProfile(ctx context.Context, event models.UserEvent) error {
+	var age string
+	if event.Profile.Age > 0 {
+		age = strconv.Itoa(event.Profile.Age)
+	}
+
+	args := []any{event.UserID, event.OccurredAt}
+
+	for _, value := range []string{event.Profile.FirstName, event.Profile.LastName, event.Profile.Gender, age} {
+		encrypted, err := d.pii.Encrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt user profile: %w", err)
+		}
+
+		args = append(args, encrypted)
+	}
+
+	args = append(args, d.pii.Index(event.Profile.FirstName), d.pii.Index(event.Profile.LastName))
+
+	if _, err := d.getTXFromCtx(ctx).Exec(ctx, `
+INSERT INTO users (user_id, profile_updated_at, first_name, last_name, gender, age, first_name_index, last_name_index)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (user_id) DO UPDATE
+SET profile_updated_at = excluded.profile_updated_at,
+	first_name = excluded.first_name,
+	last_name = excluded.last_name,
+	gender = excluded.gender,
+	age = excluded.age,
+	first_name_index = excluded.first_name_index,
+	last_name_index = excluded.last_name_index
+WHERE users.profile_updated_at IS NULL OR users.profile_updated_at <= excluded.profile_updated_at`, args...); err != nil {
+		return fmt.Errorf("failed to save user profile: %w", err)
+	}
+
+	return nil
+}
+
+const userAccountColumns = `u.user_id, u.first_name, u.last_name, u.gender, u.age, u.preferences, u.deleted_at,
+	(SELECT COUNT(*) FROM wallets w WHERE w.user_id = u.user_id AND w.deleted_at IS NULL)`
+
+func (d *DataStore) GetUserAccount(ctx context.Context, userID models.UserID) (models.UserAccount, error) {
+	account, err := d.scanUserAccount(d.getTXFromCtx(ctx).QueryRow(ctx, `
+SELECT `+userAccountColumns+`
+FROM users u
+WHERE u.user_id = $1`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserAccount{}, models.ErrUserNotFound
+		}
+
+		return models.UserAccount{}, err
+	}
+
+	return account, nil
+}
+
+func (d *DataStore) UpdateUserPreferences(ctx context.Context, userID models.UserID, preferences models.UserPreferences) error {
+	tag, err := d.getTXFromCtx(ctx).Exec(ctx, `
+UPDATE users SET preferences = $2 WHERE user_id = $1`, userID, preferences)
+	if err != nil {
+		return fmt.Errorf("failed to update user preferences: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (d *DataStore) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserAccount, error) {
+	query, args := d.SearchUsersQuery(search)
+
+	rows, err := d.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching users: %w", err)
+	}
+
+	defer rows.Close()
+
+	accounts := make([]models.UserAccount, 0)
+
+	for rows.Next() {
+		account, err := d.scanUserAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return accounts, nil
+}
+
+func (d *DataStore) SearchUsersQuery(search models.UserSearch) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	sb.WriteString(`SELECT ` + userAccountColumns + `
+					FROM users u
+					WHERE TRUE`)
+
+	if search.UserID != nil {
+		args = append(args, uuid.UUID(*search.UserID))
+		sb.WriteString(fmt.Sprintf(` AND u.user_id = $%d`, len(args)))
+	}
+
+	if search.FirstName != "" {
+		args = append(args, d.pii.Index(search.FirstName))
+		sb.WriteString(fmt.Sprintf(` AND u.first_name_index = $%d`, len(args)))
+	}
+
+	if search.LastName != "" {
+		args = append(args, d.pii.Index(search.LastName))
+		sb.WriteString(fmt.Sprintf(` AND u.last_name_index = $%d`, len(args)))
+	}
+
+	if search.Deleted != nil {
+		args = append(args, *search.Deleted)
+		sb.WriteString(fmt.Sprintf(` AND (u.deleted_at IS NOT NULL) = $%d`, len(args)))
+	}
+
+	sb.WriteString(" ORDER BY u.user_id")
+
+	if search.Limit > 0 {
+		args = append(args, search.Limit)
+		sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	}
+
+	if search.Offset > 0 {
+		args = append(args, search.Offset)
+		sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	}
+
+	return sb.String(), args
+}
+
+func (d *DataStore) scanUserAccount(row pgx.Row) (models.UserAccount, error) {
+	var (
+		account models.UserAccount
+		fields  [4][]byte
+	)
+
+	if err := row.Scan(
+		&account.UserID,
+		&fields[0],
+		&fields[1],
+		&fields[2],
+		&fields[3],
+		&account.Preferences,
+		&account.DeletedAt,
+		&account.WalletCount,
+	); err != nil {
+		return models.UserAccount{}, fmt.Errorf("failed to scan user: %w", err)
+	}
+
+	profile, err := d.decryptProfile(fields)
+	if err != nil {
+		return models.UserAccount{}, err
+	}
+
+	account.Profile = profile
+
+	return account, nil
+}
+
+// decryptProfile returns the profile stored in the first name, last name, gender and age
+// columns, or nil when the service has not received a profile of the user.
+func (d *DataStore) decryptProfile(fields [4][]byte) (*models.UserProfile, error) {
+	var values [4]string
+
+	for i, field := range fields {
+		value, err := d.pii.Decrypt(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt user profile: %w", err)
+		}
+
+		values[i] = value
+	}
+
+	if values == [4]string{} {
+		return nil, nil //nolint:nilnil
+	}
+
+	profile := &models.UserProfile{FirstName: values[0], LastName: values[1], Gender: values[2]}
+
+	if values[3] != "" {
+		age, err := strconv.Atoi(values[3])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user age: %w", err)
+		}
+
+		profile.Age = age
+	}
+
+	return profile, nil
+}
+
+// counterparties returns the owners of the wallets with their display names, for the other
+// side of transfers.
+func (d *DataStore) counterparties(ctx context.Context, walletIDs []models.WalletID) (map[models.WalletID]models.Counterparty, error) {
+	result := make(map[models.WalletID]models.Counterparty, len(walletIDs))
+
+	if len(walletIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(walletIDs))
+	for _, id := range walletIDs {
+		ids = append(ids, uuid.UUID(id))
+	}
+
+	rows, err := d.getTXFromCtx(ctx).Query(ctx, `
+SELECT w.wallet_id, w.user_id, u.first_name, u.last_name
+FROM wallets w
+	JOIN users u ON u.user_id = w.user_id
+WHERE w.wallet_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error getting counterparties: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			walletID     models.WalletID
+			counterparty models.Counterparty
+			fields       [4][]byte
+		)
+
+		if err = rows.Scan(&walletID, &counterparty.UserID, &fields[0], &fields[1]); err != nil {
+			return nil, fmt.Errorf("failed to scan counterparty: %w", err)
+		}
+
+		profile, err := d.decryptProfile(fields)
+		if err != nil {
+			return nil, err
+		}
+
+		if profile != nil {
+			counterparty.DisplayName = profile.DisplayName()
+		}
+
+		result[walletID] = counterparty
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return result, nil
+}
@@ -15,11 +15,17 @@ import (
 
 func (d *DataStore) CreateWallet(ctx context.Context, wallet models.Wallet, userID models.UserID) (models.Wallet, error) {
 	query := `
-INSERT INTO wallets (wallet_id, user_id, wallet_name, currency)
-VALUES ($1, $2, $3, $4)
-RETURNING wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, active`
+WITH created AS (
+	INSERT INTO wallets (wallet_id, user_id, wallet_name, currency)
+	VALUES ($1, $2, $3, $4)
+	RETURNING wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, active
+), owner AS (
+	INSERT INTO wallet_members (wallet_id, user_id, role)
+	SELECT wallet_id, user_id, 'owner' FROM created
+)
+SELECT wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, active FROM created`
 
-	row := d.pool.QueryRow(ctx, query,
+	row := d.getTXFromCtx(ctx).QueryRow(ctx, query,
 		wallet.WalletID,
 		userID,
 		wallet.WalletName,
@@ -49,29 +55,30 @@ type querier interface {
 	QueryRow(ctx context.Context, query string, args ...any) pgx.Row
 }
 
-//nolint:ineffassign,wastedassign
 func (d *DataStore) GetWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) (models.Wallet, error) {
 	var wallet models.Wallet
 
 	query := `
-SELECT wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, active
-FROM wallets
+SELECT w.wallet_id, w.user_id, w.wallet_name, w.balance, w.currency, w.created_at, w.updated_at, w.active, w.frozen_at,
+	m.role, m.spend_limit::float8, m.created_at, m.updated_at
+FROM wallets w
+JOIN wallet_members m ON m.wallet_id = w.wallet_id
 WHERE TRUE 
-	AND wallet_id = $1 
-	AND user_id = $2 
-	AND deleted_at IS NULL`
+	AND w.wallet_id = $1 
+	AND m.user_id = $2 
+	AND w.deleted_at IS NULL`
 
-	var db querier
+	var db querier = d.getTXFromCtx(ctx)
 
-	db = d.getTXFromCtx(ctx)
-
-	if db == nil {
-		db = d.pool
-	} else {
-		query += ` FOR UPDATE`
+	// Within a transaction the wallet and the membership stay locked until it ends, so the balance,
+	// role and spend limit checked by the service cannot change before the balance is updated.
+	if _, ok := db.(pgx.Tx); ok {
+		query += ` FOR UPDATE OF w FOR SHARE OF m`
 	}
 
-	err := d.pool.QueryRow(ctx, query, walletID, userID).Scan(
+	membership := models.WalletMember{WalletID: walletID, UserID: userID}
+
+	err := db.QueryRow(ctx, query, walletID, userID).Scan(
 		&wallet.WalletID,
 		&wallet.UserID,
 		&wallet.WalletName,
@@ -80,6 +87,11 @@ WHERE TRUE
 		&wallet.CreatedAt,
 		&wallet.UpdatedAt,
 		&wallet.Active,
+		&wallet.FrozenAt,
+		&membership.Role,
+		&membership.SpendLimit,
+		&membership.CreatedAt,
+		&membership.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -89,6 +101,8 @@ WHERE TRUE
 		return models.Wallet{}, fmt.Errorf("failed to get wallet info: %w", err)
 	}
 
+	wallet.Membership = &membership
+
 	return wallet, nil
 }
 
@@ -101,7 +115,7 @@ UPDATE wallets
 SET wallet_name = $1, currency = $2, balance = $3 * balance, updated_at = $4
 WHERE TRUE 
 	AND wallet_id = $5 
-	AND user_id = $6 
+	AND ` + memberHasRole("$6", models.MemberRoleOwner) + `
 	AND deleted_at IS NULL
 RETURNING wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, deleted_at, active`
 
@@ -159,11 +173,11 @@ UPDATE wallets
 SET deleted_at = NOW(), active = false
 WHERE TRUE 
 	AND wallet_id = $1 
-	AND user_id = $2
+	AND ` + memberHasRole("$2", models.MemberRoleOwner) + `
 	AND deleted_at IS NULL 
 	AND active = true`
 
-	_, err = d.pool.Exec(ctx, query, walletID, userID)
+	_, err = d.getTXFromCtx(ctx).Exec(ctx, query, walletID, userID)
 	if err != nil {
 		return fmt.Errorf("error deleting wallet %s: %w", uuid.UUID(walletID).String(), err)
 	}
@@ -171,94 +185,142 @@ WHERE TRUE
 	return nil
 }
 
-func (d *DataStore) GetWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) ([]models.Wallet, error) {
-	var (
-		walletsAll []models.Wallet
-		rows       pgx.Rows
-		err        error
-	)
+//nolint:gochecknoglobals
+var walletSortParams = map[string]sortColumn{
+	"wallet_name": {column: "wallet_name", pgType: "varchar"},
+	"currency":    {column: "currency", pgType: "varchar"},
+	"created_at":  {column: "created_at", pgType: "timestamp"},
+	"updated_at":  {column: "updated_at", pgType: "timestamp"},
+	"balance":     {column: "balance", pgType: "numeric"},
+}
 
-	query, args := d.GetWalletsQuery(request, userID)
+//nolint:lll
+func (d *DataStore) GetWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) (models.WalletsPage, error) {
+	request, sort, err := applyCursor(request, walletSortParams, "currency")
+	if err != nil {
+		return models.WalletsPage{}, err
+	}
+
+	query, args := d.GetWalletsQuery(request, sort, userID)
 
-	if rows, err = d.pool.Query(ctx, query, args...); err != nil {
-		return nil, fmt.Errorf("error getting all wallets info: %w", err)
+	rows, err := d.pool.Query(ctx, query, args...)
+	if err != nil {
+		return models.WalletsPage{}, fmt.Errorf("error getting all wallets info: %w", err)
 	}
 
 	defer rows.Close()
 
+	var walletsAll []keysetRow[models.Wallet]
+
 	for rows.Next() {
-		var wallet models.Wallet
+		var row keysetRow[models.Wallet]
+
+		membership := models.WalletMember{UserID: userID}
 
 		err = rows.Scan(
-			&wallet.WalletID,
-			&wallet.UserID,
-			&wallet.WalletName,
-			&wallet.Balance,
-			&wallet.Currency,
-			&wallet.CreatedAt,
-			&wallet.UpdatedAt,
-			&wallet.Active,
+			&row.item.WalletID,
+			&row.item.UserID,
+			&row.item.WalletName,
+			&row.item.Balance,
+			&row.item.Currency,
+			&row.item.CreatedAt,
+			&row.item.UpdatedAt,
+			&row.item.Active,
+			&row.item.FrozenAt,
+			&membership.Role,
+			&membership.SpendLimit,
+			&row.sortKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("error when scanning wallet: %w", err)
+			return models.WalletsPage{}, fmt.Errorf("error when scanning wallet: %w", err)
 		}
 
-		walletsAll = append(walletsAll, wallet)
+		membership.WalletID = row.item.WalletID
+		row.item.Membership = &membership
+		row.id = uuid.UUID(row.item.WalletID)
+		walletsAll = append(walletsAll, row)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows.Err(): %w", err)
+		return models.WalletsPage{}, fmt.Errorf("rows.Err(): %w", err)
 	}
 
-	if len(walletsAll) == 0 {
-		return []models.Wallet{}, nil
-	}
-
-	return walletsAll, nil
+	return newPage(walletsAll, request, request.Sorting), nil
 }
 
-func (d *DataStore) GetWalletsQuery(request models.GetWalletsRequest, userID models.UserID) (string, []any) {
+func (d *DataStore) GetWalletsQuery(request models.GetWalletsRequest, sort sortColumn, userID models.UserID) (string, []any) {
 	var (
-		sb              strings.Builder
-		args            []any
-		validSortParams = map[string]string{
-			"wallet_name": "wallet_name",
-			"currency":    "currency",
-		}
+		sb   strings.Builder
+		args []any
 	)
 
-	sb.WriteString(`SELECT wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, active
-					FROM wallets
-					WHERE deleted_at IS NULL
-						AND active = true`)
-
 	args = append(args, userID)
-	sb.WriteString(fmt.Sprintf(` AND user_id = $%d`, len(args)))
+	sb.WriteString(fmt.Sprintf(`SELECT wallet_id, user_id, wallet_name, balance, currency, created_at, updated_at, active, frozen_at,
+						member_role, member_spend_limit, %s::text
+					FROM (
+						SELECT w.*, m.role AS member_role, m.spend_limit::float8 AS member_spend_limit
+						FROM wallets w
+						JOIN wallet_members m ON m.wallet_id = w.wallet_id AND m.user_id = $%d
+					) wallets
+					WHERE deleted_at IS NULL
+						AND active = true`, sort.column, len(args)))
 
 	if request.Filter != "" {
 		args = append(args, "%"+request.Filter+"%")
-		sb.WriteString(fmt.Sprintf(` AND concat_ws('', wallet_id, wallet_name, currency, balance, created_at, updated_at) ILIKE $%d`, len(args)))
+		sb.WriteString(fmt.Sprintf(` AND wallet_name ILIKE $%d`, len(args)))
 	}
 
-	sorting, ok := validSortParams[request.Sorting]
-	if !ok {
-		sorting = "currency"
-	}
+	args = writeRangeFilters(&sb, args, "created_at", request.From, request.To, false)
+	args = writeRangeFilters(&sb, args, "balance", request.MinBalance, request.MaxBalance, true)
+	args = writeSetFilter(&sb, args, "currency", request.Currencies)
 
-	sb.WriteString(" ORDER BY " + sorting)
+	args = writeKeyset(&sb, args, request, sort, "wallet_id")
 
-	if request.Descending {
-		sb.WriteString(" DESC")
+	return sb.String(), args
+}
+
+// GetActiveWallets returns the active wallets the user is a member of, like the wallet listing.
+func (d *DataStore) GetActiveWallets(ctx context.Context, userID models.UserID) ([]models.Wallet, error) {
+	query := `
+SELECT w.wallet_id, w.user_id, w.wallet_name, w.balance, w.currency, w.created_at, w.updated_at, w.active
+FROM wallets w
+JOIN wallet_members m ON m.wallet_id = w.wallet_id AND m.user_id = $1
+WHERE TRUE
+	AND w.deleted_at IS NULL
+	AND w.active = true
+ORDER BY w.created_at, w.wallet_id`
+
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting active wallets: %w", err)
 	}
 
-	args = append(args, request.Limit)
+	defer rows.Close()
 
-	sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	wallets := make([]models.Wallet, 0)
 
-	if request.Offset > 0 {
-		args = append(args, request.Offset)
-		sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	for rows.Next() {
+		var wallet models.Wallet
+
+		if err = rows.Scan(
+			&wallet.WalletID,
+			&wallet.UserID,
+			&wallet.WalletName,
+			&wallet.Balance,
+			&wallet.Currency,
+			&wallet.CreatedAt,
+			&wallet.UpdatedAt,
+			&wallet.Active,
+		); err != nil {
+			return nil, fmt.Errorf("error when scanning wallet: %w", err)
+		}
+
+		wallets = append(wallets, wallet)
 	}
 
-	return sb.String(), args
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return wallets, nil
 }
@@ -0,0 +1,412 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const (
+	webhookColumns  = `webhook_id, user_id, url, event_types, created_at`
+	deliveryColumns = `d.delivery_id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.last_error, d.created_at, d.updated_at`
+)
+
+func (d *DataStore) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	encrypted, err := d.pii.Encrypt(webhook.Secret)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	query := `
+INSERT INTO webhooks (webhook_id, user_id, url, event_types, encrypted_secret)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING ` + webhookColumns
+
+	created, err := scanWebhook(d.pool.QueryRow(ctx, query,
+		webhook.WebhookID, webhook.UserID, webhook.URL, webhook.EventTypes, encrypted))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return models.Webhook{}, models.ErrUserNotFound
+		}
+
+		return models.Webhook{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return created, nil
+}
+
+func (d *DataStore) GetWebhooks(ctx context.Context, userID models.UserID) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY created_at, webhook_id`
+
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhooks: %w", err)
+	}
+
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning webhooks: %w", err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (d *DataStore) DeleteWebhook(ctx context.Context, webhookID models.WebhookID, userID models.UserID) error {
+	result, err := d.pool.Exec(ctx, `DELETE FROM webhooks WHERE webhook_id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueWebhookEvent creates a pending delivery of the event for every webhook subscribed to its
+// type of the acting user and of the members of the affected wallets. Called within a transaction,
+// the deliveries are committed together with the change that caused the event.
+func (d *DataStore) EnqueueWebhookEvent(ctx context.Context, event models.WebhookEvent) error {
+	tx := d.getTXFromCtx(ctx)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	walletIDs := make([]uuid.UUID, 0, len(event.WalletIDs))
+	for _, id := range event.WalletIDs {
+		walletIDs = append(walletIDs, uuid.UUID(id))
+	}
+
+	query := `
+INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+SELECT webhook_id, $2, $3
+FROM webhooks
+WHERE $2 = ANY(event_types)
+	AND (user_id = $1 OR user_id IN (SELECT user_id FROM wallet_members WHERE wallet_id = ANY($4)))`
+
+	if _, err = tx.Exec(ctx, query, event.UserID, event.Type, payload, walletIDs); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries returns up to limit due deliveries with the decrypted secrets of their
+// webhooks and postpones them by the lease, so that other workers skip them while they are being sent.
+//
+//nolint:lll
+func (d *DataStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDispatch, error) {
+	query := `
+WITH due AS (
+	SELECT delivery_id
+	FROM webhook_deliveries
+	WHERE status = 'pending' AND next_attempt_at <= NOW()
+	ORDER BY next_attempt_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET next_attempt_at = NOW() + make_interval(secs => $2)
+FROM due, webhooks w
+WHERE d.delivery_id = due.delivery_id AND w.webhook_id = d.webhook_id
+RETURNING ` + deliveryColumns + `, w.url, w.encrypted_secret`
+
+	rows, err := d.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	defer rows.Close()
+
+	dispatches := make([]models.WebhookDispatch, 0)
+
+	for rows.Next() {
+		var (
+			dispatch  models.WebhookDispatch
+			encrypted []byte
+		)
+
+		if err = rows.Scan(
+			&dispatch.DeliveryID,
+			&dispatch.WebhookID,
+			&dispatch.EventType,
+			&dispatch.Payload,
+			&dispatch.Status,
+			&dispatch.Attempts,
+			&dispatch.NextAttemptAt,
+			&dispatch.LastError,
+			&dispatch.CreatedAt,
+			&dispatch.UpdatedAt,
+			&dispatch.URL,
+			&encrypted,
+		); err != nil {
+			return nil, fmt.Errorf("error when scanning webhook deliveries: %w", err)
+		}
+
+		if dispatch.Secret, err = d.pii.Decrypt(encrypted); err != nil {
+			return nil, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+		}
+
+		dispatches = append(dispatches, dispatch)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return dispatches, nil
+}
+
+// RecordDeliveryAttempt appends the attempt to the delivery history and moves the delivery
+// to the given status. nextAttemptAt is only kept for pending deliveries.
+//
+//nolint:lll
+func (d *DataStore) RecordDeliveryAttempt(ctx context.Context, deliveryID models.DeliveryID, attempt models.DeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	if err := d.DoWithTx(ctx, func(ctx context.Context) error {
+		tx := d.getTXFromCtx(ctx)
+
+		if _, err := tx.Exec(ctx, `
+INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+			deliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMs, attempt.AttemptedAt); err != nil {
+			return fmt.Errorf("failed to insert delivery attempt: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, updated_at = NOW()
+WHERE delivery_id = $1`,
+			deliveryID, status, attempt.Attempt, nextAttemptAt, attempt.Error); err != nil {
+			return fmt.Errorf("failed to update delivery: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error recording delivery attempt: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:lll
+func (d *DataStore) GetWebhookDeliveries(ctx context.Context, webhookID models.WebhookID, userID models.UserID, status string) ([]models.WebhookDelivery, error) {
+	if err := d.checkWebhookOwner(ctx, webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries d
+WHERE d.webhook_id = $1 AND ($2 = '' OR d.status = $2)
+ORDER BY d.created_at DESC, d.delivery_id`
+
+	rows, err := d.pool.Query(ctx, query, webhookID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhook deliveries: %w", err)
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error when scanning webhook deliveries: %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns the delivery with its attempt history.
+//
+//nolint:lll
+func (d *DataStore) GetWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error) {
+	query := `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries d
+JOIN webhooks w ON w.webhook_id = d.webhook_id
+WHERE d.delivery_id = $1 AND d.webhook_id = $2 AND w.user_id = $3`
+
+	delivery, err := scanWebhookDelivery(d.pool.QueryRow(ctx, query, deliveryID, webhookID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDelivery{}, models.ErrDeliveryNotFound
+		}
+
+		return models.WebhookDelivery{}, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	rows, err := d.pool.Query(ctx, `
+SELECT attempt, status_code, error, duration_ms, attempted_at
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY attempt_id`, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("error getting delivery attempts: %w", err)
+	}
+
+	delivery.History, err = pgx.CollectRows(rows, pgx.RowToStructByPos[models.DeliveryAttempt])
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("error when scanning delivery attempts: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// RedeliverWebhookDelivery schedules the delivery to be sent again right away with a fresh
+// attempt budget. Its history is kept.
+//
+//nolint:lll
+func (d *DataStore) RedeliverWebhookDelivery(ctx context.Context, webhookID models.WebhookID, deliveryID models.DeliveryID, userID models.UserID) (models.WebhookDelivery, error) {
+	query := `
+UPDATE webhook_deliveries d
+SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+FROM webhooks w
+WHERE w.webhook_id = d.webhook_id AND d.delivery_id = $1 AND d.webhook_id = $2 AND w.user_id = $3
+RETURNING ` + deliveryColumns
+
+	delivery, err := scanWebhookDelivery(d.pool.QueryRow(ctx, query, deliveryID, webhookID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDelivery{}, models.ErrDeliveryNotFound
+		}
+
+		return models.WebhookDelivery{}, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (d *DataStore) checkWebhookOwner(ctx context.Context, webhookID models.WebhookID, userID models.UserID) error {
+	var exists bool
+
+	if err := d.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM webhooks WHERE webhook_id = $1 AND user_id = $2)`,
+		webhookID, userID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check webhook: %w", err)
+	}
+
+	if !exists {
+		return models.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func scanWebhook(row pgx.Row) (models.Webhook, error) {
+	var webhook models.Webhook
+
+	if err := row.Scan(
+		&webhook.WebhookID,
+		&webhook.UserID,
+		&webhook.URL,
+		&webhook.EventTypes,
+		&webhook.CreatedAt,
+	); err != nil {
+		return models.Webhook{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	if err := row.Scan(
+		&delivery.DeliveryID,
+		&delivery.WebhookID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	); err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("scan error: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// encryptPlaintextWebhookSecrets encrypts the secrets of webhooks created before they were
+// stored encrypted.
+func (d *DataStore) encryptPlaintextWebhookSecrets(ctx context.Context) error {
+	rows, err := d.pool.Query(ctx, `
+SELECT webhook_id, plaintext_secret FROM webhooks WHERE plaintext_secret IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to get plaintext webhook secrets: %w", err)
+	}
+
+	defer rows.Close()
+
+	plaintext := make(map[models.WebhookID]string)
+
+	for rows.Next() {
+		var (
+			webhookID models.WebhookID
+			secret    string
+		)
+
+		if err := rows.Scan(&webhookID, &secret); err != nil {
+			return fmt.Errorf("error when scanning plaintext webhook secrets: %w", err)
+		}
+
+		plaintext[webhookID] = secret
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	rows.Close()
+
+	for webhookID, secret := range plaintext {
+		encrypted, err := d.pii.Encrypt(secret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt webhook secret: %w", err)
+		}
+
+		if _, err := d.pool.Exec(ctx, `
+UPDATE webhooks SET encrypted_secret = $2, plaintext_secret = NULL
+WHERE webhook_id = $1`, webhookID, encrypted); err != nil {
+			return fmt.Errorf("failed to store encrypted webhook secret: %w", err)
+		}
+	}
+
+	return nil
+}
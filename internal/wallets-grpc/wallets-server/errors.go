@@ -0,0 +1,61 @@
+package walletsgrpcserver
+
+import (
+	"errors"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//nolint:gochecknoglobals
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{models.ErrWalletNotFound, codes.NotFound},
+	{models.ErrUserNotFound, codes.NotFound},
+	{models.ErrMemberNotFound, codes.NotFound},
+	{models.ErrWalletEmptyName, codes.InvalidArgument},
+	{models.ErrWrongCurrency, codes.InvalidArgument},
+	{models.ErrZeroAmount, codes.InvalidArgument},
+	{models.ErrNegativeAmount, codes.InvalidArgument},
+	{models.ErrSameWallet, codes.InvalidArgument},
+	{models.ErrInvalidTransaction, codes.InvalidArgument},
+	{models.ErrInvalidUUIDFormat, codes.InvalidArgument},
+	{models.ErrInvalidFilter, codes.InvalidArgument},
+	{models.ErrInvalidCursor, codes.InvalidArgument},
+	{models.ErrInvalidDescription, codes.InvalidArgument},
+	{models.ErrInvalidExternalReference, codes.InvalidArgument},
+	{models.ErrInvalidMetadata, codes.InvalidArgument},
+	{models.ErrDuplicateExternalReference, codes.AlreadyExists},
+	{models.ErrInsufficientRights, codes.PermissionDenied},
+	{models.ErrSpendLimitExceeded, codes.PermissionDenied},
+	{models.ErrTransactionBlocked, codes.PermissionDenied},
+	{models.ErrUserDeleted, codes.PermissionDenied},
+	{models.ErrWrongUserID, codes.PermissionDenied},
+	{models.ErrInsufficientFunds, codes.FailedPrecondition},
+	{models.ErrTransactionHeld, codes.FailedPrecondition},
+	{models.ErrWalletFrozen, codes.FailedPrecondition},
+	{models.ErrNonZeroBalanceWallet, codes.FailedPrecondition},
+	{models.ErrInvalidToken, codes.Unauthenticated},
+}
+
+// toStatus maps the models sentinel in the error chain to a status carrying the sentinel message.
+// Unknown errors are logged and reported as internal without details.
+func toStatus(err error) error {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return status.Error(e.code, e.err.Error())
+		}
+	}
+
+	log.Error().Err(err).Msg("grpc request failed")
+
+	return status.Error(codes.Internal, codes.Internal.String())
+}
+
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
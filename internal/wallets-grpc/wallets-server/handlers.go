@@ -0,0 +1,454 @@
+package walletsgrpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultLimit    = 25
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+func (s *Server) CreateWallet(ctx context.Context, req *walletsv1.CreateWalletRequest) (*walletsv1.Wallet, error) {
+	userInfo := s.getUserInfo(ctx)
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     userInfo.UserID,
+		WalletName: req.GetWalletName(),
+		Currency:   req.GetCurrency(),
+	}
+
+	if err := wallet.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	created, err := s.service.CreateWallet(ctx, wallet, userInfo.UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toWallet(created), nil
+}
+
+func (s *Server) GetWallet(ctx context.Context, req *walletsv1.GetWalletRequest) (*walletsv1.Wallet, error) {
+	walletID, err := parseWalletID(req.GetWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.service.GetWallet(ctx, walletID, s.getUserInfo(ctx).UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toWallet(wallet), nil
+}
+
+func (s *Server) UpdateWallet(ctx context.Context, req *walletsv1.UpdateWalletRequest) (*walletsv1.Wallet, error) {
+	walletID, err := parseWalletID(req.GetWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := s.getUserInfo(ctx)
+
+	wallet, err := s.service.UpdateWallet(ctx, walletID, models.WalletUpdate{
+		WalletName: req.GetWalletName(),
+		UserID:     userInfo.UserID,
+		Currency:   req.GetCurrency(),
+	}, userInfo.UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toWallet(wallet), nil
+}
+
+func (s *Server) DeleteWallet(ctx context.Context, req *walletsv1.DeleteWalletRequest) (*walletsv1.DeleteWalletResponse, error) {
+	walletID, err := parseWalletID(req.GetWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.service.DeleteWallet(ctx, walletID, s.getUserInfo(ctx).UserID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &walletsv1.DeleteWalletResponse{}, nil
+}
+
+func (s *Server) ListWallets(ctx context.Context, req *walletsv1.ListWalletsRequest) (*walletsv1.ListWalletsResponse, error) {
+	request := models.GetWalletsRequest{
+		Sorting:    req.GetSorting(),
+		Descending: req.GetDescending(),
+		Limit:      int(req.GetLimit()),
+		Filter:     req.GetFilter(),
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = defaultLimit
+	}
+
+	if req.GetCursor() != "" {
+		cursor, err := models.DecodeCursor(req.GetCursor())
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		request.Cursor = cursor
+	}
+
+	page, err := s.service.GetAllWallets(ctx, request, s.getUserInfo(ctx).UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &walletsv1.ListWalletsResponse{
+		Wallets:    make([]*walletsv1.Wallet, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+
+	for _, wallet := range page.Items {
+		response.Wallets = append(response.Wallets, toWallet(wallet))
+	}
+
+	return response, nil
+}
+
+func (s *Server) Deposit(ctx context.Context, req *walletsv1.DepositRequest) (*walletsv1.DepositResponse, error) {
+	toWalletID, err := parseWalletID(req.GetToWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := models.Transaction{
+		Type:              "deposit",
+		ToWalletID:        &toWalletID,
+		Amount:            req.GetAmount(),
+		Currency:          req.GetCurrency(),
+		Description:       req.GetDescription(),
+		ExternalReference: optional(req.GetExternalReference()),
+		Metadata:          req.GetMetadata(),
+	}
+
+	if err = s.moveFunds(ctx, transaction, s.service.Deposit); err != nil {
+		return nil, err
+	}
+
+	return &walletsv1.DepositResponse{}, nil
+}
+
+func (s *Server) Withdraw(ctx context.Context, req *walletsv1.WithdrawRequest) (*walletsv1.WithdrawResponse, error) {
+	fromWalletID, err := parseWalletID(req.GetFromWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := models.Transaction{
+		Type:              "withdraw",
+		FromWalletID:      &fromWalletID,
+		Amount:            req.GetAmount(),
+		Currency:          req.GetCurrency(),
+		Description:       req.GetDescription(),
+		ExternalReference: optional(req.GetExternalReference()),
+		Metadata:          req.GetMetadata(),
+	}
+
+	if err = s.moveFunds(ctx, transaction, s.service.Withdraw); err != nil {
+		return nil, err
+	}
+
+	return &walletsv1.WithdrawResponse{}, nil
+}
+
+func (s *Server) Transfer(ctx context.Context, req *walletsv1.TransferRequest) (*walletsv1.TransferResponse, error) {
+	fromWalletID, err := parseWalletID(req.GetFromWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	toWalletID, err := parseWalletID(req.GetToWalletId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromWalletID == toWalletID {
+		return nil, toStatus(models.ErrSameWallet)
+	}
+
+	transaction := models.Transaction{
+		Type:              "transfer",
+		FromWalletID:      &fromWalletID,
+		ToWalletID:        &toWalletID,
+		Amount:            req.GetAmount(),
+		Currency:          req.GetCurrency(),
+		Description:       req.GetDescription(),
+		ExternalReference: optional(req.GetExternalReference()),
+		Metadata:          req.GetMetadata(),
+	}
+
+	if err = s.moveFunds(ctx, transaction, s.service.Transfer); err != nil {
+		return nil, err
+	}
+
+	return &walletsv1.TransferResponse{}, nil
+}
+
+//nolint:lll
+func (s *Server) moveFunds(ctx context.Context, transaction models.Transaction, fn func(ctx context.Context, transaction models.Transaction, userID models.UserID) error) error {
+	if err := transaction.Validate(); err != nil {
+		return toStatus(err)
+	}
+
+	if err := fn(ctx, transaction, s.getUserInfo(ctx).UserID); err != nil {
+		return toStatus(err)
+	}
+
+	return nil
+}
+
+// ListTransactions walks the keyset pages of the wallet history and streams every transaction,
+// so that large histories are never held in memory at once.
+func (s *Server) ListTransactions(req *walletsv1.ListTransactionsRequest, stream grpc.ServerStreamingServer[walletsv1.Transaction]) error {
+	walletID, err := parseWalletID(req.GetWalletId())
+	if err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	userID := s.getUserInfo(ctx).UserID
+
+	request := models.GetWalletsRequest{
+		Sorting:    req.GetSorting(),
+		Descending: req.GetDescending(),
+		Limit:      int(req.GetPageSize()),
+		Filter:     req.GetFilter(),
+		Types:      req.GetTypes(),
+	}
+
+	switch {
+	case request.Limit <= 0:
+		request.Limit = defaultPageSize
+	case request.Limit > maxPageSize:
+		request.Limit = maxPageSize
+	}
+
+	if req.GetFrom() != nil {
+		from := req.GetFrom().AsTime()
+		request.From = &from
+	}
+
+	if req.GetTo() != nil {
+		to := req.GetTo().AsTime()
+		request.To = &to
+	}
+
+	for {
+		page, err := s.service.GetTransactions(ctx, request, walletID, userID)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for _, transaction := range page.Items {
+			if err = stream.Send(toTransaction(transaction)); err != nil {
+				return err //nolint:wrapcheck
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+
+		if request.Cursor, err = models.DecodeCursor(page.NextCursor); err != nil {
+			return toStatus(err)
+		}
+	}
+}
+
+type balancePayload struct {
+	WalletID  string    `json:"walletId"`
+	Balance   float64   `json:"balance"`
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// WatchBalances streams the balance events of the caller's wallets, replaying the events after
+// last_event_id first. The stream ends with Unavailable when the client falls too far behind;
+// the client then resumes with the last event ID it received.
+//
+//nolint:cyclop
+func (s *Server) WatchBalances(req *walletsv1.WatchBalancesRequest, stream grpc.ServerStreamingServer[walletsv1.BalanceUpdate]) error {
+	walletIDs := make(map[string]struct{}, len(req.GetWalletIds()))
+
+	for _, id := range req.GetWalletIds() {
+		walletID, err := uuid.Parse(id)
+		if err != nil {
+			return invalidArgument("invalid wallet id")
+		}
+
+		walletIDs[walletID.String()] = struct{}{}
+	}
+
+	if req.GetLastEventId() < 0 {
+		return invalidArgument("invalid last event id")
+	}
+
+	ctx := stream.Context()
+
+	send := func(notification models.Notification) error {
+		if notification.Type != models.NotificationBalanceUpdated {
+			return nil
+		}
+
+		update, ok := toBalanceUpdate(notification)
+		if !ok {
+			return nil
+		}
+
+		if _, ok = walletIDs[update.GetWalletId()]; !ok && len(walletIDs) > 0 {
+			return nil
+		}
+
+		return stream.Send(update)
+	}
+
+	var sendErr error
+
+	live, unsubscribe, err := s.service.StreamNotifications(ctx, s.getUserInfo(ctx).UserID, req.GetLastEventId(), func(notification models.Notification) error {
+		sendErr = send(notification)
+
+		return sendErr
+	})
+	if err != nil {
+		if sendErr != nil {
+			return sendErr
+		}
+
+		return toStatus(err)
+	}
+
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification, ok := <-live:
+			if !ok {
+				return status.Error(codes.Unavailable, "stream fell behind, resume from the last event id")
+			}
+
+			if err = send(notification); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toBalanceUpdate(notification models.Notification) (*walletsv1.BalanceUpdate, bool) {
+	data, err := json.Marshal(notification.Payload)
+	if err != nil {
+		return nil, false
+	}
+
+	var payload balancePayload
+
+	if err = json.Unmarshal(data, &payload); err != nil {
+		log.Warn().Err(err).Int64("eventId", notification.ID).Msg("failed to decode balance event")
+
+		return nil, false
+	}
+
+	walletID, err := uuid.Parse(payload.WalletID)
+	if err != nil {
+		return nil, false
+	}
+
+	return &walletsv1.BalanceUpdate{
+		EventId:   notification.ID,
+		WalletId:  walletID.String(),
+		Balance:   payload.Balance,
+		Currency:  payload.Currency,
+		UpdatedAt: timestamppb.New(payload.UpdatedAt),
+	}, true
+}
+
+func toWallet(wallet models.Wallet) *walletsv1.Wallet {
+	result := &walletsv1.Wallet{
+		WalletId:   uuid.UUID(wallet.WalletID).String(),
+		UserId:     uuid.UUID(wallet.UserID).String(),
+		WalletName: wallet.WalletName,
+		Balance:    wallet.Balance,
+		Currency:   wallet.Currency,
+		CreatedAt:  timestamppb.New(wallet.CreatedAt),
+		UpdatedAt:  timestamppb.New(wallet.UpdatedAt),
+		Active:     wallet.Active,
+	}
+
+	if wallet.Membership != nil {
+		result.Role = wallet.Membership.Role
+	}
+
+	return result
+}
+
+func toTransaction(transaction models.Transaction) *walletsv1.Transaction {
+	result := &walletsv1.Transaction{
+		TransactionId: uuid.UUID(transaction.ID).String(),
+		Type:          transaction.Type,
+		Amount:        transaction.Amount,
+		Currency:      transaction.Currency,
+		CommittedAt:   timestamppb.New(transaction.CommittedAt),
+		Description:   transaction.Description,
+		Metadata:      transaction.Metadata,
+	}
+
+	if transaction.ToWalletID != nil {
+		result.ToWalletId = uuid.UUID(*transaction.ToWalletID).String()
+	}
+
+	if transaction.FromWalletID != nil {
+		result.FromWalletId = uuid.UUID(*transaction.FromWalletID).String()
+	}
+
+	if transaction.CategoryID != nil {
+		result.CategoryId = uuid.UUID(*transaction.CategoryID).String()
+	}
+
+	if transaction.ExternalReference != nil {
+		result.ExternalReference = *transaction.ExternalReference
+	}
+
+	return result
+}
+
+func parseWalletID(value string) (models.WalletID, error) {
+	walletID, err := uuid.Parse(value)
+	if err != nil || walletID == uuid.Nil {
+		return models.WalletID{}, invalidArgument("invalid wallet id")
+	}
+
+	return models.WalletID(walletID), nil
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,138 @@
+package walletsgrpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationKey = "authorization"
+
+// methodScopes lists the scope a token needs for each method, like the REST routes declare theirs.
+// Methods missing from it are rejected, and the reflection service needs a valid token only.
+//
+//nolint:gochecknoglobals
+var methodScopes = map[string]string{
+	walletsv1.WalletService_CreateWallet_FullMethodName:     models.ScopeWalletsWrite,
+	walletsv1.WalletService_GetWallet_FullMethodName:        models.ScopeWalletsRead,
+	walletsv1.WalletService_UpdateWallet_FullMethodName:     models.ScopeWalletsWrite,
+	walletsv1.WalletService_DeleteWallet_FullMethodName:     models.ScopeWalletsWrite,
+	walletsv1.WalletService_ListWallets_FullMethodName:      models.ScopeWalletsRead,
+	walletsv1.WalletService_Deposit_FullMethodName:          models.ScopeTransactionsWrite,
+	walletsv1.WalletService_Withdraw_FullMethodName:         models.ScopeTransactionsWrite,
+	walletsv1.WalletService_Transfer_FullMethodName:         models.ScopeTransactionsWrite,
+	walletsv1.WalletService_ListTransactions_FullMethodName: models.ScopeTransactionsRead,
+	walletsv1.WalletService_WatchBalances_FullMethodName:    models.ScopeWalletsRead,
+
+	reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName:      "",
+	reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName: "",
+}
+
+// authenticatedStream carries the context holding the caller's user info into stream handlers.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context //nolint:containedctx
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) authUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) authStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate validates the bearer token of the authorization metadata the same way the REST
+// server validates the Authorization header, checks the scope the method needs and stores the
+// caller's user info in the context.
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	unauthenticated := status.Error(codes.Unauthenticated, models.ErrInvalidToken.Error())
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return nil, unauthenticated
+	}
+
+	encodedToken, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, unauthenticated
+	}
+
+	claims, err := s.verifier.Verify(ctx, encodedToken)
+	if err != nil {
+		log.Debug().Err(err).Msg("token rejected")
+
+		return nil, unauthenticated
+	}
+
+	userInfo := models.UserInfo{
+		UserID: claims.UserID,
+		Email:  claims.Email,
+		Role:   claims.Role,
+		Scopes: claims.Scopes,
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "no scope declared for method %s", method)
+	}
+
+	if scope != "" && !userInfo.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s: %s", models.ErrMissingScope, scope)
+	}
+
+	return models.ContextWithUserInfo(ctx, userInfo), nil
+}
+
+func (s *Server) recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("method", info.FullMethod).Msg("grpc handler panicked")
+
+			err = status.Error(codes.Internal, codes.Internal.String())
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func (s *Server) recoverStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("method", info.FullMethod).Msg("grpc handler panicked")
+
+			err = status.Error(codes.Internal, codes.Internal.String())
+		}
+	}()
+
+	return handler(srv, stream)
+}
+
+func (s *Server) getUserInfo(ctx context.Context) models.UserInfo {
+	return models.UserInfoFromContext(ctx)
+}
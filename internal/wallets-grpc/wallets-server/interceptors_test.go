@@ -0,0 +1,113 @@
+//nolint:testpackage
+package walletsgrpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/ratelimit"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type staticVerifier struct {
+	claims *models.Claims
+}
+
+func (v staticVerifier) Verify(context.Context, string) (*models.Claims, error) {
+	return v.claims, nil
+}
+
+// TestMethodScopesDeclared fails when a method is added to the service without declaring its scope.
+func TestMethodScopesDeclared(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range walletsv1.WalletService_ServiceDesc.Methods {
+		require.Contains(t, methodScopes, "/"+walletsv1.WalletService_ServiceDesc.ServiceName+"/"+method.MethodName)
+	}
+
+	for _, stream := range walletsv1.WalletService_ServiceDesc.Streams {
+		require.Contains(t, methodScopes, "/"+walletsv1.WalletService_ServiceDesc.ServiceName+"/"+stream.StreamName)
+	}
+}
+
+func TestAuthenticateScopes(t *testing.T) {
+	t.Parallel()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationKey, "Bearer token"))
+
+	tests := []struct {
+		name         string
+		method       string
+		scopes       []string
+		expectedCode codes.Code
+	}{
+		{
+			name:   "scope held",
+			method: walletsv1.WalletService_Deposit_FullMethodName,
+			scopes: []string{models.ScopeTransactionsWrite},
+		},
+		{
+			name:         "scope missing",
+			method:       walletsv1.WalletService_Deposit_FullMethodName,
+			scopes:       []string{models.ScopeWalletsRead},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "unknown method",
+			method:       "/wallets.v1.WalletService/Unknown",
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Server{verifier: staticVerifier{claims: &models.Claims{UserID: models.UserID(uuid.New()), Scopes: tt.scopes}}}
+
+			_, err := s.authenticate(ctx, tt.method)
+
+			if tt.expectedCode != codes.OK {
+				require.Equal(t, tt.expectedCode, status.Code(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+type budgetLimiter struct {
+	allowed map[string]bool
+}
+
+func (l budgetLimiter) Allow(_ context.Context, _ models.UserID, budget string) (models.RateLimitResult, error) {
+	return models.RateLimitResult{Allowed: l.allowed[budget], Limit: 1, RetryAfter: time.Second}, nil
+}
+
+func TestAllow(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{limiter: budgetLimiter{allowed: map[string]bool{models.RateLimitDefault: true}}}
+	ctx := models.ContextWithUserInfo(context.Background(), models.UserInfo{UserID: models.UserID(uuid.New())})
+
+	_, err := s.allow(ctx, walletsv1.WalletService_GetWallet_FullMethodName)
+	require.NoError(t, err)
+
+	rejected := ratelimit.Rejected().WithLabelValues(models.RateLimitMoney)
+	before := testutil.ToFloat64(rejected)
+
+	header, err := s.allow(ctx, walletsv1.WalletService_Transfer_FullMethodName)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, []string{"1"}, header.Get("retry-after"))
+	require.InDelta(t, before+1, testutil.ToFloat64(rejected), 1e-9, "rejections are counted with the REST ones")
+}
@@ -0,0 +1,105 @@
+package walletsgrpcserver
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/ratelimit"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type rateLimiter interface {
+	Allow(ctx context.Context, userID models.UserID, budget string) (models.RateLimitResult, error)
+}
+
+// methodBudgets lists the methods drawing from another rate limit budget than the default one,
+// like the REST routes moving money.
+//
+//nolint:gochecknoglobals
+var methodBudgets = map[string]string{
+	walletsv1.WalletService_Deposit_FullMethodName:  models.RateLimitMoney,
+	walletsv1.WalletService_Withdraw_FullMethodName: models.RateLimitMoney,
+	walletsv1.WalletService_Transfer_FullMethodName: models.RateLimitMoney,
+}
+
+func (s *Server) rateLimitUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	header, err := s.allow(ctx, info.FullMethod)
+
+	if len(header) > 0 {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			log.Warn().Err(err).Msg("failed to set rate limit headers")
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) rateLimitStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	header, err := s.allow(stream.Context(), info.FullMethod)
+
+	if len(header) > 0 {
+		if err := stream.SetHeader(header); err != nil {
+			log.Warn().Err(err).Msg("failed to set rate limit headers")
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// allow takes a token from the caller's bucket for the budget of the method and returns the
+// rate limit headers, the same the REST server sets, together with a resource exhausted status
+// when the bucket is empty, counted with the REST rejections. Calls are let through when the
+// limiter fails.
+func (s *Server) allow(ctx context.Context, method string) (metadata.MD, error) {
+	if s.limiter == nil {
+		return nil, nil
+	}
+
+	budget, ok := methodBudgets[method]
+	if !ok {
+		budget = models.RateLimitDefault
+	}
+
+	result, err := s.limiter.Allow(ctx, s.getUserInfo(ctx).UserID, budget)
+	if err != nil {
+		log.Warn().Err(err).Str("budget", budget).Msg("rate limiter failed")
+	}
+
+	header := metadata.MD{}
+
+	if result.Limit > 0 {
+		header.Set("ratelimit-limit", strconv.Itoa(result.Limit))
+		header.Set("ratelimit-remaining", strconv.Itoa(result.Remaining))
+		header.Set("ratelimit-reset", ceilSeconds(result.Reset))
+	}
+
+	if !result.Allowed {
+		ratelimit.Rejected().WithLabelValues(budget).Inc()
+
+		header.Set("retry-after", ceilSeconds(result.RetryAfter))
+
+		return header, status.Error(codes.ResourceExhausted, models.ErrRateLimited.Error())
+	}
+
+	return header, nil
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,89 @@
+package walletsgrpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Config struct {
+	Port int
+}
+
+type service interface {
+	CreateWallet(ctx context.Context, wallet models.Wallet, userID models.UserID) (models.Wallet, error)
+	GetWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) (models.Wallet, error)
+	UpdateWallet(ctx context.Context, walletID models.WalletID, updatedWallet models.WalletUpdate, userID models.UserID) (models.Wallet, error)
+	DeleteWallet(ctx context.Context, walletID models.WalletID, userID models.UserID) error
+	GetAllWallets(ctx context.Context, request models.GetWalletsRequest, userID models.UserID) (models.WalletsPage, error)
+	Deposit(ctx context.Context, transaction models.Transaction, userID models.UserID) error
+	Withdraw(ctx context.Context, transaction models.Transaction, userID models.UserID) error
+	Transfer(ctx context.Context, transaction models.Transaction, userID models.UserID) error
+	GetTransactions(ctx context.Context, request models.GetWalletsRequest, walletID models.WalletID, userID models.UserID) (models.TransactionsPage, error)
+	StreamNotifications(ctx context.Context, userID models.UserID, lastEventID int64, replay func(models.Notification) error) (<-chan models.Notification, func(), error)
+}
+
+type tokenVerifier interface {
+	Verify(ctx context.Context, token string) (*models.Claims, error)
+}
+
+// Server serves the wallets API over gRPC next to the REST server, sharing its service, token verifier
+// and rate limiter.
+type Server struct {
+	grpcServer *grpc.Server
+	service    service
+	port       int
+	verifier   tokenVerifier
+	limiter    rateLimiter
+	walletsv1.UnimplementedWalletServiceServer
+}
+
+func New(cfg Config, service service, verifier tokenVerifier, limiter rateLimiter) *Server {
+	s := &Server{
+		service:  service,
+		port:     cfg.Port,
+		verifier: verifier,
+		limiter:  limiter,
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.recoverUnary, s.authUnary, s.rateLimitUnary),
+		grpc.ChainStreamInterceptor(s.recoverStream, s.authStream, s.rateLimitStream),
+	)
+
+	walletsv1.RegisterWalletServiceServer(s.grpcServer, s)
+	reflection.Register(s.grpcServer)
+
+	return s
+}
+
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", s.port, err)
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts connections on the listener until the context is done.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	log.Info().Msgf("Starting wallets grpc server on %s", listener.Addr())
+
+	go func() {
+		<-ctx.Done()
+		s.grpcServer.GracefulStop()
+	}()
+
+	if err := s.grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("failed to serve grpc: %w", err)
+	}
+
+	return nil
+}
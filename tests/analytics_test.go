@@ -0,0 +1,89 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const analyticsPath = `/api/v1/analytics`
+
+func (s *IntegrationTestSuite) TestAnalytics() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	rubWallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "analyticsRub",
+		Currency:   "RUB",
+	}
+
+	usdWallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "analyticsUsd",
+		Currency:   "USD",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &rubWallet, nil, existingUser)
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &usdWallet, nil, existingUser)
+
+	rubPath := walletPath + "/" + uuid.UUID(rubWallet.WalletID).String()
+	usdPath := walletPath + "/" + uuid.UUID(usdWallet.WalletID).String()
+
+	s.sendRequest(http.MethodPut, rubPath+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &rubWallet.WalletID,
+		Amount:     9000.0,
+		Currency:   "RUB",
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, rubPath+"/withdrawal", http.StatusOK, &models.Transaction{
+		FromWalletID: &rubWallet.WalletID,
+		Amount:       1000.0,
+		Currency:     "RUB",
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, usdPath+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &usdWallet.WalletID,
+		Amount:     10.0,
+		Currency:   "USD",
+	}, nil, existingUser)
+
+	err = s.db.RefreshRollups(context.Background(), 0)
+	s.Require().NoError(err)
+
+	s.Run("wallet analytics by day", func() {
+		var report models.AnalyticsReport
+
+		s.sendRequest(http.MethodGet, analyticsPath+"/wallets/"+uuid.UUID(rubWallet.WalletID).String(),
+			http.StatusOK, nil, &report, existingUser)
+
+		s.Require().Equal(models.GranularityDay, report.Granularity)
+		s.Require().Len(report.Buckets, 2)
+		s.Require().Equal("deposit", report.Buckets[0].Type)
+		s.Require().InDelta(9000.0, report.Buckets[0].Inflow, epsilon)
+		s.Require().Equal("withdraw", report.Buckets[1].Type)
+		s.Require().InDelta(-1000.0, report.Buckets[1].Net, epsilon)
+	})
+
+	s.Run("all wallets by month in reporting currency", func() {
+		var report models.AnalyticsReport
+
+		s.sendRequest(http.MethodGet, analyticsPath+"?granularity=month&currency=RUB", http.StatusOK, nil, &report, existingUser)
+
+		s.Require().Len(report.Buckets, 3)
+		s.Require().NotNil(report.Total)
+
+		expectedNet := 9000.0 - 1000.0 + 10.0*exchangeRatesToRub["USD"]
+		s.Require().True(math.Abs(report.Total.Net-expectedNet) < epsilon)
+	})
+
+	s.Run("invalid granularity", func() {
+		s.sendRequest(http.MethodGet, analyticsPath+"?granularity=year", http.StatusBadRequest, nil, nil, existingUser)
+	})
+}
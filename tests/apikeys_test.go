@@ -0,0 +1,151 @@
+//nolint:testpackage
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (s *IntegrationTestSuite) TestAPIKeys() {
+	s.Require().NoError(s.db.UpsertUser(context.Background(), existingUser))
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "payouts",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	depositPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String() + "/deposit"
+	deposit := models.Transaction{ToWalletID: &wallet.WalletID, Amount: 10.0, Currency: "RUB"}
+
+	var credentials models.APIKeyCredentials
+
+	s.Run("create key", func() {
+		s.sendRequest(http.MethodPost, "/api/v1/api-keys", http.StatusBadRequest, &models.APIKey{
+			Name:   "payouts",
+			Scopes: []string{"everything"},
+		}, nil, existingUser)
+
+		s.sendRequest(http.MethodPost, "/api/v1/api-keys", http.StatusCreated, &models.APIKey{
+			Name:   "payouts",
+			Scopes: []string{models.ScopeTransactionsWrite, models.ScopeWalletsRead},
+		}, &credentials, existingUser)
+		s.Require().NotEmpty(credentials.Secret)
+		s.Require().Equal(existingUser.UserID, credentials.UserID)
+	})
+
+	s.Run("signed requests act as the key owner", func() {
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusOK, &deposit, nil, credentials, time.Now())
+
+		var got models.Wallet
+
+		s.sendSignedRequest(http.MethodGet, walletPath+"/"+uuid.UUID(wallet.WalletID).String(), http.StatusOK,
+			nil, &got, credentials, time.Now())
+		s.Require().InDelta(10.0, got.Balance, epsilon)
+	})
+
+	s.Run("scopes are enforced", func() {
+		s.sendSignedRequest(http.MethodPost, walletPath, http.StatusForbidden, &models.Wallet{
+			WalletID:   models.WalletID(uuid.New()),
+			WalletName: "not allowed",
+			Currency:   "RUB",
+		}, nil, credentials, time.Now())
+
+		s.sendSignedRequest(http.MethodGet, "/api/v1/api-keys", http.StatusForbidden, nil, nil, credentials, time.Now())
+	})
+
+	s.Run("stale, replayed and forged requests are rejected", func() {
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusUnauthorized, &deposit, nil, credentials,
+			time.Now().Add(-time.Hour))
+
+		timestamp := time.Now()
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusOK, &deposit, nil, credentials, timestamp)
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusUnauthorized, &deposit, nil, credentials, timestamp)
+
+		forged := credentials
+		forged.Secret = "wsk_forged"
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusUnauthorized, &deposit, nil, forged, time.Now())
+	})
+
+	s.Run("rotation replaces the secret", func() {
+		var rotated models.APIKeyCredentials
+
+		s.sendRequest(http.MethodPost, "/api/v1/api-keys/"+uuid.UUID(credentials.KeyID).String()+"/rotate",
+			http.StatusOK, nil, &rotated, existingUser)
+		s.Require().NotEqual(credentials.Secret, rotated.Secret)
+		s.Require().NotNil(rotated.RotatedAt)
+
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusUnauthorized, &deposit, nil, credentials, time.Now())
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusOK, &deposit, nil, rotated, time.Now())
+
+		credentials = rotated
+	})
+
+	s.Run("revoked keys are rejected", func() {
+		s.sendRequest(http.MethodDelete, "/api/v1/api-keys/"+uuid.UUID(credentials.KeyID).String(),
+			http.StatusNoContent, nil, nil, existingUser)
+
+		s.sendSignedRequest(http.MethodPut, depositPath, http.StatusUnauthorized, &deposit, nil, credentials, time.Now())
+
+		var keys []models.APIKey
+
+		s.sendRequest(http.MethodGet, "/api/v1/api-keys", http.StatusOK, nil, &keys, existingUser)
+		s.Require().Len(keys, 1)
+		s.Require().NotNil(keys[0].RevokedAt)
+		s.Require().NotNil(keys[0].LastUsedAt)
+	})
+}
+
+//nolint:lll
+func (s *IntegrationTestSuite) sendSignedRequest(method, path string, status int, entity, result any, credentials models.APIKeyCredentials, timestamp time.Time) {
+	var body []byte
+
+	if entity != nil {
+		var err error
+
+		body, err = json.Marshal(entity)
+		s.Require().NoError(err)
+	}
+
+	signed := models.SignedRequest{
+		KeyID:     credentials.KeyID,
+		Timestamp: timestamp,
+		Method:    method,
+		URI:       path,
+		Body:      body,
+	}
+
+	request, err := http.NewRequestWithContext(context.Background(), method,
+		fmt.Sprintf("http://localhost:%d%s", port, path), bytes.NewReader(body))
+	s.Require().NoError(err, "fail to create request")
+
+	request.Header.Set("X-Api-Key-Id", uuid.UUID(credentials.KeyID).String())
+	request.Header.Set("X-Api-Timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set("X-Api-Signature", signed.Sign(models.APIKeySigningKey(credentials.Secret)))
+
+	response, err := http.DefaultClient.Do(request)
+	s.Require().NoError(err, "fail to execute request")
+
+	defer func() {
+		s.Require().NoError(response.Body.Close())
+	}()
+
+	s.Require().Equal(status, response.StatusCode, "unexpected status code")
+
+	if result == nil {
+		return
+	}
+
+	s.Require().NoError(json.NewDecoder(response.Body).Decode(result))
+}
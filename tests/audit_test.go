@@ -0,0 +1,64 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const auditPath = `/api/v1/audit`
+
+func (s *IntegrationTestSuite) TestAuditLog() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "auditWallet",
+		Currency:   "USD",
+	}
+
+	var createdWallet models.Wallet
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
+
+	uuidString := uuid.UUID(createdWallet.WalletID).String()
+
+	transaction := models.Transaction{
+		ToWalletID: &createdWallet.WalletID,
+		Amount:     100.0,
+		Currency:   "USD",
+	}
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+uuidString+"/deposit", http.StatusOK, &transaction, nil, existingUser)
+
+	auditor := s.newClaims(models.User{UserID: models.UserID(uuid.New())})
+	auditor.Role = models.RoleAuditor
+
+	s.Run("regular user cannot read audit log", func() {
+		s.sendRequest(http.MethodGet, auditPath, http.StatusForbidden, nil, nil, existingUser)
+	})
+
+	s.Run("auditor reads calls touching a wallet", func() {
+		var records []models.AuditRecord
+
+		s.sendRequestWithClaims(http.MethodGet, auditPath+"?walletId="+uuidString, http.StatusOK, nil, &records, auditor)
+
+		s.Require().Len(records, 2)
+		s.Require().Equal("/api/v1/wallets/{walletId}/deposit", records[0].Route)
+		s.Require().Equal(http.StatusOK, records[0].Status)
+		s.Require().Equal(existingUser.UserID, records[0].UserID)
+		s.Require().NotEmpty(records[0].RequestID)
+		s.Require().NotEmpty(records[0].RequestBody)
+		s.Require().Equal("/api/v1/wallets", records[1].Route)
+		s.Require().Equal(http.StatusCreated, records[1].Status)
+	})
+
+	s.Run("invalid filter", func() {
+		s.sendRequestWithClaims(http.MethodGet, auditPath+"?walletId=abc", http.StatusBadRequest, nil, nil, auditor)
+	})
+}
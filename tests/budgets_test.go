@@ -0,0 +1,141 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const budgetsPath = "/api/v1/budgets"
+
+func (s *IntegrationTestSuite) TestBudgets() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "budgeted",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	walletIDStr := uuid.UUID(wallet.WalletID).String()
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &wallet.WalletID,
+		Amount:     1000.0,
+		Currency:   "RUB",
+	}, nil, existingUser)
+
+	var budget models.Budget
+
+	s.sendRequest(http.MethodPost, budgetsPath, http.StatusCreated, &models.Budget{
+		Name:     "pocket money",
+		WalletID: &wallet.WalletID,
+		Period:   models.BudgetPeriodMonthly,
+		Amount:   100.0,
+		Currency: "rub",
+	}, &budget, existingUser)
+
+	s.Require().Equal(models.DefaultBudgetThresholds, budget.Thresholds)
+	s.Require().Equal("RUB", budget.Currency)
+
+	s.Run("progress follows spending", func() {
+		s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/withdrawal", http.StatusOK, &models.Transaction{
+			FromWalletID: &wallet.WalletID,
+			Amount:       85.0,
+			Currency:     "RUB",
+		}, nil, existingUser)
+
+		var progress models.BudgetProgress
+
+		s.sendRequest(http.MethodGet, budgetsPath+"/"+uuid.UUID(budget.BudgetID).String(), http.StatusOK, nil, &progress, existingUser)
+		s.Require().InDelta(85.0, progress.Spent, epsilon)
+		s.Require().InDelta(15.0, progress.Remaining, epsilon)
+		s.Require().Equal([]int{80}, progress.Crossed)
+		s.Require().True(progress.PeriodStart.Before(progress.PeriodEnd))
+
+		var all []models.BudgetProgress
+
+		s.sendRequest(http.MethodGet, budgetsPath, http.StatusOK, nil, &all, existingUser)
+		s.Require().Len(all, 1)
+	})
+
+	s.Run("alerts are recorded as events of the owner", func() {
+		events, err := s.db.GetWalletEvents(context.Background(), existingUser.UserID, 0, 100)
+		s.Require().NoError(err)
+
+		var thresholds []int
+
+		for _, event := range events {
+			if event.Type != models.NotificationBudgetAlert {
+				continue
+			}
+
+			var alert models.BudgetAlert
+
+			s.Require().NoError(json.Unmarshal(event.Payload, &alert))
+			s.Require().Equal(budget.BudgetID, alert.BudgetID)
+
+			thresholds = append(thresholds, alert.Threshold)
+		}
+
+		s.Require().Equal([]int{80}, thresholds)
+	})
+
+	s.Run("deposits do not count", func() {
+		s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/deposit", http.StatusOK, &models.Transaction{
+			ToWalletID: &wallet.WalletID,
+			Amount:     500.0,
+			Currency:   "RUB",
+		}, nil, existingUser)
+
+		var progress models.BudgetProgress
+
+		s.sendRequest(http.MethodGet, budgetsPath+"/"+uuid.UUID(budget.BudgetID).String(), http.StatusOK, nil, &progress, existingUser)
+		s.Require().InDelta(85.0, progress.Spent, epsilon)
+	})
+
+	s.Run("transfers between own wallets do not count", func() {
+		savings := models.Wallet{
+			WalletID:   models.WalletID(uuid.New()),
+			UserID:     existingUser.UserID,
+			WalletName: "savings",
+			Currency:   "RUB",
+		}
+
+		s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &savings, nil, existingUser)
+
+		s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/transfer", http.StatusOK, &models.Transaction{
+			FromWalletID: &wallet.WalletID,
+			ToWalletID:   &savings.WalletID,
+			Amount:       50.0,
+			Currency:     "RUB",
+		}, nil, existingUser)
+
+		var progress models.BudgetProgress
+
+		s.sendRequest(http.MethodGet, budgetsPath+"/"+uuid.UUID(budget.BudgetID).String(), http.StatusOK, nil, &progress, existingUser)
+		s.Require().InDelta(85.0, progress.Spent, epsilon)
+	})
+
+	s.Run("invalid budget", func() {
+		s.sendRequest(http.MethodPost, budgetsPath, http.StatusBadRequest, &models.Budget{
+			Name:     "no target",
+			Period:   models.BudgetPeriodWeekly,
+			Amount:   10.0,
+			Currency: "RUB",
+		}, nil, existingUser)
+	})
+
+	s.Run("delete budget", func() {
+		s.sendRequest(http.MethodDelete, budgetsPath+"/"+uuid.UUID(budget.BudgetID).String(), http.StatusNoContent, nil, nil, existingUser)
+		s.sendRequest(http.MethodGet, budgetsPath+"/"+uuid.UUID(budget.BudgetID).String(), http.StatusNotFound, nil, nil, existingUser)
+	})
+}
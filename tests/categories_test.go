@@ -0,0 +1,229 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const categoriesPath = "/api/v1/categories"
+
+func (s *IntegrationTestSuite) TestCategories() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "categorized",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	walletIDStr := uuid.UUID(wallet.WalletID).String()
+
+	var groceries, salary models.Category
+
+	s.sendRequest(http.MethodPost, categoriesPath, http.StatusCreated, &models.Category{Name: "groceries"}, &groceries, existingUser)
+	s.sendRequest(http.MethodPost, categoriesPath, http.StatusCreated, &models.Category{Name: "salary"}, &salary, existingUser)
+
+	deposit := "deposit"
+	shop := "shop"
+	minSalary := 500.0
+
+	s.sendRequest(http.MethodPost, categoriesPath+"/rules", http.StatusCreated, &models.CategoryRule{
+		CategoryID: salary.CategoryID,
+		Type:       &deposit,
+		MinAmount:  &minSalary,
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPost, categoriesPath+"/rules", http.StatusCreated, &models.CategoryRule{
+		CategoryID:          groceries.CategoryID,
+		Priority:            1,
+		DescriptionContains: &shop,
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &wallet.WalletID,
+		Amount:     1000.0,
+		Currency:   "RUB",
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/withdrawal", http.StatusOK, &models.Transaction{
+		FromWalletID: &wallet.WalletID,
+		Amount:       100.0,
+		Currency:     "RUB",
+		Description:  "Corner Shop",
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/withdrawal", http.StatusOK, &models.Transaction{
+		FromWalletID: &wallet.WalletID,
+		Amount:       50.0,
+		Currency:     "RUB",
+	}, nil, existingUser)
+
+	s.Run("rules categorize new transactions", func() {
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions?sorting=amount", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 3)
+
+		s.Require().Nil(page.Items[0].CategoryID)
+		s.Require().Equal(groceries.CategoryID, *page.Items[1].CategoryID)
+		s.Require().Equal(salary.CategoryID, *page.Items[2].CategoryID)
+	})
+
+	s.Run("filter by category", func() {
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions?category=none,"+uuid.UUID(groceries.CategoryID).String(),
+			http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 2)
+	})
+
+	s.Run("totals per category", func() {
+		var totals []models.CategoryTotal
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions/categories", http.StatusOK, nil, &totals, existingUser)
+		s.Require().Len(totals, 3)
+		s.Require().Empty(totals[0].CategoryName)
+		s.Require().Equal("groceries", totals[1].CategoryName)
+		s.Require().InDelta(100.0, totals[1].Amount, epsilon)
+	})
+
+	s.Run("manual category survives re-applying rules", func() {
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions?category=none", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+
+		txID := uuid.UUID(page.Items[0].ID).String()
+
+		s.sendRequest(http.MethodPut, walletPath+"/"+walletIDStr+"/transactions/"+txID+"/category", http.StatusOK,
+			&models.CategoryAssignment{CategoryID: &groceries.CategoryID}, nil, existingUser)
+
+		var result models.ApplyRulesResult
+
+		s.sendRequest(http.MethodPost, categoriesPath+"/rules/apply", http.StatusOK, nil, &result, existingUser)
+		s.Require().Equal(int64(2), result.Updated)
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions?category=none", http.StatusOK, nil, &page, existingUser)
+		s.Require().Empty(page.Items)
+
+		s.sendRequest(http.MethodPost, categoriesPath+"/rules/apply?force=true", http.StatusOK, nil, &result, existingUser)
+		s.Require().Equal(int64(3), result.Updated)
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions?category=none", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+	})
+
+	s.Run("duplicate category name", func() {
+		s.sendRequest(http.MethodPost, categoriesPath, http.StatusConflict, &models.Category{Name: "salary"}, nil, existingUser)
+	})
+
+	s.Run("rule for unknown category", func() {
+		s.sendRequest(http.MethodPost, categoriesPath+"/rules", http.StatusNotFound, &models.CategoryRule{
+			CategoryID: models.CategoryID(uuid.New()),
+		}, nil, existingUser)
+	})
+
+	s.Run("deleting category uncategorizes transactions", func() {
+		s.sendRequest(http.MethodDelete, categoriesPath+"/"+uuid.UUID(salary.CategoryID).String(), http.StatusNoContent, nil, nil, existingUser)
+
+		var rules []models.CategoryRule
+
+		s.sendRequest(http.MethodGet, categoriesPath+"/rules", http.StatusOK, nil, &rules, existingUser)
+		s.Require().Len(rules, 1)
+
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+walletIDStr+"/transactions?category=none", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 2)
+	})
+}
+
+func (s *IntegrationTestSuite) TestSharedWalletCategories() {
+	ctx := context.Background()
+	spender := models.User{UserID: models.UserID(uuid.New())}
+
+	for _, user := range []models.User{existingUser, spender} {
+		s.Require().NoError(s.db.UpsertUser(ctx, user))
+	}
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "shared categories",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	walletPathID := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+
+	s.sendRequest(http.MethodPost, walletPathID+"/members", http.StatusCreated, &models.WalletMember{
+		UserID: spender.UserID,
+		Role:   models.MemberRoleSpender,
+	}, nil, existingUser)
+
+	var rent, fun models.Category
+
+	s.sendRequest(http.MethodPost, categoriesPath, http.StatusCreated, &models.Category{Name: "rent"}, &rent, existingUser)
+	s.sendRequest(http.MethodPost, categoriesPath, http.StatusCreated, &models.Category{Name: "fun"}, &fun, spender)
+
+	withdraw := "withdraw"
+
+	s.sendRequest(http.MethodPost, categoriesPath+"/rules", http.StatusCreated, &models.CategoryRule{
+		CategoryID: rent.CategoryID,
+		Type:       &withdraw,
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPathID+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &wallet.WalletID,
+		Amount:     100.0,
+		Currency:   "RUB",
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPathID+"/withdrawal", http.StatusOK, &models.Transaction{
+		FromWalletID: &wallet.WalletID,
+		Amount:       30.0,
+		Currency:     "RUB",
+	}, nil, spender)
+
+	var page models.TransactionsPage
+
+	s.sendRequest(http.MethodGet, walletPathID+"/transactions?type=withdraw", http.StatusOK, nil, &page, existingUser)
+	s.Require().Len(page.Items, 1)
+
+	withdrawal := page.Items[0]
+
+	s.Run("owner's rules categorize the spender's transaction", func() {
+		s.Require().NotNil(withdrawal.CategoryID)
+		s.Require().Equal(rent.CategoryID, *withdrawal.CategoryID)
+	})
+
+	s.Run("spender categorizes for themselves", func() {
+		s.sendRequest(http.MethodPut, walletPathID+"/transactions/"+uuid.UUID(withdrawal.ID).String()+"/category", http.StatusOK,
+			&models.CategoryAssignment{CategoryID: &fun.CategoryID}, nil, spender)
+
+		s.sendRequest(http.MethodGet, walletPathID+"/transactions?type=withdraw", http.StatusOK, nil, &page, spender)
+		s.Require().Equal(fun.CategoryID, *page.Items[0].CategoryID)
+
+		s.sendRequest(http.MethodGet, walletPathID+"/transactions?type=withdraw", http.StatusOK, nil, &page, existingUser)
+		s.Require().Equal(rent.CategoryID, *page.Items[0].CategoryID)
+	})
+
+	s.Run("spender's rules leave the owner's categories alone", func() {
+		var result models.ApplyRulesResult
+
+		s.sendRequest(http.MethodPost, categoriesPath+"/rules/apply?force=true", http.StatusOK, nil, &result, spender)
+
+		s.sendRequest(http.MethodGet, walletPathID+"/transactions?category="+uuid.UUID(rent.CategoryID).String(),
+			http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+	})
+}
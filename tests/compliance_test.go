@@ -0,0 +1,151 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const compliancePath = `/api/v1/compliance/cases`
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestComplianceCases() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	officer := s.newClaims(models.User{UserID: models.UserID(uuid.New())})
+	officer.Role = models.RoleComplianceOfficer
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "monitoredWallet",
+		Currency:   "CHF",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	depositPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String() + "/deposit"
+
+	for _, amount := range []float64{100, 6000, 4600, 4700, 4800} {
+		s.sendRequest(http.MethodPut, depositPath, http.StatusOK,
+			&models.Transaction{ToWalletID: &wallet.WalletID, Amount: amount, Currency: "CHF"}, nil, existingUser)
+	}
+
+	var cases []models.ComplianceCase
+
+	s.Require().Eventually(func() bool {
+		s.sendRequestWithClaims(http.MethodGet, compliancePath, http.StatusOK, nil, &cases, officer)
+
+		return len(cases) == 2
+	}, 5*time.Second, complianceScanPeriod)
+
+	kinds := map[string]models.ComplianceCase{cases[0].Kind: cases[0], cases[1].Kind: cases[1]}
+
+	s.Run("cases are opened with the transactions attached", func() {
+		large := kinds[models.CaseKindLargeTransaction]
+		s.Require().Equal(models.CaseStatusOpen, large.Status)
+		s.Require().Equal(existingUser.UserID, large.UserID)
+		s.Require().Len(large.Transactions, 1)
+		s.Require().InDelta(6000.0, large.Amount, 0.001)
+
+		structuring := kinds[models.CaseKindStructuring]
+		s.Require().Len(structuring.Transactions, 3)
+		s.Require().InDelta(14100.0, structuring.Amount, 0.001)
+	})
+
+	s.Run("regular user cannot read cases", func() {
+		s.sendRequest(http.MethodGet, compliancePath, http.StatusForbidden, nil, nil, existingUser)
+	})
+
+	casePath := compliancePath + "/" + uuid.UUID(kinds[models.CaseKindLargeTransaction].CaseID).String()
+
+	s.Run("status workflow", func() {
+		s.sendRequestWithClaims(http.MethodPatch, casePath, http.StatusConflict,
+			&models.CaseUpdate{Status: models.CaseStatusReported}, nil, officer)
+		s.sendRequestWithClaims(http.MethodPatch, casePath, http.StatusBadRequest,
+			&models.CaseUpdate{Status: "escalated"}, nil, officer)
+
+		var updated models.ComplianceCase
+
+		s.sendRequestWithClaims(http.MethodPatch, casePath, http.StatusOK,
+			&models.CaseUpdate{Status: models.CaseStatusInvestigating}, &updated, officer)
+		s.Require().Equal(models.CaseStatusInvestigating, updated.Status)
+
+		s.sendRequestWithClaims(http.MethodPatch, casePath, http.StatusOK,
+			&models.CaseUpdate{Status: models.CaseStatusReported}, &updated, officer)
+		s.Require().Equal(models.CaseStatusReported, updated.Status)
+
+		var reported []models.ComplianceCase
+
+		s.sendRequestWithClaims(http.MethodGet, compliancePath+"?status=reported", http.StatusOK, nil, &reported, officer)
+		s.Require().Len(reported, 1)
+	})
+
+	s.Run("notes", func() {
+		s.sendRequestWithClaims(http.MethodPost, casePath+"/notes", http.StatusBadRequest, &models.CaseNote{Text: " "}, nil, officer)
+
+		var note models.CaseNote
+
+		s.sendRequestWithClaims(http.MethodPost, casePath+"/notes", http.StatusCreated,
+			&models.CaseNote{Text: "Filed with the regulator"}, &note, officer)
+		s.Require().Equal(officer.UserID, note.AuthorID)
+
+		var got models.ComplianceCase
+
+		s.sendRequestWithClaims(http.MethodGet, casePath, http.StatusOK, nil, &got, officer)
+		s.Require().Len(got.Notes, 1)
+		s.Require().Equal("Filed with the regulator", got.Notes[0].Text)
+	})
+
+	s.Run("csv export", func() {
+		records, err := csv.NewReader(strings.NewReader(s.getExport(compliancePath+"/export", officer))).ReadAll()
+		s.Require().NoError(err)
+		s.Require().Len(records, 5, "a header and a row per attached transaction")
+	})
+
+	s.Run("xml export", func() {
+		var report struct {
+			Cases []struct {
+				Kind         string   `xml:"kind,attr"`
+				Transactions []string `xml:"Transactions>Transaction>Amount"`
+			} `xml:"Case"`
+		}
+
+		s.Require().NoError(xml.Unmarshal([]byte(s.getExport(compliancePath+"/export?format=xml&status=reported", officer)), &report))
+		s.Require().Len(report.Cases, 1)
+		s.Require().Equal(models.CaseKindLargeTransaction, report.Cases[0].Kind)
+		s.Require().Equal([]string{"6000"}, report.Cases[0].Transactions)
+	})
+}
+
+func (s *IntegrationTestSuite) getExport(path string, claims models.Claims) string {
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		fmt.Sprintf("http://localhost:%d%s", port, path), nil)
+	s.Require().NoError(err)
+
+	request.Header.Set("Authorization", "Bearer "+s.getToken(claims))
+
+	response, err := http.DefaultClient.Do(request)
+	s.Require().NoError(err)
+
+	defer func() {
+		s.Require().NoError(response.Body.Close())
+	}()
+
+	s.Require().Equal(http.StatusOK, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+
+	return string(body)
+}
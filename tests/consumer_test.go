@@ -0,0 +1,267 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/broker"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+)
+
+func (s *IntegrationTestSuite) TestUsersConsumerGroup() {
+	producer := s.newSyncProducer()
+
+	defer func() {
+		s.Require().NoError(producer.Close())
+	}()
+
+	users := []models.User{
+		{UserID: models.UserID(uuid.New())},
+		{UserID: models.UserID(uuid.New())},
+		{UserID: models.UserID(uuid.New())},
+	}
+
+	// The users are produced before the consumer joins its group, as while the service is down.
+	for _, user := range users {
+		bytes, err := json.Marshal(user)
+		s.Require().NoError(err)
+
+		_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+			Topic: "users",
+			Key:   sarama.StringEncoder(uuid.UUID(user.UserID).String()),
+			Value: sarama.ByteEncoder(bytes),
+		})
+		s.Require().NoError(err)
+	}
+
+	stop := s.runUsersConsumer()
+	defer stop()
+
+	client := s.newGRPCClient()
+
+	for _, user := range users {
+		s.Require().Eventually(func() bool {
+			_, err := client.CreateWallet(s.grpcContext(user), &walletsv1.CreateWalletRequest{WalletName: "consumed", Currency: "RUB"})
+
+			return err == nil
+		}, 30*time.Second, 100*time.Millisecond)
+	}
+}
+
+func (s *IntegrationTestSuite) TestUsersProtobufEvents() {
+	producer := s.newSyncProducer()
+
+	defer func() {
+		s.Require().NoError(producer.Close())
+	}()
+
+	legacy := models.User{UserID: models.UserID(uuid.New())}
+
+	bytes, err := json.Marshal(legacy)
+	s.Require().NoError(err)
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "users", Value: sarama.ByteEncoder(bytes)})
+	s.Require().NoError(err)
+
+	created := models.UserEvent{
+		Version:    models.UserEventVersion,
+		Type:       models.UserEventCreated,
+		UserID:     models.UserID(uuid.New()),
+		OccurredAt: time.Now().UTC(),
+		Profile:    &models.UserProfile{FirstName: "Ann", LastName: "Smith"},
+	}
+
+	message, err := broker.NewUserEventMessage(created)
+	s.Require().NoError(err)
+
+	_, _, err = producer.SendMessage(message)
+	s.Require().NoError(err)
+
+	stop := s.runUsersConsumer()
+	defer stop()
+
+	client := s.newGRPCClient()
+
+	// JSON and protobuf messages are consumed from the same topic.
+	for _, userID := range []models.UserID{legacy.UserID, created.UserID} {
+		user := models.User{UserID: userID}
+
+		s.Require().Eventually(func() bool {
+			_, err := client.CreateWallet(s.grpcContext(user), &walletsv1.CreateWalletRequest{WalletName: "consumed", Currency: "RUB"})
+
+			return err == nil
+		}, 30*time.Second, 100*time.Millisecond)
+	}
+}
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestUsersDeadLetterQueue() {
+	producer := s.newSyncProducer()
+
+	defer func() {
+		s.Require().NoError(producer.Close())
+	}()
+
+	malformed := []byte(`{"userId":` + uuid.NewString())
+
+	_, offset, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   "users",
+		Value:   sarama.ByteEncoder(malformed),
+		Headers: []sarama.RecordHeader{{Key: []byte("trace-id"), Value: []byte("dlq-test")}},
+	})
+	s.Require().NoError(err)
+
+	user := models.User{UserID: models.UserID(uuid.New())}
+
+	bytes, err := json.Marshal(user)
+	s.Require().NoError(err)
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "users", Value: sarama.ByteEncoder(bytes)})
+	s.Require().NoError(err)
+
+	stop := s.runUsersConsumer()
+	defer stop()
+
+	s.Run("malformed message does not stop the consumer", func() {
+		client := s.newGRPCClient()
+
+		s.Require().Eventually(func() bool {
+			_, err := client.CreateWallet(s.grpcContext(user), &walletsv1.CreateWalletRequest{WalletName: "consumed", Currency: "RUB"})
+
+			return err == nil
+		}, 30*time.Second, 100*time.Millisecond)
+	})
+
+	s.Run("malformed message is dead-lettered", func() {
+		message := s.findMessage("users.dlq", malformed)
+
+		headers := make(map[string]string, len(message.Headers))
+		for _, header := range message.Headers {
+			headers[string(header.Key)] = string(header.Value)
+		}
+
+		s.Require().Equal("dlq-test", headers["trace-id"])
+		s.Require().Equal("users", headers["dlq-original-topic"])
+		s.Require().Equal(strconv.FormatInt(offset, 10), headers["dlq-original-offset"])
+		s.Require().Contains(headers["dlq-error"], "malformed message")
+	})
+
+	// Replayed messages would be dead-lettered again, after the end of the replay.
+	stop()
+
+	s.Run("dead-lettered messages are replayed once", func() {
+		cfg := broker.ReplayConfig{Addr: kafkaAddress, GroupID: "wallets-service-test-replay-" + uuid.NewString()}
+
+		replayed, err := broker.ReplayDLQ(context.Background(), cfg)
+		s.Require().NoError(err)
+		s.Require().Positive(replayed)
+
+		replayed, err = broker.ReplayDLQ(context.Background(), cfg)
+		s.Require().NoError(err)
+		s.Require().Zero(replayed)
+	})
+}
+
+func (s *IntegrationTestSuite) newSyncProducer() sarama.SyncProducer {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer([]string{kafkaAddress}, config)
+	s.Require().NoError(err)
+
+	return producer
+}
+
+// runUsersConsumer runs a users consumer in a group of its own and returns a func stopping it,
+// which can be called more than once.
+func (s *IntegrationTestSuite) runUsersConsumer() func() {
+	consumer, err := broker.NewConsumer(s.db, broker.ConsumerConfig{
+		Addr:         kafkaAddress,
+		GroupID:      "wallets-service-test-" + uuid.NewString(),
+		MaxRetries:   2,
+		RetryBackoff: 10 * time.Millisecond,
+	})
+	s.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	//nolint:testifylint
+	go func() {
+		err := consumer.Run(ctx)
+		s.Require().NoError(err)
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			cancel()
+			s.Require().NoError(consumer.Close())
+		})
+	}
+}
+
+// findMessage reads the topic from the start until it finds a message with the value.
+func (s *IntegrationTestSuite) findMessage(topic string, value []byte) *sarama.ConsumerMessage {
+	var found *sarama.ConsumerMessage
+
+	s.Require().Eventually(func() bool {
+		found = s.scanTopic(topic, value)
+
+		return found != nil
+	}, 30*time.Second, time.Second)
+
+	return found
+}
+
+func (s *IntegrationTestSuite) scanTopic(topic string, value []byte) *sarama.ConsumerMessage {
+	client, err := sarama.NewClient([]string{kafkaAddress}, nil)
+	s.Require().NoError(err)
+
+	defer func() {
+		s.Require().NoError(client.Close())
+	}()
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	s.Require().NoError(err)
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return nil
+	}
+
+	for _, partition := range partitions {
+		end, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+		s.Require().NoError(err)
+
+		if end == 0 {
+			continue
+		}
+
+		partitionConsumer, err := consumer.ConsumePartition(topic, partition, sarama.OffsetOldest)
+		s.Require().NoError(err)
+
+		for message := range partitionConsumer.Messages() {
+			if string(message.Value) == string(value) {
+				s.Require().NoError(partitionConsumer.Close())
+
+				return message
+			}
+
+			if message.Offset+1 >= end {
+				break
+			}
+		}
+
+		s.Require().NoError(partitionConsumer.Close())
+	}
+
+	return nil
+}
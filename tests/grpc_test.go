@@ -0,0 +1,149 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestGRPCWallets() {
+	s.Require().NoError(s.db.UpsertUser(context.Background(), existingUser))
+
+	client := s.newGRPCClient()
+	ctx := s.grpcContext(existingUser)
+
+	s.Run("unauthenticated", func() {
+		_, err := client.ListWallets(context.Background(), &walletsv1.ListWalletsRequest{})
+		s.Require().Equal(codes.Unauthenticated, status.Code(err))
+	})
+
+	wallet, err := client.CreateWallet(ctx, &walletsv1.CreateWalletRequest{WalletName: "grpc", Currency: "RUB"})
+	s.Require().NoError(err)
+	s.Require().Equal("grpc", wallet.GetWalletName())
+	s.Require().Equal(uuid.UUID(existingUser.UserID).String(), wallet.GetUserId())
+
+	s.Run("wallet not found", func() {
+		_, err := client.GetWallet(ctx, &walletsv1.GetWalletRequest{WalletId: uuid.NewString()})
+		s.Require().Equal(codes.NotFound, status.Code(err))
+	})
+
+	s.Run("invalid wallet id", func() {
+		_, err := client.GetWallet(ctx, &walletsv1.GetWalletRequest{WalletId: "wallet"})
+		s.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+
+	watch, err := client.WatchBalances(watchCtx, &walletsv1.WatchBalancesRequest{WalletIds: []string{wallet.GetWalletId()}})
+	s.Require().NoError(err)
+
+	for i := range 3 {
+		_, err = client.Deposit(ctx, &walletsv1.DepositRequest{
+			ToWalletId:  wallet.GetWalletId(),
+			Amount:      10,
+			Currency:    "RUB",
+			Description: fmt.Sprintf("deposit %d", i),
+		})
+		s.Require().NoError(err)
+	}
+
+	s.Run("balance updates streamed", func() {
+		updates := make(chan *walletsv1.BalanceUpdate)
+
+		go func() {
+			for {
+				update, err := watch.Recv()
+				if err != nil {
+					return
+				}
+
+				updates <- update
+			}
+		}()
+
+		for _, balance := range []float64{10, 20, 30} {
+			select {
+			case update := <-updates:
+				s.Require().Equal(wallet.GetWalletId(), update.GetWalletId())
+				s.Require().InDelta(balance, update.GetBalance(), 0.0001)
+				s.Require().NotZero(update.GetEventId())
+			case <-time.After(5 * time.Second):
+				s.FailNow("balance update not received")
+			}
+		}
+	})
+
+	s.Run("insufficient funds", func() {
+		_, err := client.Withdraw(ctx, &walletsv1.WithdrawRequest{FromWalletId: wallet.GetWalletId(), Amount: 100, Currency: "RUB"})
+		s.Require().Equal(codes.FailedPrecondition, status.Code(err))
+	})
+
+	s.Run("transactions streamed across pages", func() {
+		stream, err := client.ListTransactions(ctx, &walletsv1.ListTransactionsRequest{
+			WalletId: wallet.GetWalletId(),
+			PageSize: 1,
+		})
+		s.Require().NoError(err)
+
+		var transactions []*walletsv1.Transaction
+
+		for {
+			transaction, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			s.Require().NoError(err)
+
+			transactions = append(transactions, transaction)
+		}
+
+		s.Require().Len(transactions, 3)
+
+		for _, transaction := range transactions {
+			s.Require().Equal("deposit", transaction.GetType())
+			s.Require().Equal(wallet.GetWalletId(), transaction.GetToWalletId())
+		}
+	})
+
+	s.Run("foreign wallet not found", func() {
+		otherUser := models.User{UserID: models.UserID(uuid.New())}
+		s.Require().NoError(s.db.UpsertUser(context.Background(), otherUser))
+
+		_, err := client.Deposit(s.grpcContext(otherUser), &walletsv1.DepositRequest{
+			ToWalletId: wallet.GetWalletId(),
+			Amount:     10,
+			Currency:   "RUB",
+		})
+		s.Require().Equal(codes.NotFound, status.Code(err))
+	})
+}
+
+func (s *IntegrationTestSuite) newGRPCClient() walletsv1.WalletServiceClient {
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", grpcPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return walletsv1.NewWalletServiceClient(conn)
+}
+
+func (s *IntegrationTestSuite) grpcContext(user models.User) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+s.getToken(s.newClaims(user)))
+}
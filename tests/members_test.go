@@ -0,0 +1,135 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (s *IntegrationTestSuite) TestWalletMembers() {
+	ctx := context.Background()
+	spender := models.User{UserID: models.UserID(uuid.New())}
+	viewer := models.User{UserID: models.UserID(uuid.New())}
+
+	for _, user := range []models.User{existingUser, spender, viewer} {
+		s.Require().NoError(s.db.UpsertUser(ctx, user))
+	}
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "family",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	walletPathID := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+	membersPath := walletPathID + "/members"
+	limit := 100.0
+
+	s.sendRequest(http.MethodPut, walletPathID+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &wallet.WalletID,
+		Amount:     1000.0,
+		Currency:   "RUB",
+	}, nil, existingUser)
+
+	s.Run("owner invites members", func() {
+		s.sendRequest(http.MethodPost, membersPath, http.StatusCreated, &models.WalletMember{
+			UserID:     spender.UserID,
+			Role:       models.MemberRoleSpender,
+			SpendLimit: &limit,
+		}, nil, existingUser)
+
+		s.sendRequest(http.MethodPost, membersPath, http.StatusCreated, &models.WalletMember{
+			UserID: viewer.UserID,
+			Role:   models.MemberRoleViewer,
+		}, nil, existingUser)
+
+		s.sendRequest(http.MethodPost, membersPath, http.StatusConflict, &models.WalletMember{
+			UserID: viewer.UserID,
+			Role:   models.MemberRoleViewer,
+		}, nil, existingUser)
+
+		var members []models.WalletMember
+
+		s.sendRequest(http.MethodGet, membersPath, http.StatusOK, nil, &members, viewer)
+		s.Require().Len(members, 3)
+	})
+
+	s.Run("members see the shared wallet", func() {
+		var page models.WalletsPage
+
+		s.sendRequest(http.MethodGet, walletPath, http.StatusOK, nil, &page, viewer)
+		s.Require().Len(page.Items, 1)
+		s.Require().Equal(models.MemberRoleViewer, page.Items[0].Membership.Role)
+	})
+
+	s.Run("summary includes the shared wallet", func() {
+		var summary models.PortfolioSummary
+
+		s.sendRequest(http.MethodGet, walletPath+"/summary?currency=RUB", http.StatusOK, nil, &summary, viewer)
+		s.Require().Len(summary.Wallets, 1)
+		s.Require().Equal(wallet.WalletID, summary.Wallets[0].WalletID)
+	})
+
+	s.Run("spender moves funds within limit", func() {
+		s.sendRequest(http.MethodPut, walletPathID+"/withdrawal", http.StatusOK, &models.Transaction{
+			FromWalletID: &wallet.WalletID,
+			Amount:       50.0,
+			Currency:     "RUB",
+		}, nil, spender)
+
+		s.sendRequest(http.MethodPut, walletPathID+"/withdrawal", http.StatusForbidden, &models.Transaction{
+			FromWalletID: &wallet.WalletID,
+			Amount:       150.0,
+			Currency:     "RUB",
+		}, nil, spender)
+
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, walletPathID+"/transactions?type=withdraw", http.StatusOK, nil, &page, viewer)
+		s.Require().Len(page.Items, 1)
+		s.Require().Equal(spender.UserID, *page.Items[0].PerformedBy)
+	})
+
+	s.Run("viewer is read-only", func() {
+		s.sendRequest(http.MethodPut, walletPathID+"/withdrawal", http.StatusForbidden, &models.Transaction{
+			FromWalletID: &wallet.WalletID,
+			Amount:       1.0,
+			Currency:     "RUB",
+		}, nil, viewer)
+
+		s.sendRequest(http.MethodPatch, walletPathID, http.StatusForbidden, &models.WalletUpdate{
+			WalletName: "mine now",
+		}, nil, viewer)
+
+		s.sendRequest(http.MethodPost, membersPath, http.StatusForbidden, &models.WalletMember{
+			UserID: models.UserID(uuid.New()),
+			Role:   models.MemberRoleViewer,
+		}, nil, viewer)
+	})
+
+	s.Run("owner changes and removes members", func() {
+		var updated models.WalletMember
+
+		s.sendRequest(http.MethodPatch, membersPath+"/"+uuid.UUID(viewer.UserID).String(), http.StatusOK, &models.WalletMember{
+			Role: models.MemberRoleSpender,
+		}, &updated, existingUser)
+		s.Require().Equal(models.MemberRoleSpender, updated.Role)
+
+		s.sendRequest(http.MethodDelete, membersPath+"/"+uuid.UUID(spender.UserID).String(), http.StatusNoContent, nil, nil, existingUser)
+		s.sendRequest(http.MethodGet, walletPathID, http.StatusNotFound, nil, nil, spender)
+	})
+
+	s.Run("last owner cannot leave", func() {
+		s.sendRequest(http.MethodDelete, membersPath+"/"+uuid.UUID(existingUser.UserID).String(), http.StatusConflict, nil, nil, existingUser)
+
+		s.sendRequest(http.MethodPatch, membersPath+"/"+uuid.UUID(existingUser.UserID).String(), http.StatusConflict, &models.WalletMember{
+			Role: models.MemberRoleViewer,
+		}, nil, existingUser)
+	})
+}
@@ -0,0 +1,63 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (s *IntegrationTestSuite) TestPortfolioSummary() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	rubWallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "summaryRub",
+		Currency:   "RUB",
+	}
+
+	usdWallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "summaryUsd",
+		Currency:   "USD",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &rubWallet, nil, existingUser)
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &usdWallet, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+uuid.UUID(rubWallet.WalletID).String()+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &rubWallet.WalletID,
+		Amount:     1000.0,
+		Currency:   "RUB",
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+uuid.UUID(usdWallet.WalletID).String()+"/deposit", http.StatusOK, &models.Transaction{
+		ToWalletID: &usdWallet.WalletID,
+		Amount:     10.0,
+		Currency:   "USD",
+	}, nil, existingUser)
+
+	s.Run("summary in RUB", func() {
+		var summary models.PortfolioSummary
+
+		s.sendRequest(http.MethodGet, walletPath+"/summary?currency=RUB", http.StatusOK, nil, &summary, existingUser)
+
+		s.Require().Equal("RUB", summary.Currency)
+		s.Require().True(summary.Complete)
+		s.Require().Len(summary.Wallets, 2)
+		s.Require().Len(summary.Rates, 2)
+
+		expected := 1000.0 + 10.0*exchangeRatesToRub["USD"]
+		s.Require().True(math.Abs(summary.NetWorth-expected) < epsilon)
+	})
+
+	s.Run("currency is required", func() {
+		s.sendRequest(http.MethodGet, walletPath+"/summary", http.StatusBadRequest, nil, nil, existingUser)
+	})
+}
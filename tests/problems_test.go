@@ -0,0 +1,77 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+func (s *IntegrationTestSuite) TestProblemResponses() {
+	s.Require().NoError(s.db.UpsertUser(context.Background(), existingUser))
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "problems",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	depositPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String() + "/deposit"
+
+	s.Run("validation error names the field", func() {
+		var problem models.Problem
+
+		s.sendRequest(http.MethodPut, depositPath, http.StatusBadRequest, &models.Transaction{
+			ToWalletID: &wallet.WalletID,
+			Amount:     0,
+			Currency:   "RUB",
+		}, &problem, existingUser)
+
+		s.Require().Equal(http.StatusBadRequest, problem.Status)
+		s.Require().Equal("amount_zero", problem.Code)
+		s.Require().Equal("urn:wallets-service:problem:amount_zero", problem.Type)
+		s.Require().Equal(depositPath, problem.Instance)
+		s.Require().NotEmpty(problem.RequestID)
+		s.Require().Len(problem.Errors, 1)
+		s.Require().Equal("amount", problem.Errors[0].Field)
+	})
+
+	s.Run("not found", func() {
+		var problem models.Problem
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+uuid.NewString(), http.StatusNotFound, nil, &problem, existingUser)
+		s.Require().Equal("wallet_not_found", problem.Code)
+		s.Require().Empty(problem.Errors)
+	})
+
+	s.Run("invalid token", func() {
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+			fmt.Sprintf("http://localhost:%d%s", port, walletPath), nil)
+		s.Require().NoError(err)
+
+		request.Header.Set("Authorization", "Bearer invalid")
+
+		response, err := http.DefaultClient.Do(request)
+		s.Require().NoError(err)
+
+		defer func() {
+			s.Require().NoError(response.Body.Close())
+		}()
+
+		s.Require().Equal(http.StatusUnauthorized, response.StatusCode)
+		s.Require().Equal("application/problem+json", response.Header.Get("Content-Type"))
+
+		var problem models.Problem
+
+		s.Require().NoError(json.NewDecoder(response.Body).Decode(&problem))
+		s.Require().Equal("invalid_token", problem.Code)
+		s.Require().Equal(http.StatusUnauthorized, problem.Status)
+	})
+}
@@ -0,0 +1,131 @@
+//nolint:testpackage
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func (s *IntegrationTestSuite) TestRateLimits() {
+	ctx := context.Background()
+	user := models.User{UserID: models.UserID(uuid.New())}
+
+	s.Require().NoError(s.db.UpsertUser(ctx, user))
+	s.Require().NoError(s.db.UpsertRateLimitOverride(ctx, models.RateLimitOverride{
+		UserID:   user.UserID,
+		Budget:   models.RateLimitMoney,
+		Requests: 2,
+	}))
+
+	s.Require().ErrorIs(s.db.UpsertRateLimitOverride(ctx, models.RateLimitOverride{
+		UserID:   user.UserID,
+		Budget:   models.RateLimitDefault,
+		Requests: 0,
+	}), models.ErrInvalidRateLimit)
+
+	time.Sleep(3 * rateLimitRefresh)
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     user.UserID,
+		WalletName: "limited",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, user)
+
+	path := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+	deposit := models.Transaction{ToWalletID: &wallet.WalletID, Amount: 10, Currency: "RUB"}
+
+	s.sendRequest(http.MethodPut, path+"/deposit", http.StatusOK, &deposit, nil, user)
+	s.sendRequest(http.MethodPut, path+"/deposit", http.StatusOK, &deposit, nil, user)
+
+	s.Run("money budget exhausted", func() {
+		body, err := json.Marshal(deposit)
+		s.Require().NoError(err)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPut,
+			fmt.Sprintf("http://localhost:%d%s/deposit", port, path), bytes.NewReader(body))
+		s.Require().NoError(err)
+
+		request.Header.Set("Authorization", "Bearer "+s.getToken(s.newClaims(user)))
+
+		response, err := http.DefaultClient.Do(request)
+		s.Require().NoError(err)
+
+		defer func() {
+			s.Require().NoError(response.Body.Close())
+		}()
+
+		s.Require().Equal(http.StatusTooManyRequests, response.StatusCode)
+		s.Require().Equal("2", response.Header.Get("RateLimit-Limit"))
+		s.Require().Equal("0", response.Header.Get("RateLimit-Remaining"))
+		s.Require().NotEmpty(response.Header.Get("RateLimit-Reset"))
+		s.Require().NotEmpty(response.Header.Get("Retry-After"))
+
+		var problem models.Problem
+
+		s.Require().NoError(json.NewDecoder(response.Body).Decode(&problem))
+		s.Require().Equal("rate_limited", problem.Code)
+	})
+
+	s.Run("money budget exhausted over grpc", func() {
+		var header metadata.MD
+
+		_, err := s.newGRPCClient().Deposit(s.grpcContext(user), &walletsv1.DepositRequest{
+			ToWalletId: uuid.UUID(wallet.WalletID).String(),
+			Amount:     10,
+			Currency:   "RUB",
+		}, grpc.Header(&header))
+		s.Require().Equal(codes.ResourceExhausted, status.Code(err))
+		s.Require().Equal([]string{"2"}, header.Get("ratelimit-limit"))
+		s.Require().NotEmpty(header.Get("retry-after"))
+
+		_, err = s.newGRPCClient().GetWallet(s.grpcContext(user), &walletsv1.GetWalletRequest{WalletId: uuid.UUID(wallet.WalletID).String()})
+		s.Require().NoError(err)
+	})
+
+	s.Run("default budget unaffected", func() {
+		s.sendRequest(http.MethodGet, path, http.StatusOK, nil, nil, user)
+	})
+
+	s.Run("other users unaffected", func() {
+		s.Require().NoError(s.db.UpsertUser(ctx, existingUser))
+
+		other := models.Wallet{
+			WalletID:   models.WalletID(uuid.New()),
+			UserID:     existingUser.UserID,
+			WalletName: "unlimited",
+			Currency:   "RUB",
+		}
+
+		s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &other, nil, existingUser)
+
+		otherPath := walletPath + "/" + uuid.UUID(other.WalletID).String()
+		otherDeposit := models.Transaction{ToWalletID: &other.WalletID, Amount: 10, Currency: "RUB"}
+
+		for range 3 {
+			s.sendRequest(http.MethodPut, otherPath+"/deposit", http.StatusOK, &otherDeposit, nil, existingUser)
+		}
+	})
+
+	s.Run("deleted override restores the configured limit", func() {
+		s.Require().NoError(s.db.DeleteRateLimitOverride(ctx, user.UserID, models.RateLimitMoney))
+
+		time.Sleep(3 * rateLimitRefresh)
+
+		s.sendRequest(http.MethodPut, path+"/deposit", http.StatusOK, &deposit, nil, user)
+	})
+}
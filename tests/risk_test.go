@@ -0,0 +1,136 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+const riskPath = `/api/v1/risk`
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestRiskScreening() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	reviewer := s.newClaims(models.User{UserID: models.UserID(uuid.New())})
+	reviewer.Role = models.RoleRiskReviewer
+
+	velocity := models.RiskRule{
+		Kind:    models.RiskRuleVelocity,
+		Action:  models.RiskOutcomeReview,
+		Enabled: true,
+		Params:  models.RiskRuleParams{WindowMinutes: 10, MaxCount: 1},
+	}
+
+	defer func() {
+		velocity.Enabled = false
+		s.sendRequestWithClaims(http.MethodPut, riskPath+"/rules/velocity", http.StatusOK, &velocity, nil, reviewer)
+	}()
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "screenedWallet",
+		Currency:   "USD",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	path := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+	withdrawal := models.Transaction{FromWalletID: &wallet.WalletID, Amount: 10, Currency: "USD"}
+
+	s.sendRequest(http.MethodPut, path+"/deposit", http.StatusOK,
+		&models.Transaction{ToWalletID: &wallet.WalletID, Amount: 100, Currency: "USD"}, nil, existingUser)
+
+	s.Run("regular user cannot manage rules", func() {
+		s.sendRequest(http.MethodGet, riskPath+"/rules", http.StatusForbidden, nil, nil, existingUser)
+		s.sendRequest(http.MethodPut, riskPath+"/rules/velocity", http.StatusForbidden, &velocity, nil, existingUser)
+	})
+
+	s.Run("invalid rule", func() {
+		invalid := velocity
+		invalid.Params.MaxCount = 0
+
+		s.sendRequestWithClaims(http.MethodPut, riskPath+"/rules/velocity", http.StatusBadRequest, &invalid, nil, reviewer)
+	})
+
+	s.Run("rules are seeded disabled", func() {
+		var rules []models.RiskRule
+
+		s.sendRequestWithClaims(http.MethodGet, riskPath+"/rules", http.StatusOK, nil, &rules, reviewer)
+		s.Require().Len(rules, 4)
+
+		for _, rule := range rules {
+			s.Require().False(rule.Enabled, rule.Name)
+		}
+	})
+
+	var saved models.RiskRule
+
+	s.sendRequestWithClaims(http.MethodPut, riskPath+"/rules/velocity", http.StatusOK, &velocity, &saved, reviewer)
+	s.Require().Equal("velocity", saved.Name)
+	s.Require().True(saved.Enabled)
+
+	s.sendRequest(http.MethodPut, path+"/withdrawal", http.StatusOK, &withdrawal, nil, existingUser)
+
+	var held models.RiskAssessment
+
+	s.Run("withdrawal held for review", func() {
+		s.sendRequest(http.MethodPut, path+"/withdrawal", http.StatusAccepted, &withdrawal, &held, existingUser)
+		s.Require().Equal(models.RiskOutcomeReview, held.Outcome)
+		s.Require().Equal(models.ReviewStatusPending, held.Status)
+		s.Require().Equal([]string{"velocity"}, held.Rules)
+
+		var reviews []models.RiskAssessment
+
+		s.sendRequestWithClaims(http.MethodGet, riskPath+"/reviews", http.StatusOK, nil, &reviews, reviewer)
+		s.Require().Len(reviews, 1)
+		s.Require().Equal(held.AssessmentID, reviews[0].AssessmentID)
+	})
+
+	reviewPath := riskPath + "/reviews/" + uuid.UUID(held.AssessmentID).String()
+
+	s.Run("approved withdrawal is committed", func() {
+		var approved models.RiskAssessment
+
+		s.sendRequestWithClaims(http.MethodPost, reviewPath+"/approve", http.StatusOK, nil, &approved, reviewer)
+		s.Require().Equal(models.ReviewStatusApproved, approved.Status)
+		s.Require().Equal(reviewer.UserID, *approved.DecidedBy)
+
+		var got models.Wallet
+
+		s.sendRequest(http.MethodGet, path, http.StatusOK, nil, &got, existingUser)
+		s.Require().InDelta(80.0, got.Balance, 0.001)
+
+		s.sendRequestWithClaims(http.MethodPost, reviewPath+"/approve", http.StatusConflict, nil, nil, reviewer)
+	})
+
+	s.Run("declined withdrawal is not committed", func() {
+		var declined models.RiskAssessment
+
+		s.sendRequest(http.MethodPut, path+"/withdrawal", http.StatusAccepted, &withdrawal, &held, existingUser)
+		s.sendRequestWithClaims(http.MethodPost, riskPath+"/reviews/"+uuid.UUID(held.AssessmentID).String()+"/decline",
+			http.StatusOK, nil, &declined, reviewer)
+		s.Require().Equal(models.ReviewStatusDeclined, declined.Status)
+
+		var got models.Wallet
+
+		s.sendRequest(http.MethodGet, path, http.StatusOK, nil, &got, existingUser)
+		s.Require().InDelta(80.0, got.Balance, 0.001)
+	})
+
+	s.Run("blocked withdrawal", func() {
+		velocity.Action = models.RiskOutcomeBlock
+
+		s.sendRequestWithClaims(http.MethodPut, riskPath+"/rules/velocity", http.StatusOK, &velocity, nil, reviewer)
+		s.sendRequest(http.MethodPut, path+"/withdrawal", http.StatusForbidden, &withdrawal, nil, existingUser)
+	})
+
+	s.Run("unknown review", func() {
+		s.sendRequestWithClaims(http.MethodPost, riskPath+"/reviews/"+uuid.NewString()+"/approve", http.StatusNotFound, nil, nil, reviewer)
+	})
+}
@@ -0,0 +1,105 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	walletsv1 "github.com/romanpitatelev/wallets-service/internal/wallets-grpc/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestTokenScopes() {
+	s.Require().NoError(s.db.UpsertUser(context.Background(), existingUser))
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "scopes",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	path := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+	deposit := models.Transaction{ToWalletID: &wallet.WalletID, Amount: 10, Currency: "RUB"}
+
+	readOnly := s.newClaims(existingUser)
+	readOnly.Scopes = []string{models.ScopeWalletsRead, models.ScopeTransactionsRead}
+
+	s.Run("read-only token reads", func() {
+		var fetched models.Wallet
+
+		s.sendRequestWithClaims(http.MethodGet, path, http.StatusOK, nil, &fetched, readOnly)
+		s.Require().Equal(wallet.WalletID, fetched.WalletID)
+
+		s.sendRequestWithClaims(http.MethodGet, path+"/transactions", http.StatusOK, nil, nil, readOnly)
+	})
+
+	s.Run("read-only token cannot deposit", func() {
+		var problem models.Problem
+
+		s.sendRequestWithClaims(http.MethodPut, path+"/deposit", http.StatusForbidden, &deposit, &problem, readOnly)
+		s.Require().Equal("missing_scope", problem.Code)
+		s.Require().Contains(problem.Detail, models.ScopeTransactionsWrite)
+	})
+
+	s.Run("limited token", func() {
+		limited := s.newClaims(existingUser)
+		limited.Scopes = []string{models.ScopeTransactionsWrite}
+
+		s.sendRequestWithClaims(http.MethodPut, path+"/deposit", http.StatusOK, &deposit, nil, limited)
+		s.sendRequestWithClaims(http.MethodGet, path, http.StatusForbidden, nil, nil, limited)
+		s.sendRequestWithClaims(http.MethodGet, "/api/v1/api-keys", http.StatusForbidden, nil, nil, limited)
+	})
+
+	s.Run("token without scopes is unrestricted", func() {
+		s.sendRequest(http.MethodPut, path+"/deposit", http.StatusOK, &deposit, nil, existingUser)
+		s.sendRequest(http.MethodGet, "/api/v1/api-keys", http.StatusOK, nil, nil, existingUser)
+	})
+
+	s.Run("stream leaves out transactions without transactions:read", func() {
+		walletsOnly := s.newClaims(existingUser)
+		walletsOnly.Scopes = []string{models.ScopeWalletsRead}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events := s.openStreamWithClaims(ctx, 1, walletsOnly)
+		received := make(map[string]int)
+
+	receive:
+		for {
+			select {
+			case event := <-events:
+				received[event.eventType]++
+			case <-time.After(time.Second):
+				break receive
+			}
+		}
+
+		s.Require().NotZero(received[models.NotificationBalanceUpdated])
+		s.Require().Zero(received[models.NotificationTransactionCreated])
+	})
+
+	s.Run("grpc", func() {
+		client := s.newGRPCClient()
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+s.getToken(readOnly))
+
+		_, err := client.GetWallet(ctx, &walletsv1.GetWalletRequest{WalletId: uuid.UUID(wallet.WalletID).String()})
+		s.Require().NoError(err)
+
+		_, err = client.Deposit(ctx, &walletsv1.DepositRequest{
+			ToWalletId: uuid.UUID(wallet.WalletID).String(),
+			Amount:     10,
+			Currency:   "RUB",
+		})
+		s.Require().Equal(codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -0,0 +1,184 @@
+//nolint:testpackage
+package tests
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+type streamEvent struct {
+	id        int64
+	eventType string
+	data      string
+}
+
+func (s *IntegrationTestSuite) TestStreamEvents() {
+	s.Require().NoError(s.db.UpsertUser(context.Background(), existingUser))
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "live",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := s.openStream(ctx, 0)
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+uuid.UUID(wallet.WalletID).String()+"/deposit", http.StatusOK,
+		&models.Transaction{ToWalletID: &wallet.WalletID, Amount: 25.0, Currency: "RUB"}, nil, existingUser)
+
+	received := make(map[string]streamEvent)
+
+	for len(received) < 2 {
+		select {
+		case event := <-events:
+			received[event.eventType] = event
+		case <-time.After(5 * time.Second):
+			s.FailNow("stream event not received")
+		}
+	}
+
+	cancel()
+
+	balance := received[models.NotificationBalanceUpdated]
+	s.Require().NotZero(balance.id)
+	s.Require().Contains(balance.data, `"balance":25`)
+	s.Require().Contains(received[models.NotificationTransactionCreated].data, `"type":"deposit"`)
+
+	s.Run("resume from event id", func() {
+		first, last := balance, received[models.NotificationTransactionCreated]
+		if first.id > last.id {
+			first, last = last, first
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		select {
+		case event := <-s.openStream(ctx, first.id):
+			s.Require().Equal(last.id, event.id)
+			s.Require().Equal(last.eventType, event.eventType)
+		case <-time.After(5 * time.Second):
+			s.FailNow("missed event not replayed")
+		}
+	})
+}
+
+// TestEventIDsFollowCommitOrder records events in a transaction that commits after a later one, and
+// checks that a client resuming from the events of the later transaction still receives them.
+func (s *IntegrationTestSuite) TestEventIDsFollowCommitOrder() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.db.UpsertUser(ctx, existingUser))
+
+	wallets := make([]models.Wallet, 2)
+
+	for i := range wallets {
+		wallets[i] = models.Wallet{
+			WalletID:   models.WalletID(uuid.New()),
+			UserID:     existingUser.UserID,
+			WalletName: "ordered",
+			Currency:   "RUB",
+		}
+
+		s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallets[i], nil, existingUser)
+	}
+
+	recorded, commit, committed := make(chan struct{}), make(chan struct{}), make(chan error)
+
+	go func() {
+		committed <- s.db.DoWithTx(ctx, func(ctx context.Context) error {
+			_, err := s.db.Deposit(ctx, models.Transaction{ToWalletID: &wallets[0].WalletID, Amount: 10, Currency: "RUB"},
+				existingUser.UserID, 1)
+
+			close(recorded)
+			<-commit
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	<-recorded
+
+	s.sendRequest(http.MethodPut, walletPath+"/"+uuid.UUID(wallets[1].WalletID).String()+"/deposit", http.StatusOK,
+		&models.Transaction{ToWalletID: &wallets[1].WalletID, Amount: 20, Currency: "RUB"}, nil, existingUser)
+
+	close(commit)
+	s.Require().NoError(<-committed)
+
+	events, err := s.db.GetWalletEvents(ctx, existingUser.UserID, 0, 100)
+	s.Require().NoError(err)
+	s.Require().Len(events, 4)
+
+	// The events of the deposit committed first come first, and resuming after them replays the
+	// events of the deposit recorded earlier but committed last.
+	s.Require().Contains(string(events[0].Payload), uuid.UUID(wallets[1].WalletID).String())
+	s.Require().Contains(string(events[1].Payload), uuid.UUID(wallets[1].WalletID).String())
+
+	resumed, err := s.db.GetWalletEvents(ctx, existingUser.UserID, events[1].EventID, 100)
+	s.Require().NoError(err)
+	s.Require().Len(resumed, 2)
+
+	for _, event := range resumed {
+		s.Require().Contains(string(event.Payload), uuid.UUID(wallets[0].WalletID).String())
+	}
+}
+
+func (s *IntegrationTestSuite) openStream(ctx context.Context, lastEventID int64) <-chan streamEvent {
+	return s.openStreamWithClaims(ctx, lastEventID, s.newClaims(existingUser))
+}
+
+func (s *IntegrationTestSuite) openStreamWithClaims(ctx context.Context, lastEventID int64, claims models.Claims) <-chan streamEvent {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("http://localhost:%d/api/v1/stream", port), nil)
+	s.Require().NoError(err)
+
+	request.Header.Set("Authorization", "Bearer "+s.getToken(claims))
+
+	if lastEventID != 0 {
+		request.Header.Set("Last-Event-ID", strconv.FormatInt(lastEventID, 10))
+	}
+
+	response, err := http.DefaultClient.Do(request) //nolint:bodyclose
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, response.StatusCode)
+	s.Require().Equal("text/event-stream", response.Header.Get("Content-Type"))
+
+	events := make(chan streamEvent, 10)
+
+	go func() {
+		defer response.Body.Close()
+
+		var event streamEvent
+
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case line == "" && event.eventType != "":
+				events <- event
+				event = streamEvent{}
+			case strings.HasPrefix(line, "id: "):
+				event.id, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+			case strings.HasPrefix(line, "event: "):
+				event.eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	return events
+}
@@ -818,23 +818,27 @@ func (s *IntegrationTestSuite) TestGetTransactions() {
 	s.sendRequest(http.MethodPut, walletIDPathFive, http.StatusOK, &transactionFive, nil, existingUser)
 
 	s.Run("get all transactions for walletOne", func() {
-		var transactions []models.Transaction
+		var page models.TransactionsPage
 
 		uuidStrng := uuid.UUID(createdOne.WalletID).String()
 		walletIDPath := walletPath + "/" + uuidStrng + "/transactions"
 
-		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &transactions, existingUser)
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &page, existingUser)
+
+		transactions := page.Items
 
 		s.Require().Len(transactions, 3)
 	})
 
 	s.Run("sorted by transacton type with limit 2", func() {
-		var transactions []models.Transaction
+		var page models.TransactionsPage
 
 		uuidStrng := uuid.UUID(createdOne.WalletID).String()
 		walletIDPath := walletPath + "/" + uuidStrng + "/transactions" + "?sorting=transaction_type&limit=2"
 
-		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &transactions, existingUser)
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &page, existingUser)
+
+		transactions := page.Items
 
 		s.Require().Len(transactions, 2)
 		s.Require().Equal(transactions[0].ToWalletID, transactionOne.ToWalletID)
@@ -842,12 +846,14 @@ func (s *IntegrationTestSuite) TestGetTransactions() {
 	})
 
 	s.Run("sorted by transacton type with limit 2 and offset 1", func() {
-		var transactions []models.Transaction
+		var page models.TransactionsPage
 
 		uuidStrng := uuid.UUID(createdOne.WalletID).String()
 		walletIDPath := walletPath + "/" + uuidStrng + "/transactions" + "?sorting=transaction_type&limit=2&offset=1"
 
-		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &transactions, existingUser)
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &page, existingUser)
+
+		transactions := page.Items
 
 		s.Require().Len(transactions, 2)
 		s.Require().Equal(transactions[0].FromWalletID, transactionFive.FromWalletID)
@@ -855,18 +861,73 @@ func (s *IntegrationTestSuite) TestGetTransactions() {
 	})
 
 	s.Run("sorted by transaction type with limit 2 and offset 1, descending true", func() {
-		var transactions []models.Transaction
+		var page models.TransactionsPage
 
 		uuidStrng := uuid.UUID(createdOne.WalletID).String()
 		walletIDPath := walletPath + "/" + uuidStrng + "/transactions" + "?sorting=transaction_type&limit=2&offset=1&descending=true"
 
-		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &transactions, existingUser)
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &page, existingUser)
+
+		transactions := page.Items
 
 		s.Require().Len(transactions, 2)
 		s.Require().Equal(transactions[0].FromWalletID, transactionFive.FromWalletID)
 		s.Require().Equal(transactions[1].ToWalletID, transactionOne.ToWalletID)
 	})
 
+	s.Run("cursor pagination by commit time is stable", func() {
+		var first, second models.TransactionsPage
+
+		uuidStrng := uuid.UUID(createdOne.WalletID).String()
+		walletIDPath := walletPath + "/" + uuidStrng + "/transactions"
+
+		s.sendRequest(http.MethodGet, walletIDPath+"?limit=2", http.StatusOK, nil, &first, existingUser)
+
+		s.Require().Len(first.Items, 2)
+		s.Require().Equal(transactionOne.ToWalletID, first.Items[0].ToWalletID)
+		s.Require().NotEmpty(first.NextCursor)
+
+		s.sendRequest(http.MethodGet, walletIDPath+"?limit=2&cursor="+first.NextCursor, http.StatusOK, nil, &second, existingUser)
+
+		s.Require().Len(second.Items, 1)
+		s.Require().Equal(transactionFive.ToWalletID, second.Items[0].ToWalletID)
+		s.Require().Empty(second.NextCursor)
+		s.Require().NotEmpty(second.PrevCursor)
+	})
+
+	s.Run("filtered by type set and sorted by amount", func() {
+		var page models.TransactionsPage
+
+		uuidStrng := uuid.UUID(createdOne.WalletID).String()
+		walletIDPath := walletPath + "/" + uuidStrng + "/transactions" + "?type=withdraw,transfer&sorting=amount&descending=true"
+
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &page, existingUser)
+
+		s.Require().Len(page.Items, 2)
+		s.Require().Equal(transactionFive.Amount, page.Items[0].Amount)
+		s.Require().Equal(transactionFour.Amount, page.Items[1].Amount)
+	})
+
+	s.Run("filtered by amount range and counterparty", func() {
+		var page models.TransactionsPage
+
+		uuidStrng := uuid.UUID(createdOne.WalletID).String()
+		counterparty := uuid.UUID(createdThree.WalletID).String()
+		walletIDPath := walletPath + "/" + uuidStrng + "/transactions" + "?minAmount=1000&maxAmount=7500&counterpartyWalletId=" + counterparty
+
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &page, existingUser)
+
+		s.Require().Len(page.Items, 1)
+		s.Require().Equal(transactionFive.ToWalletID, page.Items[0].ToWalletID)
+	})
+
+	s.Run("invalid typed filter", func() {
+		uuidStrng := uuid.UUID(createdOne.WalletID).String()
+		walletIDPath := walletPath + "/" + uuidStrng + "/transactions" + "?type=refund"
+
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusBadRequest, nil, nil, existingUser)
+	})
+
 	s.Run("user does not own any wallets", func() {
 		otherUser := models.User{
 			UserID: models.UserID(uuid.New()),
@@ -881,3 +942,81 @@ func (s *IntegrationTestSuite) TestGetTransactions() {
 		s.sendRequest(http.MethodGet, walletIDPath, http.StatusNotFound, nil, nil, otherUser)
 	})
 }
+
+func (s *IntegrationTestSuite) TestTransactionDetails() {
+	err := s.db.UpsertUser(context.Background(), existingUser)
+	s.Require().NoError(err)
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "detailed",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	depositPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String() + "/deposit"
+	transactionsPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String() + "/transactions"
+	reference := "psp-1001"
+
+	deposit := models.Transaction{
+		ToWalletID:        &wallet.WalletID,
+		Amount:            100.0,
+		Currency:          "RUB",
+		Description:       "Invoice 17 payment",
+		ExternalReference: &reference,
+		Metadata:          map[string]string{"provider": "acme", "orderId": "17"},
+	}
+
+	s.sendRequest(http.MethodPut, depositPath, http.StatusOK, &deposit, nil, existingUser)
+
+	s.Run("details are persisted", func() {
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, transactionsPath, http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+		s.Require().Equal(deposit.Description, page.Items[0].Description)
+		s.Require().Equal(reference, *page.Items[0].ExternalReference)
+		s.Require().Equal(deposit.Metadata, page.Items[0].Metadata)
+	})
+
+	s.Run("duplicate external reference is rejected", func() {
+		s.sendRequest(http.MethodPut, depositPath, http.StatusConflict, &deposit, nil, existingUser)
+
+		var updated models.Wallet
+
+		s.sendRequest(http.MethodGet, walletPath+"/"+uuid.UUID(wallet.WalletID).String(), http.StatusOK, nil, &updated, existingUser)
+		s.Require().InDelta(100.0, updated.Balance, epsilon)
+	})
+
+	s.Run("search by description, reference and metadata", func() {
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, transactionsPath+"?filter=invoice", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+
+		s.sendRequest(http.MethodGet, transactionsPath+"?externalReference=psp-1001", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+
+		s.sendRequest(http.MethodGet, transactionsPath+"?metadata=provider:acme", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 1)
+
+		s.sendRequest(http.MethodGet, transactionsPath+"?metadata=provider:other", http.StatusOK, nil, &page, existingUser)
+		s.Require().Empty(page.Items)
+	})
+
+	s.Run("oversized metadata is rejected", func() {
+		metadata := make(map[string]string)
+		for i := range models.MaxMetadataKeys + 1 {
+			metadata[uuid.NewString()[:8]+string(rune('a'+i))] = "v"
+		}
+
+		s.sendRequest(http.MethodPut, depositPath, http.StatusBadRequest, &models.Transaction{
+			ToWalletID: &wallet.WalletID,
+			Amount:     1.0,
+			Currency:   "RUB",
+			Metadata:   metadata,
+		}, nil, existingUser)
+	})
+}
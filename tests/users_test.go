@@ -0,0 +1,213 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+)
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestUserLifecycle() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.db.UpsertUser(ctx, existingUser))
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "lifecycle",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+
+	walletIDPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+	deposit := &models.Transaction{ToWalletID: &wallet.WalletID, Amount: 100, Currency: "RUB"}
+
+	deletedAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	s.Require().NoError(s.db.ApplyUserEvent(ctx, models.UserEvent{
+		Version:    models.UserEventVersion,
+		Type:       models.UserEventDeleted,
+		UserID:     existingUser.UserID,
+		OccurredAt: deletedAt,
+	}))
+
+	s.Run("deleting the user freezes the wallet", func() {
+		var frozen models.Wallet
+
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &frozen, existingUser)
+		s.Require().NotNil(frozen.FrozenAt)
+		s.Require().True(deletedAt.Equal(*frozen.FrozenAt))
+	})
+
+	s.Run("frozen wallet rejects deposits", func() {
+		var problem models.Problem
+
+		s.sendRequest(http.MethodPut, walletIDPath+"/deposit", http.StatusConflict, deposit, &problem, existingUser)
+		s.Require().Equal("wallet_frozen", problem.Code)
+	})
+
+	s.Run("balance updates skip frozen wallets", func() {
+		_, err := s.db.Deposit(ctx, *deposit, existingUser.UserID, 1)
+		s.Require().ErrorIs(err, models.ErrWalletFrozen)
+	})
+
+	s.Run("deleted user cannot create wallets", func() {
+		var problem models.Problem
+
+		s.sendRequest(http.MethodPost, walletPath, http.StatusForbidden, &models.Wallet{
+			WalletID:   models.WalletID(uuid.New()),
+			UserID:     existingUser.UserID,
+			WalletName: "after deletion",
+			Currency:   "RUB",
+		}, &problem, existingUser)
+		s.Require().Equal("user_deleted", problem.Code)
+	})
+
+	s.Run("older event is ignored", func() {
+		s.Require().NoError(s.db.ApplyUserEvent(ctx, models.UserEvent{
+			Version:    models.UserEventVersion,
+			Type:       models.UserEventRestored,
+			UserID:     existingUser.UserID,
+			OccurredAt: deletedAt.Add(-time.Minute),
+		}))
+
+		var frozen models.Wallet
+
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &frozen, existingUser)
+		s.Require().NotNil(frozen.FrozenAt)
+	})
+
+	s.Run("restoring the user unfreezes the wallet", func() {
+		s.Require().NoError(s.db.ApplyUserEvent(ctx, models.UserEvent{
+			Version:    models.UserEventVersion,
+			Type:       models.UserEventRestored,
+			UserID:     existingUser.UserID,
+			OccurredAt: deletedAt.Add(time.Minute),
+		}))
+
+		var restored models.Wallet
+
+		s.sendRequest(http.MethodGet, walletIDPath, http.StatusOK, nil, &restored, existingUser)
+		s.Require().Nil(restored.FrozenAt)
+
+		s.sendRequest(http.MethodPut, walletIDPath+"/deposit", http.StatusOK, deposit, nil, existingUser)
+	})
+}
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestUserProfile() {
+	ctx := context.Background()
+	friend := models.User{UserID: models.UserID(uuid.New())}
+
+	for user, profile := range map[models.User]models.UserProfile{
+		existingUser: {FirstName: "Ann", LastName: "Smith", Gender: "female", Age: 34},
+		friend:       {FirstName: "Boris", LastName: "Ivanov", Gender: "male", Age: 41},
+	} {
+		s.Require().NoError(s.db.ApplyUserEvent(ctx, models.UserEvent{
+			Version:    models.UserEventVersion,
+			Type:       models.UserEventCreated,
+			UserID:     user.UserID,
+			OccurredAt: time.Now(),
+			Profile:    &profile,
+		}))
+	}
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "own",
+		Currency:   "RUB",
+	}
+
+	friendWallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     friend.UserID,
+		WalletName: "shared",
+		Currency:   "RUB",
+	}
+
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &friendWallet, nil, friend)
+
+	s.Run("profile of the user", func() {
+		var account models.UserAccount
+
+		s.sendRequest(http.MethodGet, "/api/v1/users/me", http.StatusOK, nil, &account, existingUser)
+		s.Require().Equal(existingUser.UserID, account.UserID)
+		s.Require().Equal(&models.UserProfile{FirstName: "Ann", LastName: "Smith", Gender: "female", Age: 34}, account.Profile)
+		s.Require().Equal(1, account.WalletCount)
+		s.Require().Empty(account.Preferences)
+	})
+
+	s.Run("preferences", func() {
+		var account models.UserAccount
+
+		s.sendRequest(http.MethodPatch, "/api/v1/users/me/preferences", http.StatusOK,
+			&models.UserPreferences{Currency: "usd", Timezone: "Europe/Moscow"}, &account, existingUser)
+		s.Require().Equal(models.UserPreferences{Currency: "USD", Timezone: "Europe/Moscow"}, account.Preferences)
+
+		var problem models.Problem
+
+		s.sendRequest(http.MethodPatch, "/api/v1/users/me/preferences", http.StatusBadRequest,
+			&models.UserPreferences{Timezone: "Mars/Olympus"}, &problem, existingUser)
+		s.Require().Equal("invalid_preferences", problem.Code)
+	})
+
+	s.Run("admin searches users by name", func() {
+		admin := s.newClaims(models.User{UserID: models.UserID(uuid.New())})
+		admin.Role = models.RoleAdmin
+
+		query := url.Values{"lastName": {" SMITH "}}
+
+		var accounts []models.UserAccount
+
+		s.sendRequestWithClaims(http.MethodGet, "/api/v1/users?"+query.Encode(), http.StatusOK, nil, &accounts, admin)
+		s.Require().Len(accounts, 1)
+		s.Require().Equal(existingUser.UserID, accounts[0].UserID)
+
+		query.Set("firstName", "Boris")
+
+		s.sendRequestWithClaims(http.MethodGet, "/api/v1/users?"+query.Encode(), http.StatusOK, nil, &accounts, admin)
+		s.Require().Empty(accounts)
+
+		s.sendRequest(http.MethodGet, "/api/v1/users", http.StatusForbidden, nil, nil, existingUser)
+	})
+
+	s.Run("transfers show the counterparty", func() {
+		s.sendRequest(http.MethodPost, walletPath+"/"+uuid.UUID(friendWallet.WalletID).String()+"/members", http.StatusCreated,
+			&models.WalletMember{UserID: existingUser.UserID, Role: models.MemberRoleSpender}, nil, friend)
+
+		walletIDPath := walletPath + "/" + uuid.UUID(wallet.WalletID).String()
+
+		s.sendRequest(http.MethodPut, walletIDPath+"/deposit", http.StatusOK,
+			&models.Transaction{ToWalletID: &wallet.WalletID, Amount: 100, Currency: "RUB"}, nil, existingUser)
+		s.sendRequest(http.MethodPut, walletIDPath+"/transfer", http.StatusOK, &models.Transaction{
+			FromWalletID: &wallet.WalletID,
+			ToWalletID:   &friendWallet.WalletID,
+			Amount:       40,
+			Currency:     "RUB",
+		}, nil, existingUser)
+
+		var page models.TransactionsPage
+
+		s.sendRequest(http.MethodGet, walletIDPath+"/transactions", http.StatusOK, nil, &page, existingUser)
+		s.Require().Len(page.Items, 2)
+
+		for _, transaction := range page.Items {
+			if transaction.Type != "transfer" {
+				s.Require().Nil(transaction.Counterparty)
+
+				continue
+			}
+
+			s.Require().Equal(&models.Counterparty{UserID: friend.UserID, DisplayName: "Boris I."}, transaction.Counterparty)
+		}
+	})
+}
@@ -381,7 +381,7 @@ func (s *IntegrationTestSuite) TestDeleteWallet() {
 }
 
 func (s *IntegrationTestSuite) TestGetWallets() {
-	err := s.db.Truncate(context.Background(), "wallets")
+	err := s.db.Truncate(context.Background(), "wallet_members", "wallets")
 	s.Require().NoError(err)
 
 	err = s.db.UpsertUser(context.Background(), existingUser)
@@ -465,19 +465,23 @@ func (s *IntegrationTestSuite) TestGetWallets() {
 	s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &walletFive, &createdFive, existingUser)
 
 	s.Run("read successfully", func() {
-		var wallets []models.Wallet
+		var page models.WalletsPage
 
-		s.sendRequest(http.MethodGet, walletPath, http.StatusOK, nil, &wallets, existingUser)
+		s.sendRequest(http.MethodGet, walletPath, http.StatusOK, nil, &page, existingUser)
 
-		s.Require().Len(wallets, len(arrWallets))
+		s.Require().Len(page.Items, len(arrWallets))
+		s.Require().Empty(page.NextCursor)
+		s.Require().Empty(page.PrevCursor)
 	})
 
 	s.Run("sorted by name with limit 2", func() {
-		var wallets []models.Wallet
+		var page models.WalletsPage
 
 		someWalletPath := walletPath + "?sorting=wallet_name&limit=2"
 
-		s.sendRequest(http.MethodGet, someWalletPath, http.StatusOK, nil, &wallets, existingUser)
+		s.sendRequest(http.MethodGet, someWalletPath, http.StatusOK, nil, &page, existingUser)
+
+		wallets := page.Items
 
 		s.Require().Len(wallets, 2)
 		s.Require().Equal(wallets[0].UserID, walletFive.UserID)
@@ -485,11 +489,13 @@ func (s *IntegrationTestSuite) TestGetWallets() {
 	})
 
 	s.Run("sorted by name with limit 2 and offset 2", func() {
-		var wallets []models.Wallet
+		var page models.WalletsPage
 
 		someWalletPath := walletPath + "?sorting=wallet_name&limit=2&offset=2"
 
-		s.sendRequest(http.MethodGet, someWalletPath, http.StatusOK, nil, &wallets, existingUser)
+		s.sendRequest(http.MethodGet, someWalletPath, http.StatusOK, nil, &page, existingUser)
+
+		wallets := page.Items
 
 		s.Require().Len(wallets, 2)
 		s.Require().Equal(wallets[0].UserID, walletFour.UserID)
@@ -498,17 +504,54 @@ func (s *IntegrationTestSuite) TestGetWallets() {
 	})
 
 	s.Run("sorted by name with limit 2 and offset 2, descending true", func() {
-		var wallets []models.Wallet
+		var page models.WalletsPage
 
 		someWalletPath := walletPath + "?sorting=wallet_name&limit=2&offset=2&descending=true"
 
-		s.sendRequest(http.MethodGet, someWalletPath, http.StatusOK, nil, &wallets, existingUser)
+		s.sendRequest(http.MethodGet, someWalletPath, http.StatusOK, nil, &page, existingUser)
+
+		wallets := page.Items
 
 		s.Require().Len(wallets, 2)
 		s.Require().Equal(wallets[0].Balance, walletFour.Balance)
 		s.Require().Equal(wallets[1].WalletName, walletOne.WalletName)
 	})
 
+	s.Run("cursor pagination by name", func() {
+		var first, second, back models.WalletsPage
+
+		s.sendRequest(http.MethodGet, walletPath+"?sorting=wallet_name&limit=2", http.StatusOK, nil, &first, existingUser)
+
+		s.Require().Len(first.Items, 2)
+		s.Require().NotEmpty(first.NextCursor)
+		s.Require().Empty(first.PrevCursor)
+
+		s.sendRequest(http.MethodGet, walletPath+"?limit=2&cursor="+first.NextCursor, http.StatusOK, nil, &second, existingUser)
+
+		s.Require().Len(second.Items, 2)
+		s.Require().Equal(walletFour.WalletID, second.Items[0].WalletID)
+		s.Require().Equal(walletTwo.WalletID, second.Items[1].WalletID)
+		s.Require().NotEmpty(second.PrevCursor)
+
+		s.sendRequest(http.MethodGet, walletPath+"?limit=2&cursor="+second.PrevCursor, http.StatusOK, nil, &back, existingUser)
+
+		s.Require().Equal(first.Items, back.Items)
+	})
+
+	s.Run("filtered by currency set", func() {
+		var page models.WalletsPage
+
+		s.sendRequest(http.MethodGet, walletPath+"?currency=rub,cny&sorting=created_at", http.StatusOK, nil, &page, existingUser)
+
+		s.Require().Len(page.Items, 2)
+		s.Require().Equal(walletOne.WalletID, page.Items[0].WalletID)
+		s.Require().Equal(walletThree.WalletID, page.Items[1].WalletID)
+	})
+
+	s.Run("invalid cursor", func() {
+		s.sendRequest(http.MethodGet, walletPath+"?cursor=garbage", http.StatusBadRequest, nil, nil, existingUser)
+	})
+
 	s.Run("user does not own any wallets", func() {
 		otherUser := models.User{
 			UserID: models.UserID(uuid.New()),
@@ -517,10 +560,10 @@ func (s *IntegrationTestSuite) TestGetWallets() {
 		err := s.db.UpsertUser(context.Background(), otherUser)
 		s.Require().NoError(err)
 
-		var wallets []models.Wallet
+		var page models.WalletsPage
 
-		s.sendRequest(http.MethodGet, walletPath, http.StatusOK, nil, &wallets, otherUser)
+		s.sendRequest(http.MethodGet, walletPath, http.StatusOK, nil, &page, otherUser)
 
-		s.Require().Len(wallets, 0)
+		s.Require().Empty(page.Items)
 	})
 }
@@ -0,0 +1,141 @@
+//nolint:testpackage
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanpitatelev/wallets-service/internal/models"
+	"github.com/romanpitatelev/wallets-service/internal/service"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+//nolint:funlen
+func (s *IntegrationTestSuite) TestWebhooks() {
+	s.Require().NoError(s.db.UpsertUser(context.Background(), existingUser))
+
+	var (
+		failing  atomic.Bool
+		received = make(chan []byte, 10)
+		secret   atomic.Value
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+
+		timestamp, signature, _ := strings.Cut(r.Header.Get(service.WebhookSignatureHeader), ",v1=")
+		s.Equal(service.SignWebhook(secret.Load().(string), strings.TrimPrefix(timestamp, "t="), body), signature) //nolint:forcetypeassert
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		received <- body
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	var webhook models.Webhook
+
+	s.sendRequest(http.MethodPost, "/api/v1/webhooks", http.StatusBadRequest, &models.Webhook{
+		URL:        "ftp://example.com",
+		EventTypes: []string{models.WebhookEventDeposit},
+	}, nil, existingUser)
+
+	s.sendRequest(http.MethodPost, "/api/v1/webhooks", http.StatusCreated, &models.Webhook{
+		URL:        receiver.URL,
+		EventTypes: []string{models.WebhookEventDeposit, models.WebhookEventWalletCreated},
+	}, &webhook, existingUser)
+	s.Require().NotEmpty(webhook.Secret)
+	secret.Store(webhook.Secret)
+
+	webhookPath := "/api/v1/webhooks/" + uuid.UUID(webhook.WebhookID).String()
+
+	wallet := models.Wallet{
+		WalletID:   models.WalletID(uuid.New()),
+		UserID:     existingUser.UserID,
+		WalletName: "hooked",
+		Currency:   "RUB",
+	}
+
+	deposit := func() {
+		s.sendRequest(http.MethodPut, walletPath+"/"+uuid.UUID(wallet.WalletID).String()+"/deposit", http.StatusOK,
+			&models.Transaction{ToWalletID: &wallet.WalletID, Amount: 10.0, Currency: "RUB"}, nil, existingUser)
+	}
+
+	s.Run("events are delivered", func() {
+		s.sendRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, existingUser)
+		deposit()
+
+		for _, eventType := range []string{models.WebhookEventWalletCreated, models.WebhookEventDeposit} {
+			select {
+			case body := <-received:
+				s.Require().Contains(string(body), `"type":"`+eventType+`"`)
+			case <-time.After(5 * time.Second):
+				s.FailNow("webhook not delivered", eventType)
+			}
+		}
+	})
+
+	s.Run("failed deliveries are dead lettered and redelivered", func() {
+		failing.Store(true)
+		deposit()
+
+		var dead []models.WebhookDelivery
+
+		s.Require().Eventually(func() bool {
+			s.sendRequest(http.MethodGet, webhookPath+"/deliveries?status=dead", http.StatusOK, nil, &dead, existingUser)
+
+			return len(dead) == 1
+		}, 5*time.Second, webhookPollPeriod)
+
+		deliveryPath := webhookPath + "/deliveries/" + uuid.UUID(dead[0].DeliveryID).String()
+
+		var delivery models.WebhookDelivery
+
+		s.sendRequest(http.MethodGet, deliveryPath, http.StatusOK, nil, &delivery, existingUser)
+		s.Require().Len(delivery.History, webhookMaxAttempts)
+		s.Require().Equal(http.StatusServiceUnavailable, *delivery.History[0].StatusCode)
+
+		failing.Store(false)
+		s.sendRequest(http.MethodPost, deliveryPath+"/redeliver", http.StatusOK, nil, &delivery, existingUser)
+		s.Require().Equal(models.DeliveryStatusPending, delivery.Status)
+
+		select {
+		case body := <-received:
+			s.Require().Contains(string(body), models.WebhookEventDeposit)
+		case <-time.After(5 * time.Second):
+			s.FailNow("webhook not redelivered")
+		}
+	})
+
+	s.Run("plaintext secrets are encrypted by migrate", func() {
+		s.Require().NoError(s.db.Exec(context.Background(),
+			`UPDATE webhooks SET plaintext_secret = $2, encrypted_secret = NULL WHERE webhook_id = $1`, webhook.WebhookID, webhook.Secret))
+		s.Require().NoError(s.db.Migrate(migrate.Up))
+
+		deposit()
+
+		select {
+		case body := <-received:
+			s.Require().Contains(string(body), models.WebhookEventDeposit)
+		case <-time.After(5 * time.Second):
+			s.FailNow("webhook not delivered")
+		}
+	})
+
+	s.Run("deleted webhooks stop receiving events", func() {
+		s.sendRequest(http.MethodDelete, webhookPath, http.StatusNoContent, nil, nil, existingUser)
+		s.sendRequest(http.MethodGet, webhookPath+"/deliveries", http.StatusNotFound, nil, nil, existingUser)
+	})
+}